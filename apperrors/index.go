@@ -0,0 +1,129 @@
+// Package apperrors gives the errors servers return a Kind, which decides
+// the HTTP status they are answered with. The app's ErrorHandler turns them
+// into responses; anything else that reaches it is an internal error, unless
+// it is a database error From knows how to translate.
+package apperrors
+
+import (
+	"errors"
+	"telemed/responses"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindRateLimited
+)
+
+var statuses = map[Kind]int{
+	KindInternal:     500,
+	KindNotFound:     404,
+	KindConflict:     409,
+	KindValidation:   422,
+	KindUnauthorized: 401,
+	KindForbidden:    403,
+	KindRateLimited:  429,
+}
+
+// Status is the HTTP status errors of kind k are answered with.
+func (k Kind) Status() int {
+	if status, ok := statuses[k]; ok {
+		return status
+	}
+	return 500
+}
+
+// Postgres error codes From translates.
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// Error is a failure the client is told about. Message is shown to them as
+// it is, so it should be one of the responses constants; Err is the cause,
+// which is only logged.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func Invalid(message string) *Error {
+	return &Error{Kind: KindValidation, Message: message}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+func RateLimited(message string) *Error {
+	return &Error{Kind: KindRateLimited, Message: message}
+}
+
+// Internal reports an unexpected failure. The client only sees
+// SOMETHING_WRONG; cause, which may be nil, is kept for the log and for From.
+func Internal(cause error) *Error {
+	return &Error{Kind: KindInternal, Message: responses.SOMETHING_WRONG, Err: cause}
+}
+
+// From classifies err for a response. Typed errors keep their kind, except
+// that an internal error whose cause is a missing row, a unique violation or
+// a foreign key violation becomes a 404 or 409. Untyped errors get the same
+// translation or are internal.
+func From(err error) *Error {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = Internal(err)
+	}
+	if e.Kind != KindInternal || e.Err == nil {
+		return e
+	}
+
+	if errors.Is(e.Err, pgx.ErrNoRows) {
+		return &Error{Kind: KindNotFound, Message: responses.RECORD_NOT_FOUND, Err: e.Err}
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(e.Err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return &Error{Kind: KindConflict, Message: responses.RECORD_EXISTS, Err: e.Err}
+		case foreignKeyViolation:
+			return &Error{Kind: KindConflict, Message: responses.RECORD_IN_USE, Err: e.Err}
+		}
+	}
+	return e
+}
+
+// KindOf returns the kind From would give err.
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
@@ -0,0 +1,48 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"telemed/responses"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestFrom(t *testing.T) {
+	unique := &pgconn.PgError{Code: uniqueViolation}
+	foreignKey := &pgconn.PgError{Code: foreignKeyViolation}
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{"typed", Conflict(responses.SLOT_TAKEN), 409, responses.SLOT_TAKEN},
+		{"wrapped typed", fmt.Errorf("booking: %w", Forbidden(responses.ACCOUNT_SUSPENDED)), 403, responses.ACCOUNT_SUSPENDED},
+		{"untyped", errors.New("connection refused"), 500, responses.SOMETHING_WRONG},
+		{"no rows", pgx.ErrNoRows, 404, responses.RECORD_NOT_FOUND},
+		{"internal no rows", Internal(pgx.ErrNoRows), 404, responses.RECORD_NOT_FOUND},
+		{"unique violation", Internal(unique), 409, responses.RECORD_EXISTS},
+		{"foreign key violation", fmt.Errorf("insert: %w", foreignKey), 409, responses.RECORD_IN_USE},
+		{"other database error", Internal(&pgconn.PgError{Code: "57014"}), 500, responses.SOMETHING_WRONG},
+		{"typed not found is kept", &Error{Kind: KindNotFound, Message: "pharmacy not found", Err: pgx.ErrNoRows}, 404, "pharmacy not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Kind.Status() != tt.wantStatus || got.Message != tt.wantMessage {
+				t.Errorf("got %d %q, want %d %q", got.Kind.Status(), got.Message, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestInternalKeepsCause(t *testing.T) {
+	cause := errors.New("disk full")
+	err := Internal(cause)
+	if err.Error() != responses.SOMETHING_WRONG || !errors.Is(err, cause) {
+		t.Errorf("got %q wrapping %v", err.Error(), errors.Unwrap(err))
+	}
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -28,3 +32,154 @@ var GatewaySecret = os.Getenv("GATEWAY_SECRET")
 var AppPassword = os.Getenv("APP_PASSWORD")
 var AppEmail = os.Getenv("APP_EMAIL")
 var JwtSecret = os.Getenv("JWT_SECRET")
+
+// MigrateOnStart applies pending migrations before the server starts
+// listening. Set MIGRATE_ON_START=false to run them only through the
+// `migrate` subcommand.
+var MigrateOnStart = getEnv("MIGRATE_ON_START", "true") == "true"
+
+type TimeoutConfig struct {
+	// Request is the deadline for an ordinary API request.
+	Request time.Duration
+	// Report is the deadline for the dashboard, analytics, audit log and
+	// search routes, which scan far more rows.
+	Report time.Duration
+	// Statement is the longest Postgres lets any one statement run, whatever
+	// deadline the caller set. It should be at least Report.
+	Statement time.Duration
+	// MaxConns caps the database pool.
+	MaxConns int32
+}
+
+// Timeouts reads REQUEST_TIMEOUT, REPORT_TIMEOUT, STATEMENT_TIMEOUT and
+// DB_MAX_CONNS. Missing or unparseable values fall back to the defaults.
+func Timeouts() TimeoutConfig {
+	cfg := TimeoutConfig{
+		Request:   durationEnv("REQUEST_TIMEOUT", 10*time.Second),
+		Report:    durationEnv("REPORT_TIMEOUT", 30*time.Second),
+		Statement: durationEnv("STATEMENT_TIMEOUT", 45*time.Second),
+		MaxConns:  5,
+	}
+	if n, err := strconv.Atoi(os.Getenv("DB_MAX_CONNS")); err == nil && n > 0 {
+		cfg.MaxConns = int32(n)
+	}
+	return cfg
+}
+
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+type ProxyConfig struct {
+	// Header is where trusted proxies put the client's address.
+	Header string
+	// Trusted are the addresses or CIDR ranges of our own reverse proxies.
+	// With none, the header is ignored and the peer address is the client.
+	Trusted []string
+}
+
+// Proxies reads PROXY_HEADER, default X-Forwarded-For, and TRUSTED_PROXIES,
+// a comma separated list of addresses and CIDR ranges.
+func Proxies() ProxyConfig {
+	cfg := ProxyConfig{Header: getEnv("PROXY_HEADER", "X-Forwarded-For")}
+	for _, raw := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy := strings.TrimSpace(raw); proxy != "" {
+			cfg.Trusted = append(cfg.Trusted, proxy)
+		}
+	}
+	return cfg
+}
+
+type MailConfig struct {
+	// Driver picks the notifier: "smtp", "log" or "memory".
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// TLS is "starttls", "tls" (implicit, usually port 465) or "none".
+	TLS string
+	// Dir is where the log driver writes .eml files; empty only logs.
+	Dir string
+}
+
+func Mail() MailConfig {
+	return MailConfig{
+		Driver:   getEnv("MAIL_DRIVER", "smtp"),
+		Host:     getEnv("SMTP_HOST", "smtp.gmail.com"),
+		Port:     getEnv("SMTP_PORT", "587"),
+		Username: getEnv("SMTP_USERNAME", AppEmail),
+		Password: getEnv("SMTP_PASSWORD", AppPassword),
+		From:     getEnv("MAIL_FROM", AppEmail),
+		TLS:      getEnv("SMTP_TLS", "starttls"),
+		Dir:      os.Getenv("MAIL_DIR"),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+type SMSConfig struct {
+	// Driver is "http" (SMSProvider against URL), "log" or "memory".
+	Driver string
+	URL    string
+	APIKey string
+	From   string
+}
+
+func SMS() SMSConfig {
+	return SMSConfig{
+		Driver: getEnv("SMS_DRIVER", "log"),
+		URL:    os.Getenv("SMS_URL"),
+		APIKey: os.Getenv("SMS_API_KEY"),
+		From:   getEnv("SMS_FROM", "Telemed"),
+	}
+}
+
+type PushConfig struct {
+	// Driver is "http" (PushProvider against URL), "log" or "memory".
+	Driver    string
+	URL       string
+	ServerKey string
+}
+
+func Push() PushConfig {
+	return PushConfig{
+		Driver:    getEnv("PUSH_DRIVER", "log"),
+		URL:       os.Getenv("PUSH_URL"),
+		ServerKey: os.Getenv("PUSH_SERVER_KEY"),
+	}
+}
+
+type ReminderConfig struct {
+	// Offsets are how long before an appointment reminders go out.
+	Offsets []time.Duration
+	// Interval is how often the scheduler looks for due reminders.
+	Interval time.Duration
+}
+
+// Reminders reads REMINDER_OFFSETS (comma separated durations, e.g.
+// "24h,1h") and REMINDER_INTERVAL. Unparseable values are skipped.
+func Reminders() ReminderConfig {
+	cfg := ReminderConfig{Interval: time.Minute}
+	for _, raw := range strings.Split(getEnv("REMINDER_OFFSETS", "24h,1h"), ",") {
+		offset, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || offset <= 0 {
+			log.Printf("Ignoring reminder offset %q", raw)
+			continue
+		}
+		cfg.Offsets = append(cfg.Offsets, offset)
+	}
+	if interval, err := time.ParseDuration(os.Getenv("REMINDER_INTERVAL")); err == nil && interval > 0 {
+		cfg.Interval = interval
+	}
+	return cfg
+}
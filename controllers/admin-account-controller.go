@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"telemed/models"
+	"telemed/responses"
+	"telemed/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (AdminController) InviteAdmin(c *fiber.Ctx) error {
+	var payload models.InviteAdmin
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.InviteAdmin(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.INVITE_SENT, res, 201)
+}
+
+func (AdminController) AcceptInvite(c *fiber.Ctx) error {
+	var payload models.AcceptAdminInvite
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.AcceptInvite(c.UserContext(), clientInfo(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.ACCOUNT_CREATED, res, 200)
+}
+
+func (AdminController) FetchAdmins(c *fiber.Ctx) error {
+	page, err := adminServer.GetAdmins(c.UserContext(), listParams(c))
+	if err != nil {
+		return err
+	}
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
+}
+
+func (AdminController) UpdateAdminStatus(c *fiber.Ctx) error {
+	var payload models.UpdateAdminAccount
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.AdminTag = c.Params("admintag")
+	if errs := append(validation.Struct(payload), validation.Var("status", payload.Status, "required")...); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.UpdateAdminStatus(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (AdminController) UpdateAdminRole(c *fiber.Ctx) error {
+	var payload models.UpdateAdminAccount
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.AdminTag = c.Params("admintag")
+	if errs := append(validation.Struct(payload), validation.Var("role", payload.Role, "required")...); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.UpdateAdminRole(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (AdminController) ForceAdminPasswordReset(c *fiber.Ctx) error {
+	var payload models.UpdateAdminAccount
+	payload.AdminTag = c.Params("admintag")
+	if payload.AdminTag == "" {
+		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	}
+	res, err := adminServer.ForceAdminPasswordReset(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (AdminController) FetchLockouts(c *fiber.Ctx) error {
+	page, err := adminServer.GetLockouts(c.UserContext(), listParams(c))
+	if err != nil {
+		return err
+	}
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
+}
+
+func (AdminController) ResetLockout(c *fiber.Ctx) error {
+	var payload models.LockoutReq
+	payload.Scope = c.Params("scope")
+	payload.Key = c.Params("key")
+	if payload.Scope == "" || payload.Key == "" {
+		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	}
+	res, err := adminServer.ResetLockout(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.LOCKOUT_CLEARED, res, 200)
+}
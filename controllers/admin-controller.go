@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"telemed/middleware"
 	"telemed/models"
 	"telemed/responses"
 	"telemed/servers"
+	"telemed/validation"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,6 +14,40 @@ type AdminController struct{}
 
 var adminServer servers.AdminServer
 
+// SetAdminServer sets the server the admin handlers call. main sets it once,
+// after connecting to the database.
+func SetAdminServer(s servers.AdminServer) {
+	adminServer = s
+}
+
+// auditActor describes the signed-in admin behind a request for the audit log.
+func auditActor(c *fiber.Ctx) models.Actor {
+	var actor models.Actor
+	actor.AdminTag, _ = c.Locals("usertag").(string)
+	actor.Role, _ = c.Locals("role").(string)
+	actor.IP = middleware.ClientIP(c)
+	actor.RequestID, _ = c.Locals("requestid").(string)
+	return actor
+}
+
+// listParams reads the shared list query parameters. Every other non-empty
+// query parameter is passed on as a filter for the list spec to check.
+func listParams(c *fiber.Ctx) models.ListParams {
+	params := models.ListParams{
+		Limit:   c.QueryInt("limit", 0),
+		Cursor:  c.Query("cursor"),
+		Sort:    c.Query("sort"),
+		Filters: map[string]string{},
+	}
+	for key, value := range c.Queries() {
+		if key == "limit" || key == "cursor" || key == "sort" || value == "" {
+			continue
+		}
+		params.Filters[key] = value
+	}
+	return params
+}
+
 func (AdminController) Login(c *fiber.Ctx) error {
 	var payload models.Adminlogin
 	//parse data from request
@@ -19,13 +55,14 @@ func (AdminController) Login(c *fiber.Ctx) error {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
 	//vet if data exists
-	if payload.Email == "" || payload.Password == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
+	payload.ClientInfo = clientInfo(c)
 	//pass data to servers
-	res, err := adminServer.Login(payload)
+	res, err := adminServer.Login(c.UserContext(), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.OTP_SENT, res, 200)
 }
@@ -35,12 +72,13 @@ func (AdminController) VerifyOTP(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.OTP == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.VerifyOTP(payload)
+	payload.ClientInfo = clientInfo(c)
+	res, err := adminServer.VerifyOTP(c.UserContext(), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.OTP_VERIFIED, res, 200)
 }
@@ -50,12 +88,12 @@ func (AdminController) ForgotPassword(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.Email == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.ForgotPassword(payload)
+	res, err := adminServer.ForgotPassword(c.UserContext(), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.OTP_SENT, res, 200)
 }
@@ -65,12 +103,13 @@ func (AdminController) VerifyPwdOTP(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.OTP == "" || payload.Email == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.VerifyPwdOTP(payload)
+	payload.ClientInfo = clientInfo(c)
+	res, err := adminServer.VerifyPwdOTP(c.UserContext(), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.OTP_VERIFIED, res, 200)
 }
@@ -80,19 +119,19 @@ func (AdminController) ResetPassword(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.Email == "" || payload.NewPassword == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.ResetPassword(payload)
+	res, err := adminServer.ResetPassword(c.UserContext(), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.PASSWORD_RESET_SUCCESS, res, 200)
 }
 func (AdminController) FetchDashboardSummary(c *fiber.Ctx) error {
-	res, err := adminServer.GetDashboardSummary()
+	res, err := adminServer.GetDashboardSummary(c.UserContext())
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -102,24 +141,24 @@ func (AdminController) FetchAnalytics(c *fiber.Ctx) error {
 	data.Month = c.Query("month")
 	data.Year = c.Query("year")
 
-	if data.Metric == "" || data.Month == "" || data.Year == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(data); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
 
-	res, err := adminServer.GetAnalytics(data)
+	res, err := adminServer.GetAnalytics(c.UserContext(), data)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 
 }
 
 func (AdminController) FetchAppointments(c *fiber.Ctx) error {
-	res, err := adminServer.GetAppointments()
+	page, err := adminServer.GetAppointments(c.UserContext(), listParams(c), callerLocation(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) FetchAppointmentByID(c *fiber.Ctx) error {
@@ -127,25 +166,25 @@ func (AdminController) FetchAppointmentByID(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.ID == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.GetAppointmentByID(payload)
+	res, err := adminServer.GetAppointmentByID(c.UserContext(), payload, callerLocation(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
 
 func (AdminController) FetchDoctorByID(c *fiber.Ctx) error {
 	var payload models.Doctorreq
-	payload.DoctorTag = c.Params("id")
+	payload.DoctorTag = c.Params("doctortag")
 	if payload.DoctorTag == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	res, err := adminServer.GetDoctorByID(payload)
+	res, err := adminServer.GetDoctorByID(c.UserContext(), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -156,12 +195,12 @@ func (AdminController) UpdateAppointmentStatus(c *fiber.Ctx) error {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
 	payload.Appointment_id = c.Params("id")
-	if payload.Appointment_id == "" || payload.Status == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.UpdateAppointmentStatus(payload)
+	res, err := adminServer.UpdateAppointmentStatus(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
@@ -173,54 +212,94 @@ func (a *AdminController) UpdateAppointment(c *fiber.Ctx) error {
 	}
 
 	payload.Appointment_id = c.Params("id")
-	if payload.Appointment_id == "" || payload.NewScheduledAt == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
 
-	res, err := adminServer.RescheduleAppointment(payload)
+	res, err := adminServer.RescheduleAppointment(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
 
 func (AdminController) FetchDoctors(c *fiber.Ctx) error {
-	res, err := adminServer.GetDoctors()
+	page, err := adminServer.GetDoctors(c.UserContext(), listParams(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) DeleteDoctor(c *fiber.Ctx) error {
 	var payload models.Doctorreq
-	payload.DoctorTag = c.Params("id")
+	payload.DoctorTag = c.Params("doctortag")
 	if payload.DoctorTag == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	err := adminServer.DeleteDoctor(payload)
+	err := adminServer.DeleteDoctor(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, nil, 200)
 }
 
-func (AdminController) FetchPatients(c *fiber.Ctx) error {
-	res, err := adminServer.GetPatients()
+func (AdminController) FetchDoctorApplications(c *fiber.Ctx) error {
+	params := listParams(c)
+	if _, ok := params.Filters["status"]; !ok {
+		params.Filters["status"] = "pending"
+	}
+	page, err := adminServer.GetDoctorApplications(c.UserContext(), params)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
+	}
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
+}
+
+func (AdminController) FetchDoctorApplicationByID(c *fiber.Ctx) error {
+	doctorTag := c.Params("doctortag")
+	if doctorTag == "" {
+		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	}
+	res, err := adminServer.GetDoctorApplicationByID(c.UserContext(), doctorTag)
+	if err != nil {
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
 
+func (AdminController) ReviewDoctorApplication(c *fiber.Ctx) error {
+	var payload models.ReviewDoctorApplication
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.DoctorTag = c.Params("doctortag")
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.ReviewDoctorApplication(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (AdminController) FetchPatients(c *fiber.Ctx) error {
+	page, err := adminServer.GetPatients(c.UserContext(), listParams(c))
+	if err != nil {
+		return err
+	}
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
+}
+
 func (AdminController) FetchPatientByUsertag(c *fiber.Ctx) error {
 	var payload models.PatientIdReq
 	payload.Usertag = c.Params("usertag")
 
-	res, err := adminServer.GetPatientByUsertag(payload)
+	res, err := adminServer.GetPatientByUsertag(c.UserContext(), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -231,9 +310,9 @@ func (AdminController) DeletePatient(c *fiber.Ctx) error {
 	if payload.Usertag == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	err := adminServer.DeletePatient(payload)
+	err := adminServer.DeletePatient(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, nil, 200)
 }
@@ -244,22 +323,22 @@ func (AdminController) EditPatient(c *fiber.Ctx) error {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
 	payload.UserTag = c.Params("usertag")
-	if payload.UserTag == "" || payload.Firstname == "" || payload.Lastname == "" || payload.Phone_no == "" || payload.Dob == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.EditPatient(payload)
+	res, err := adminServer.EditPatient(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
 
 func (AdminController) FetchPharmacy(c *fiber.Ctx) error {
-	res, err := adminServer.GetPharmacy()
+	page, err := adminServer.GetPharmacy(c.UserContext(), listParams(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) CreatePharmacy(c *fiber.Ctx) error {
@@ -267,12 +346,12 @@ func (AdminController) CreatePharmacy(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.PharmacyName == "" || payload.Address == "" || payload.Country == "" || payload.State == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.CreatePharmacy(payload)
+	res, err := adminServer.CreatePharmacy(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_CREATED, res, 201)
 }
@@ -282,9 +361,9 @@ func (AdminController) DeletePharmacy(c *fiber.Ctx) error {
 	if pharmacyID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	err := adminServer.DeletePharmacy(pharmacyID)
+	err := adminServer.DeletePharmacy(c.UserContext(), auditActor(c), pharmacyID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, nil, 200)
 }
@@ -294,35 +373,35 @@ func (AdminController) FetchPharmacyByID(c *fiber.Ctx) error {
 	if pharmacyID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	res, err := adminServer.GetPharmacyByID(pharmacyID)
+	res, err := adminServer.GetPharmacyByID(c.UserContext(), pharmacyID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
 
 func (AdminController) UpdatePharmacy(c *fiber.Ctx) error {
-	var payload models.Pharmacy
+	var payload models.PharmacyPatch
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	payload.PharmacyID = c.Params("pharmacy_id")
-	if payload.PharmacyID == "" || payload.PharmacyName == "" || payload.Address == "" || payload.Country == "" || payload.State == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	pharmacyID := c.Params("pharmacy_id")
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.UpdatePharmacy(payload)
+	res, err := adminServer.UpdatePharmacy(c.UserContext(), auditActor(c), pharmacyID, payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
 
 func (AdminController) FetchHospitals(c *fiber.Ctx) error {
-	res, err := adminServer.GetHospitals()
+	page, err := adminServer.GetHospitals(c.UserContext(), listParams(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) CreateHospital(c *fiber.Ctx) error {
@@ -330,12 +409,12 @@ func (AdminController) CreateHospital(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.HospitalName == "" || payload.Address == "" || payload.Country == "" || payload.State == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.CreateHospital(payload)
+	res, err := adminServer.CreateHospital(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_CREATED, res, 201)
 }
@@ -345,9 +424,9 @@ func (AdminController) FetchHospitalByID(c *fiber.Ctx) error {
 	if hospitalID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	res, err := adminServer.GetHospitalByID(hospitalID)
+	res, err := adminServer.GetHospitalByID(c.UserContext(), hospitalID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -357,34 +436,34 @@ func (AdminController) DeleteHospital(c *fiber.Ctx) error {
 	if hospitalID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	err := adminServer.DeleteHospital(hospitalID)
+	err := adminServer.DeleteHospital(c.UserContext(), auditActor(c), hospitalID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, nil, 200)
 }
 func (AdminController) UpdateHospital(c *fiber.Ctx) error {
-	var payload models.Hospital
+	var payload models.HospitalPatch
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	payload.HospitalID = c.Params("hospital_id")
-	if payload.HospitalID == "" || payload.HospitalName == "" || payload.Address == "" || payload.Country == "" || payload.State == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	hospitalID := c.Params("hospital_id")
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.UpdateHospital(payload)
+	res, err := adminServer.UpdateHospital(c.UserContext(), auditActor(c), hospitalID, payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
 
 func (AdminController) FetchInventory(c *fiber.Ctx) error {
-	res, err := adminServer.GetInventory()
+	page, err := adminServer.GetInventory(c.UserContext(), listParams(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) FetchInventoryByID(c *fiber.Ctx) error {
@@ -392,9 +471,9 @@ func (AdminController) FetchInventoryByID(c *fiber.Ctx) error {
 	if inventoryID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	res, err := adminServer.GetInventoryByID(inventoryID)
+	res, err := adminServer.GetInventoryByID(c.UserContext(), inventoryID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -404,12 +483,12 @@ func (AdminController) CreateInventory(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.ProductName == "" || payload.Milligrams == "" || payload.Price == 0 {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.CreateInventory(payload)
+	res, err := adminServer.CreateInventory(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_CREATED, res, 201)
 }
@@ -419,35 +498,35 @@ func (AdminController) DeleteInventory(c *fiber.Ctx) error {
 	if inventoryID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	err := adminServer.DeleteInventory(inventoryID)
+	err := adminServer.DeleteInventory(c.UserContext(), auditActor(c), inventoryID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, nil, 200)
 }
 
 func (AdminController) UpdateInventory(c *fiber.Ctx) error {
-	var payload models.Inventory
+	var payload models.InventoryPatch
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	payload.ProductID = c.Params("inventory_id")
-	if payload.ProductID == "" || payload.ProductName == "" || payload.Milligrams == "" || payload.Price == 0 {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	productID := c.Params("inventory_id")
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.UpdateInventory(payload)
+	res, err := adminServer.UpdateInventory(c.UserContext(), auditActor(c), productID, payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
 
 func (AdminController) FetchOrders(c *fiber.Ctx) error {
-	res, err := adminServer.GetOrders()
+	page, err := adminServer.GetOrders(c.UserContext(), listParams(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) FetchOrderByID(c *fiber.Ctx) error {
@@ -455,9 +534,9 @@ func (AdminController) FetchOrderByID(c *fiber.Ctx) error {
 	if orderID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	res, err := adminServer.GetOrderByID(orderID)
+	res, err := adminServer.GetOrderByID(c.UserContext(), orderID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -468,22 +547,22 @@ func (AdminController) UpdateOrder(c *fiber.Ctx) error {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
 	payload.OrderID = c.Params("order_id")
-	if payload.OrderID == "" || payload.Status == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.UpdateOrder(payload)
+	res, err := adminServer.UpdateOrder(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
 
 func (AdminController) FetchTestCenters(c *fiber.Ctx) error {
-	res, err := adminServer.GetTestCenters()
+	page, err := adminServer.GetTestCenters(c.UserContext(), listParams(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) FetchTestCenterByID(c *fiber.Ctx) error {
@@ -491,9 +570,9 @@ func (AdminController) FetchTestCenterByID(c *fiber.Ctx) error {
 	if testCenterID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	res, err := adminServer.GetTestCenterByID(testCenterID)
+	res, err := adminServer.GetTestCenterByID(c.UserContext(), testCenterID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -503,12 +582,12 @@ func (AdminController) CreateTestCenter(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	if payload.CentreName == "" || payload.Address == "" || payload.Country == "" || payload.State == "" || payload.TestType == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.CreateTestCenter(payload)
+	res, err := adminServer.CreateTestCenter(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_CREATED, res, 201)
 }
@@ -518,41 +597,34 @@ func (AdminController) DeleteCenter(c *fiber.Ctx) error {
 	if testCenterID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	err := adminServer.DeleteTestCenter(testCenterID)
+	err := adminServer.DeleteTestCenter(c.UserContext(), auditActor(c), testCenterID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, nil, 200)
 }
 func (AdminController) UpdateTestCenter(c *fiber.Ctx) error {
-	var payload models.TestCentre
+	var payload models.TestCentrePatch
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	payload.CentreID = c.Params("test_center_id")
-	if payload.CentreID == "" || payload.CentreName == "" || payload.Address == "" || payload.Country == "" || payload.State == "" || payload.TestType == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	centerID := c.Params("test_center_id")
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.UpdateTestCenter(payload)
+	res, err := adminServer.UpdateTestCenter(c.UserContext(), auditActor(c), centerID, payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
 
 func (AdminController) FetchReviews(c *fiber.Ctx) error {
-	var payload models.Getreviews
-	if err := c.BodyParser(&payload); err != nil {
-		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
-	}
-	if payload.Status == "" || payload.Status != "approved" && payload.Status != "pending" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
-	}
-	res, err := adminServer.GetReviews(payload)
+	page, err := adminServer.GetReviews(c.UserContext(), listParams(c))
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
-	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
 }
 
 func (AdminController) FetchReviewByID(c *fiber.Ctx) error {
@@ -560,9 +632,9 @@ func (AdminController) FetchReviewByID(c *fiber.Ctx) error {
 	if reviewID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	res, err := adminServer.GetReviewByID(reviewID)
+	res, err := adminServer.GetReviewByID(c.UserContext(), reviewID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -572,21 +644,21 @@ func (AdminController) DeleteReview(c *fiber.Ctx) error {
 	if reviewID == "" {
 		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
 	}
-	err := adminServer.DeleteReview(reviewID)
+	err := adminServer.DeleteReview(c.UserContext(), auditActor(c), reviewID)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, nil, 200)
 }
 
 func (AdminController) FetchAdminProfile(c *fiber.Ctx) error {
-	AdminTag := c.Get("admintag")
+	AdminTag, _ := c.Locals("usertag").(string)
 	if AdminTag == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
 	}
-	res, err := adminServer.GetAdminProfile(AdminTag)
+	res, err := adminServer.GetAdminProfile(c.UserContext(), AdminTag)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
 }
@@ -596,13 +668,50 @@ func (AdminController) UpdateAdminProfile(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
 	}
-	payload.AdminTag = c.Get("admintag")
-	if payload.AdminTag == "" || payload.Firstname == "" || payload.Lastname == "" || payload.Email == "" {
-		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	payload.AdminTag, _ = c.Locals("usertag").(string)
+	if payload.AdminTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
 	}
-	res, err := adminServer.UpdateAdminProfile(payload)
+	res, err := adminServer.UpdateAdminProfile(c.UserContext(), auditActor(c), payload)
 	if err != nil {
-		return responses.ErrorResponse(c, err.Error(), 400)
+		return err
 	}
 	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
 }
+
+func (AdminController) FetchAuditLogs(c *fiber.Ctx) error {
+	page, err := adminServer.GetAuditLogs(c.UserContext(), listParams(c))
+	if err != nil {
+		return err
+	}
+	return responses.PagedResponse(c, responses.DATA_FETCHED, page.Items, page.Meta, 200)
+}
+
+// searchPermissions limits each search group to the staff who may already
+// list those records.
+var searchPermissions = map[string]middleware.Permission{
+	"patients":     middleware.ViewPatients,
+	"doctors":      middleware.ViewDoctors,
+	"inventory":    middleware.ViewInventory,
+	"hospitals":    middleware.ManageHospitals,
+	"pharmacies":   middleware.ViewPharmacies,
+	"test_centres": middleware.ViewTestCentres,
+}
+
+func (AdminController) Search(c *fiber.Ctx) error {
+	role, _ := c.Locals("role").(string)
+	var groups []string
+	for group, permission := range searchPermissions {
+		if middleware.HasPermission(role, permission) {
+			groups = append(groups, group)
+		}
+	}
+	res, err := adminServer.Search(c.UserContext(), c.Query("q"), c.QueryInt("limit", 0), groups)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
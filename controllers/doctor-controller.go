@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"telemed/models"
+	"telemed/responses"
+	"telemed/servers"
+	"telemed/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DoctorController struct{}
+
+var doctorServer servers.DoctorServer
+
+// SetDoctorServer sets the server the doctor handlers call. main sets it once,
+// after connecting to the database.
+func SetDoctorServer(s servers.DoctorServer) {
+	doctorServer = s
+}
+
+func (DoctorController) Login(c *fiber.Ctx) error {
+	var payload models.DoctorLogin
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := doctorServer.Login(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.OTP_SENT, res, 200)
+}
+
+func (DoctorController) VerifyOTP(c *fiber.Ctx) error {
+	var payload models.DoctorOTPVerify
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	payload.ClientInfo = clientInfo(c)
+	res, err := doctorServer.VerifyOTP(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.OTP_VERIFIED, res, 200)
+}
+
+func (DoctorController) Apply(c *fiber.Ctx) error {
+	var payload models.DoctorApplicationReq
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := doctorServer.Apply(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.APPLICATION_SUBMITTED, res, 201)
+}
+
+func (DoctorController) FetchAppointments(c *fiber.Ctx) error {
+	doctorTag, _ := c.Locals("usertag").(string)
+	if doctorTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := doctorServer.GetAppointments(c.UserContext(), doctorTag, callerLocation(c))
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (DoctorController) UpdateAppointmentStatus(c *fiber.Ctx) error {
+	var payload models.DoctorAppointmentStatus
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.DoctorTag, _ = c.Locals("usertag").(string)
+	if payload.DoctorTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	payload.Appointment_id = c.Params("id")
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := doctorServer.UpdateAppointmentStatus(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (DoctorController) FetchProfile(c *fiber.Ctx) error {
+	doctorTag, _ := c.Locals("usertag").(string)
+	if doctorTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := doctorServer.GetProfile(c.UserContext(), doctorTag)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (DoctorController) UpdateProfile(c *fiber.Ctx) error {
+	var payload models.DoctorProfilePatch
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.DoctorTag, _ = c.Locals("usertag").(string)
+	if payload.DoctorTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := doctorServer.UpdateProfile(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (DoctorController) FetchAvailability(c *fiber.Ctx) error {
+	doctorTag, _ := c.Locals("usertag").(string)
+	if doctorTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := doctorServer.GetAvailability(c.UserContext(), doctorTag)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (DoctorController) UpdateAvailability(c *fiber.Ctx) error {
+	var payload models.DoctorSchedule
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.DoctorTag, _ = c.Locals("usertag").(string)
+	if payload.DoctorTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := doctorServer.UpdateAvailability(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"telemed/models"
+	"telemed/responses"
+	"telemed/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (AdminController) FetchMFAStatus(c *fiber.Ctx) error {
+	adminTag, _ := c.Locals("usertag").(string)
+	if adminTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := adminServer.GetMFAStatus(c.UserContext(), adminTag)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (AdminController) EnrollTOTP(c *fiber.Ctx) error {
+	adminTag, _ := c.Locals("usertag").(string)
+	if adminTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := adminServer.EnrollTOTP(c.UserContext(), adminTag)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_CREATED, res, 200)
+}
+
+func (AdminController) ConfirmTOTP(c *fiber.Ctx) error {
+	var payload models.TOTPCode
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.AdminTag, _ = c.Locals("usertag").(string)
+	if payload.AdminTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.ConfirmTOTP(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.TOTP_ENABLED, res, 200)
+}
+
+func (AdminController) DisableTOTP(c *fiber.Ctx) error {
+	var payload models.TOTPCode
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.AdminTag, _ = c.Locals("usertag").(string)
+	if payload.AdminTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.DisableTOTP(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.TOTP_DISABLED, res, 200)
+}
+
+func (AdminController) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var payload models.TOTPCode
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.AdminTag, _ = c.Locals("usertag").(string)
+	if payload.AdminTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := adminServer.RegenerateRecoveryCodes(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_CREATED, res, 200)
+}
+
+func (AdminController) FetchMFAPolicy(c *fiber.Ctx) error {
+	res, err := adminServer.GetMFAPolicy(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (AdminController) UpdateMFAPolicy(c *fiber.Ctx) error {
+	var payload models.MFAPolicy
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	res, err := adminServer.UpdateMFAPolicy(c.UserContext(), auditActor(c), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
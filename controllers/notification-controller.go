@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"telemed/models"
+	"telemed/responses"
+	"telemed/servers"
+	"telemed/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationController struct{}
+
+var notificationServer servers.NotificationServer
+
+// SetNotificationServer sets the server the notification handlers call. main
+// sets it once, after connecting to the database.
+func SetNotificationServer(s servers.NotificationServer) {
+	notificationServer = s
+}
+
+func (NotificationController) FetchPreferences(c *fiber.Ctx) error {
+	payload := sessionReq(c)
+	if payload.Subject == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := notificationServer.GetPreferences(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (NotificationController) UpdatePreferences(c *fiber.Ctx) error {
+	var payload models.NotificationPreferencesReq
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	session := sessionReq(c)
+	payload.Subject, payload.Role = session.Subject, session.Role
+	if payload.Subject == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := notificationServer.UpdatePreferences(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (NotificationController) RegisterDevice(c *fiber.Ctx) error {
+	var payload models.PushDevice
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	session := sessionReq(c)
+	payload.Subject, payload.Role = session.Subject, session.Role
+	if payload.Subject == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := notificationServer.RegisterDevice(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DEVICE_REGISTERED, res, 201)
+}
+
+func (NotificationController) RemoveDevice(c *fiber.Ctx) error {
+	var payload models.PushDevice
+	session := sessionReq(c)
+	payload.Subject, payload.Role = session.Subject, session.Role
+	payload.Token = c.Params("token")
+	if payload.Subject == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if payload.Token == "" {
+		return responses.ErrorResponse(c, responses.INCOMPLETE_DATA, 400)
+	}
+	res, err := notificationServer.RemoveDevice(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
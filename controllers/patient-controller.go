@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"telemed/models"
+	"telemed/responses"
+	"telemed/servers"
+	"telemed/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PatientController struct{}
+
+var patientServer servers.PatientServer
+
+// SetPatientServer sets the server the patient handlers call. main sets it once,
+// after connecting to the database.
+func SetPatientServer(s servers.PatientServer) {
+	patientServer = s
+}
+
+func (PatientController) Register(c *fiber.Ctx) error {
+	var payload models.PatientRegister
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := patientServer.Register(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.OTP_SENT, res, 201)
+}
+
+func (PatientController) VerifyEmail(c *fiber.Ctx) error {
+	var payload models.PatientVerifyEmail
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	payload.ClientInfo = clientInfo(c)
+	res, err := patientServer.VerifyEmail(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.EMAIL_VERIFIED, res, 200)
+}
+
+func (PatientController) ResendOTP(c *fiber.Ctx) error {
+	var payload models.ForgotPassword
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := patientServer.ResendOTP(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.OTP_SENT, res, 200)
+}
+
+func (PatientController) Login(c *fiber.Ctx) error {
+	var payload models.PatientLogin
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	payload.ClientInfo = clientInfo(c)
+	res, err := patientServer.Login(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.LOGIN_SUCCESSFUL, res, 200)
+}
+
+func (PatientController) ForgotPassword(c *fiber.Ctx) error {
+	var payload models.ForgotPassword
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := patientServer.ForgotPassword(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.OTP_SENT, res, 200)
+}
+
+func (PatientController) ResetPassword(c *fiber.Ctx) error {
+	var payload models.PatientResetPassword
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	payload.ClientInfo = clientInfo(c)
+	res, err := patientServer.ResetPassword(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.PASSWORD_RESET_SUCCESS, res, 200)
+}
+
+func (PatientController) FetchProfile(c *fiber.Ctx) error {
+	usertag, _ := c.Locals("usertag").(string)
+	if usertag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := patientServer.GetProfile(c.UserContext(), usertag)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (PatientController) UpdateProfile(c *fiber.Ctx) error {
+	var payload models.PatientProfilePatch
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.UserTag, _ = c.Locals("usertag").(string)
+	if payload.UserTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := patientServer.UpdateProfile(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (PatientController) FetchDoctorSlots(c *fiber.Ctx) error {
+	payload := models.SlotQuery{
+		DoctorTag: c.Params("doctortag"),
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+	}
+	res, err := patientServer.GetDoctorSlots(c.UserContext(), payload, callerLocation(c))
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (PatientController) BookAppointment(c *fiber.Ctx) error {
+	var payload models.BookAppointmentReq
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.UserTag, _ = c.Locals("usertag").(string)
+	if payload.UserTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := patientServer.BookAppointment(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.APPOINTMENT_BOOKED, res, 201)
+}
+
+func (PatientController) FetchAppointments(c *fiber.Ctx) error {
+	usertag, _ := c.Locals("usertag").(string)
+	if usertag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := patientServer.GetAppointments(c.UserContext(), usertag, callerLocation(c))
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_FETCHED, res, 200)
+}
+
+func (PatientController) CancelAppointment(c *fiber.Ctx) error {
+	var payload models.PatientAppointmentReq
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.UserTag, _ = c.Locals("usertag").(string)
+	if payload.UserTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	payload.Appointment_id = c.Params("id")
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := patientServer.CancelAppointment(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
+
+func (PatientController) RescheduleAppointment(c *fiber.Ctx) error {
+	var payload models.PatientAppointmentReq
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	payload.UserTag, _ = c.Locals("usertag").(string)
+	if payload.UserTag == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	payload.Appointment_id = c.Params("id")
+	if errs := append(validation.Struct(payload), validation.Var("new_scheduled_at", payload.NewScheduledAt, "required")...); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	res, err := patientServer.RescheduleAppointment(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.DATA_UPDATED, res, 200)
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"telemed/middleware"
+	"telemed/models"
+	"telemed/responses"
+	"telemed/servers"
+	"telemed/validation"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionController struct{}
+
+var sessionServer servers.SessionServer
+
+// SetSessionServer sets the server the session handlers call. main sets it once,
+// after connecting to the database.
+func SetSessionServer(s servers.SessionServer) {
+	sessionServer = s
+}
+
+func clientInfo(c *fiber.Ctx) models.ClientInfo {
+	return models.ClientInfo{IP: middleware.ClientIP(c), UserAgent: c.Get("User-Agent")}
+}
+
+// callerLocation is the timezone times are rendered in for this request: the
+// X-Timezone header if it names a valid zone, otherwise the caller's own.
+func callerLocation(c *fiber.Ctx) *time.Location {
+	if tz := c.Get("X-Timezone"); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	subject, _ := c.Locals("usertag").(string)
+	role, _ := c.Locals("role").(string)
+	return sessionServer.AccountLocation(c.UserContext(), role, subject)
+}
+
+func sessionReq(c *fiber.Ctx) models.SessionReq {
+	var data models.SessionReq
+	data.SessionID, _ = c.Locals("sid").(string)
+	data.Subject, _ = c.Locals("usertag").(string)
+	data.Role, _ = c.Locals("role").(string)
+	return data
+}
+
+func (SessionController) Refresh(c *fiber.Ctx) error {
+	var payload models.RefreshTokenReq
+	if err := c.BodyParser(&payload); err != nil {
+		return responses.ErrorResponse(c, responses.BAD_DATA, 400)
+	}
+	if errs := validation.Struct(payload); errs != nil {
+		return responses.ValidationErrorResponse(c, errs)
+	}
+	payload.ClientInfo = clientInfo(c)
+	res, err := sessionServer.Refresh(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.SESSION_REFRESHED, res, 200)
+}
+
+func (SessionController) Logout(c *fiber.Ctx) error {
+	payload := sessionReq(c)
+	if payload.SessionID == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := sessionServer.Logout(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.LOGGED_OUT, res, 200)
+}
+
+func (SessionController) LogoutAll(c *fiber.Ctx) error {
+	payload := sessionReq(c)
+	if payload.Subject == "" {
+		return responses.ErrorResponse(c, responses.UNAUTHORIZED_ACCESS, 401)
+	}
+	res, err := sessionServer.LogoutAll(c.UserContext(), payload)
+	if err != nil {
+		return err
+	}
+	return responses.SuccessResponse(c, responses.LOGGED_OUT, res, 200)
+}
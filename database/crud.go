@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// ErrUnknownColumn is returned when a partial update names a column its
+// table was not registered with.
+var ErrUnknownColumn = errors.New("unknown column")
+
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// tables records every registered table name. Between it and Table.byName,
+// no identifier reaches generated SQL without having been declared in code.
+var tables = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: map[string]bool{}}
+
+// Column is one column of a Table. Select and Value are optional format
+// strings for columns whose SQL type differs from their Go field: Select
+// wraps the quoted column when it is read, e.g. "%s::text", and Value wraps
+// the placeholder when it is written, e.g. "string_to_array(%s, ',')".
+type Column struct {
+	Name   string
+	Select string
+	Value  string
+}
+
+func (c Column) selectExpr() string {
+	quoted := pgx.Identifier{c.Name}.Sanitize()
+	if c.Select == "" {
+		return quoted
+	}
+	return fmt.Sprintf(c.Select, quoted)
+}
+
+func (c Column) valueExpr(placeholder string) string {
+	if c.Value == "" {
+		return placeholder
+	}
+	return fmt.Sprintf(c.Value, placeholder)
+}
+
+// Table reads and writes rows of one table as T. The database assigns Key on
+// insert, and every write returns the row as it was stored.
+type Table[T any] struct {
+	name    string
+	key     Column
+	columns []Column
+	byName  map[string]Column
+	fields  func(*T) []any
+}
+
+// NewTable registers a table. fields returns the scan targets for key
+// followed by columns, in order. It panics on an identifier that is not a
+// plain lower case name or on a table registered twice, since both are
+// programming errors.
+func NewTable[T any](name string, key Column, columns []Column, fields func(*T) []any) *Table[T] {
+	tables.Lock()
+	defer tables.Unlock()
+	if !identifierPattern.MatchString(name) {
+		panic(fmt.Sprintf("database: invalid table name %q", name))
+	}
+	if tables.names[name] {
+		panic(fmt.Sprintf("database: table %q registered twice", name))
+	}
+	t := &Table[T]{name: name, key: key, columns: columns, byName: map[string]Column{}, fields: fields}
+	for _, c := range append([]Column{key}, columns...) {
+		if _, repeated := t.byName[c.Name]; repeated || !identifierPattern.MatchString(c.Name) {
+			panic(fmt.Sprintf("database: invalid or repeated column %q in table %q", c.Name, name))
+		}
+		t.byName[c.Name] = c
+	}
+	tables.names[name] = true
+	return t
+}
+
+func (t *Table[T]) quotedName() string {
+	return pgx.Identifier{t.name}.Sanitize()
+}
+
+func (t *Table[T]) returning() string {
+	exprs := []string{t.key.selectExpr()}
+	for _, c := range t.columns {
+		exprs = append(exprs, c.selectExpr())
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func (t *Table[T]) scan(row pgx.Row) (T, error) {
+	var v T
+	err := row.Scan(t.fields(&v)...)
+	return v, err
+}
+
+// Get returns the row with key id, or pgx.ErrNoRows.
+func (t *Table[T]) Get(ctx context.Context, db Queryer, id any) (T, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", t.returning(), t.quotedName(), pgx.Identifier{t.key.Name}.Sanitize())
+	return t.scan(db.QueryRow(ctx, sql, id))
+}
+
+// Insert stores every column of row except the key and returns the stored
+// row, key included.
+func (t *Table[T]) Insert(ctx context.Context, db Queryer, row T) (T, error) {
+	values := t.fields(&row)[1:]
+	names := make([]string, len(t.columns))
+	placeholders := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = pgx.Identifier{c.Name}.Sanitize()
+		placeholders[i] = c.valueExpr(fmt.Sprintf("$%d", i+1))
+	}
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		t.quotedName(), strings.Join(names, ", "), strings.Join(placeholders, ", "), t.returning())
+	return t.scan(db.QueryRow(ctx, sql, values...))
+}
+
+// Changes maps column names to their new values for a partial update.
+type Changes map[string]any
+
+// Set records column = *value, unless value is nil: a field the caller did
+// not send is left as it is.
+func Set[V any](changes Changes, column string, value *V) {
+	if value != nil {
+		changes[column] = *value
+	}
+}
+
+// Patch sets only the columns in changes on the row with key id and returns
+// the row as stored afterwards. With no changes it is a Get. It returns
+// pgx.ErrNoRows if there is no such row and ErrUnknownColumn for a column the
+// table was not registered with.
+func (t *Table[T]) Patch(ctx context.Context, db Queryer, id any, changes Changes) (T, error) {
+	if len(changes) == 0 {
+		return t.Get(ctx, db, id)
+	}
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		if _, ok := t.byName[name]; !ok || name == t.key.Name {
+			var zero T
+			return zero, fmt.Errorf("%w %q in table %q", ErrUnknownColumn, name, t.name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sets := make([]string, len(names))
+	args := make([]any, 0, len(names)+1)
+	for i, name := range names {
+		sets[i] = fmt.Sprintf("%s = %s", pgx.Identifier{name}.Sanitize(), t.byName[name].valueExpr(fmt.Sprintf("$%d", i+1)))
+		args = append(args, changes[name])
+	}
+	args = append(args, id)
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d RETURNING %s",
+		t.quotedName(), strings.Join(sets, ", "), pgx.Identifier{t.key.Name}.Sanitize(), len(args), t.returning())
+	return t.scan(db.QueryRow(ctx, sql, args...))
+}
+
+// Delete removes the row with key id, or returns pgx.ErrNoRows if there is
+// none.
+func (t *Table[T]) Delete(ctx context.Context, db Queryer, id any) error {
+	key := pgx.Identifier{t.key.Name}.Sanitize()
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = $1 RETURNING %s", t.quotedName(), key, key)
+	var deleted any
+	return db.QueryRow(ctx, sql, id).Scan(&deleted)
+}
+
+// WithTx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise. The Table methods take the transaction in place of the pool.
+func WithTx(ctx context.Context, db *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 
 	"telemed/config"
 
@@ -27,7 +28,14 @@ func NewConnection() *pgxpool.Pool {
 		panic(err)
 	}
 
-	poolConfig.MaxConns = 5
+	timeouts := config.Timeouts()
+	poolConfig.MaxConns = timeouts.MaxConns
+	// Callers cancel their own queries through their context; these are the
+	// backstop for anything that slips past, so a runaway query or an
+	// abandoned transaction cannot hold a connection for good.
+	limit := strconv.FormatInt(timeouts.Statement.Milliseconds(), 10)
+	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = limit
+	poolConfig.ConnConfig.RuntimeParams["idle_in_transaction_session_timeout"] = limit
 	dbPool, err := pgxpool.ConnectConfig(ctx, poolConfig)
 
 	if err != nil {
@@ -37,58 +45,3 @@ func NewConnection() *pgxpool.Pool {
 	fmt.Println("Successfully connected to database!")
 	return dbPool
 }
-
-func Insert(db *pgxpool.Pool, table string, data map[string]any) error {
-	var columns, placeholders string
-	var values []any
-	i := 1
-	for k, v := range data {
-		if i > 1 {
-			columns += ","
-			placeholders += ","
-		}
-		columns += k
-		placeholders += fmt.Sprintf("$%d", i)
-		values = append(values, v)
-		i++
-	}
-	sql := fmt.Sprintf("INSERT INTO %v (%s) VALUES (%s)", table, columns, placeholders)
-	_, err := db.Exec(context.Background(), sql, values...)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func Update(db *pgxpool.Pool, table string, data map[string]any, condition map[string]any) error {
-	var setClause string
-	var values []any
-	i := 1
-	for k, v := range data {
-		if i > 1 {
-			setClause += ", "
-		}
-		setClause += fmt.Sprintf("%s = $%d", k, i)
-		values = append(values, v)
-		i++
-	}
-	whereClause := ""
-	for k, v := range condition {
-		if whereClause == "" {
-			whereClause = " WHERE "
-		} else {
-			whereClause += " AND "
-		}
-		whereClause += fmt.Sprintf("%s = $%d", k, i)
-		values = append(values, v)
-		i++
-	}
-	sql := fmt.Sprintf("UPDATE %s SET %s%s", table, setClause, whereClause)
-	_, err := db.Exec(context.Background(), sql, values...)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
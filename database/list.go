@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"telemed/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// ErrBadList wraps every error caused by the caller's list parameters, as
+// opposed to the database, so they can be shown to the client as they are.
+var ErrBadList = errors.New("invalid list query")
+
+// Queryer is satisfied by both a pool and a transaction.
+type Queryer interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// Sort is a field a list can be ordered by. Expr must never be NULL, and
+// Type is its SQL type, used to read cursor values back in.
+type Sort struct {
+	Expr string
+	Type string
+}
+
+type FilterKind int
+
+const (
+	Text FilterKind = iota
+	Int
+	Number
+	Bool
+	Time // RFC3339
+)
+
+// Filter is a query parameter a list can be narrowed by. Op is one of "=",
+// "<", "<=", ">", ">=", "ieq" (case-insensitive equality), "contains"
+// (case-insensitive substring) or "any" (Expr is an array holding the value).
+// If Values is set, only those values are accepted.
+type Filter struct {
+	Expr   string
+	Kind   FilterKind
+	Op     string
+	Values []string
+}
+
+// ListSpec describes one list endpoint: what it selects, which fields it can
+// be sorted by and which filters it takes. Key must be unique per row; it
+// breaks ties between rows with equal sort values so cursors never skip or
+// repeat a row.
+type ListSpec struct {
+	Select      string
+	From        string
+	Where       string
+	Key         string
+	Sorts       map[string]Sort
+	DefaultSort string
+	Filters     map[string]Filter
+}
+
+// List runs one page of spec under params. fields returns the scan targets
+// for the columns in spec.Select. The page's items are a []T, never nil, and
+// its meta carries the total number of matching rows and the cursor for the
+// next page, if there is one.
+func List[T any](ctx context.Context, db Queryer, spec ListSpec, params models.ListParams, fields func(*T) []any) (models.Page, error) {
+	items := []T{}
+	page := models.Page{Items: items}
+
+	limit := params.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 1 || limit > MaxLimit {
+		return page, fmt.Errorf("%w: limit must be between 1 and %d", ErrBadList, MaxLimit)
+	}
+
+	sortName := params.Sort
+	if sortName == "" {
+		sortName = spec.DefaultSort
+	}
+	desc := strings.HasPrefix(sortName, "-")
+	orderBy, ok := spec.Sorts[strings.TrimPrefix(sortName, "-")]
+	if !ok {
+		return page, fmt.Errorf("%w: cannot sort by %q", ErrBadList, strings.TrimPrefix(sortName, "-"))
+	}
+
+	var conds []string
+	var args []any
+	if spec.Where != "" {
+		conds = append(conds, "("+spec.Where+")")
+	}
+	names := make([]string, 0, len(params.Filters))
+	for name := range params.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		filter, ok := spec.Filters[name]
+		if !ok {
+			return page, fmt.Errorf("%w: cannot filter by %q", ErrBadList, name)
+		}
+		value, err := filter.parse(params.Filters[name])
+		if err != nil {
+			return page, fmt.Errorf("%w: invalid value for %q", ErrBadList, name)
+		}
+		args = append(args, value)
+		conds = append(conds, filter.condition(len(args)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM "+spec.From+where, args...).Scan(&page.Meta.Total); err != nil {
+		return page, err
+	}
+
+	if params.Cursor != "" {
+		after, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return page, fmt.Errorf("%w: bad cursor", ErrBadList)
+		}
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+		args = append(args, after[0], after[1])
+		conds = append(conds, fmt.Sprintf("(%s, (%s)::text) %s ($%d::text::%s, $%d::text)",
+			orderBy.Expr, spec.Key, cmp, len(args)-1, orderBy.Type, len(args)))
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	query := fmt.Sprintf("SELECT %s, (%s)::text, (%s)::text FROM %s%s ORDER BY %s %s, (%s)::text %s LIMIT %d",
+		spec.Select, orderBy.Expr, spec.Key, spec.From, where, orderBy.Expr, dir, spec.Key, dir, limit+1)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var last [2]string
+	for rows.Next() {
+		if len(items) == limit {
+			page.Meta.NextCursor = encodeCursor(last)
+			break
+		}
+		var item T
+		var sortValue, keyValue string
+		if err := rows.Scan(append(fields(&item), &sortValue, &keyValue)...); err != nil {
+			return page, err
+		}
+		items = append(items, item)
+		last = [2]string{sortValue, keyValue}
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	page.Items = items
+	page.Meta.Limit = limit
+	page.Meta.Sort = sortName
+	return page, nil
+}
+
+func (f Filter) parse(raw string) (any, error) {
+	if len(f.Values) > 0 {
+		allowed := false
+		for _, v := range f.Values {
+			if v == raw {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, errors.New("value not allowed")
+		}
+	}
+
+	switch f.Kind {
+	case Int:
+		return strconv.Atoi(raw)
+	case Number:
+		return strconv.ParseFloat(raw, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	case Time:
+		t, err := time.Parse(time.RFC3339, raw)
+		return t.UTC(), err
+	}
+	if f.Op == "contains" {
+		raw = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(raw)
+	}
+	return raw, nil
+}
+
+func (f Filter) condition(n int) string {
+	switch f.Op {
+	case "ieq":
+		return fmt.Sprintf("LOWER(%s) = LOWER($%d)", f.Expr, n)
+	case "contains":
+		return fmt.Sprintf("%s ILIKE '%%' || $%d || '%%'", f.Expr, n)
+	case "any":
+		return fmt.Sprintf("$%d = ANY(%s)", n, f.Expr)
+	default:
+		return fmt.Sprintf("%s %s $%d", f.Expr, f.Op, n)
+	}
+}
+
+func encodeCursor(values [2]string) string {
+	raw, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor string) ([2]string, error) {
+	var values [2]string
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return values, err
+	}
+	err = json.Unmarshal(raw, &values)
+	return values, err
+}
@@ -0,0 +1,367 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the advisory lock every instance takes before touching
+// the schema, so two instances starting together never both migrate.
+const migrationLockID = 7_243_551_020
+
+// Migration is one numbered schema change, read from a pair of files named
+// NNNN_name.up.sql and NNNN_name.down.sql. Checksum covers the up file only.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// LoadMigrations reads the embedded migrations in version order. Every
+// version must have both halves and versions must run 1, 2, 3... with no gaps.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		file := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), ".")
+		number, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || !found || err != nil || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql", file)
+		}
+		body, err := migrationFiles.ReadFile(path.Join("migrations", file))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d has two names, %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+			sum := sha256.Sum256(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// withMigrationLock runs fn on a single connection holding the migration
+// lock, after making sure the history table exists.
+func withMigrationLock(ctx context.Context, db *pgxpool.Pool, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	// Waiting for the lock and rebuilding big tables can both outlast the
+	// pool's statement timeout, so lift it on this connection until we are done.
+	if _, err := conn.Exec(ctx, "SET statement_timeout = 0"); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "RESET statement_timeout"); err != nil {
+			log.Println("Failed to reset statement timeout:", err)
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			log.Println("Failed to release migration lock:", err)
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// appliedMigrations returns the recorded history, after checking it still
+// matches the embedded files: an applied migration that has been edited or
+// removed since is an error, not something to paper over.
+func appliedMigrations(ctx context.Context, conn *pgxpool.Conn, migrations []Migration) (map[int]appliedMigration, error) {
+	rows, err := conn.Query(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for version, a := range applied {
+		if version < 1 || version > len(migrations) {
+			return nil, fmt.Errorf("migration %d has been applied but is not in this build", version)
+		}
+		if m := migrations[version-1]; a.Checksum != m.Checksum {
+			return nil, fmt.Errorf("migration %04d_%s has changed since it was applied", m.Version, m.Name)
+		}
+	}
+	return applied, nil
+}
+
+// Migrate applies every pending migration in order, each in its own
+// transaction.
+func Migrate(ctx context.Context, db *pgxpool.Pool) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	return withMigrationLock(ctx, db, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn, migrations)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			var existing bool
+			if err := conn.QueryRow(ctx, "SELECT to_regclass('users') IS NOT NULL").Scan(&existing); err != nil {
+				return err
+			}
+			if existing {
+				return errors.New("database already has a schema but no migration history; run `migrate baseline 1` once to adopt it")
+			}
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			tx, err := conn.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, m.Up); err != nil {
+				tx.Rollback(ctx)
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+				m.Version, m.Name, m.Checksum); err != nil {
+				tx.Rollback(ctx)
+				return err
+			}
+			if err := tx.Commit(ctx); err != nil {
+				return err
+			}
+			log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+		}
+		return nil
+	})
+}
+
+// Rollback reverts the latest steps applied migrations, newest first.
+func Rollback(ctx context.Context, db *pgxpool.Pool, steps int) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	return withMigrationLock(ctx, db, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn, migrations)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			tx, err := conn.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, m.Down); err != nil {
+				tx.Rollback(ctx)
+				return fmt.Errorf("rolling back migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version); err != nil {
+				tx.Rollback(ctx)
+				return err
+			}
+			if err := tx.Commit(ctx); err != nil {
+				return err
+			}
+			log.Printf("Rolled back migration %04d_%s", m.Version, m.Name)
+			steps--
+		}
+		return nil
+	})
+}
+
+// Baseline records migrations up to and including version as applied
+// without running them, for databases that were built by hand before the
+// migration history existed.
+func Baseline(ctx context.Context, db *pgxpool.Pool, version int) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	if version < 1 || version > len(migrations) {
+		return fmt.Errorf("no migration %d", version)
+	}
+	return withMigrationLock(ctx, db, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn, migrations)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return errors.New("database already has a migration history")
+		}
+		for _, m := range migrations[:version] {
+			if _, err := conn.Exec(ctx, "INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+				m.Version, m.Name, m.Checksum); err != nil {
+				return err
+			}
+		}
+		log.Printf("Marked migrations up to %04d as applied", version)
+		return nil
+	})
+}
+
+func MigrationStatuses(ctx context.Context, db *pgxpool.Pool) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var statuses []MigrationStatus
+	err = withMigrationLock(ctx, db, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn, migrations)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			status := MigrationStatus{Migration: m}
+			if a, ok := applied[m.Version]; ok {
+				at := a.AppliedAt
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// RunMigrateCommand implements the `migrate` subcommand:
+//
+//	migrate up              apply every pending migration
+//	migrate down [steps]    roll back the latest steps migrations (default 1)
+//	migrate status          list migrations and when each was applied
+//	migrate baseline <n>    adopt an existing database at migration n
+func RunMigrateCommand(ctx context.Context, db *pgxpool.Pool, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [steps] | status | baseline <version>")
+	}
+	number := func(fallback int) (int, error) {
+		if len(args) < 2 {
+			return fallback, nil
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid number %q", args[1])
+		}
+		return n, nil
+	}
+
+	switch args[0] {
+	case "up":
+		return Migrate(ctx, db)
+	case "down":
+		steps, err := number(1)
+		if err != nil {
+			return err
+		}
+		return Rollback(ctx, db, steps)
+	case "baseline":
+		version, err := number(0)
+		if err != nil {
+			return err
+		}
+		return Baseline(ctx, db, version)
+	case "status":
+		statuses, err := MigrationStatuses(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
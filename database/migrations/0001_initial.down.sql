@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS
+    appointments,
+    admins,
+    reviews,
+    prescriptions,
+    test_centres,
+    orders,
+    pharmacies,
+    inventory,
+    doctors,
+    hospitals,
+    users
+    CASCADE;
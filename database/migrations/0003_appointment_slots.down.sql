@@ -0,0 +1 @@
+DROP INDEX appointments_doctor_slot_idx;
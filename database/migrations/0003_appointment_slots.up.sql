@@ -0,0 +1,2 @@
+-- One live appointment per doctor slot.
+CREATE UNIQUE INDEX appointments_doctor_slot_idx ON appointments (doctor_tag, scheduled_at) WHERE status IN ('pending', 'confirmed');
@@ -0,0 +1,3 @@
+ALTER TABLE doctors DROP COLUMN otp_expiry;
+ALTER TABLE doctors DROP COLUMN otp;
+ALTER TABLE doctors DROP COLUMN email;
@@ -0,0 +1,3 @@
+ALTER TABLE doctors ADD COLUMN email VARCHAR(255) UNIQUE;
+ALTER TABLE doctors ADD COLUMN otp VARCHAR(10);
+ALTER TABLE doctors ADD COLUMN otp_expiry TIMESTAMP;
@@ -0,0 +1,5 @@
+ALTER TABLE doctors DROP COLUMN submitted_at;
+ALTER TABLE doctors DROP COLUMN review_note;
+ALTER TABLE doctors DROP COLUMN documents;
+ALTER TABLE doctors DROP COLUMN license_number;
+ALTER TABLE doctors DROP COLUMN status;
@@ -0,0 +1,6 @@
+-- Doctors that already exist were added by an admin, so they start approved.
+ALTER TABLE doctors ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved' CHECK (status IN ('pending', 'approved', 'rejected', 'info_requested'));
+ALTER TABLE doctors ADD COLUMN license_number VARCHAR(100);
+ALTER TABLE doctors ADD COLUMN documents JSONB; -- e.g. ["https://.../license.pdf"]
+ALTER TABLE doctors ADD COLUMN review_note TEXT;
+ALTER TABLE doctors ADD COLUMN submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
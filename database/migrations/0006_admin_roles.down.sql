@@ -0,0 +1,3 @@
+ALTER TABLE admins DROP CONSTRAINT admins_role_check;
+ALTER TABLE admins ALTER COLUMN role DROP NOT NULL;
+ALTER TABLE admins ALTER COLUMN role DROP DEFAULT;
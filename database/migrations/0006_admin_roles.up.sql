@@ -0,0 +1,4 @@
+UPDATE admins SET role = 'admin' WHERE role IS NULL;
+ALTER TABLE admins ALTER COLUMN role SET DEFAULT 'admin';
+ALTER TABLE admins ALTER COLUMN role SET NOT NULL;
+ALTER TABLE admins ADD CONSTRAINT admins_role_check CHECK (role IN ('admin', 'god_eye', 'pharmacist', 'lab_staff'));
@@ -0,0 +1,7 @@
+DROP TABLE admin_account_changes;
+
+ALTER TABLE admins DROP COLUMN created_at;
+ALTER TABLE admins DROP COLUMN password_reset_required;
+ALTER TABLE admins DROP COLUMN invite_expiry;
+ALTER TABLE admins DROP COLUMN invite_token_hash;
+ALTER TABLE admins DROP COLUMN status;
@@ -0,0 +1,14 @@
+ALTER TABLE admins ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('invited', 'active', 'suspended'));
+ALTER TABLE admins ADD COLUMN invite_token_hash TEXT;
+ALTER TABLE admins ADD COLUMN invite_expiry TIMESTAMP;
+ALTER TABLE admins ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE admins ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE admin_account_changes (
+    id SERIAL PRIMARY KEY,
+    actor_admintag VARCHAR(50),
+    target_admintag VARCHAR(50),
+    action VARCHAR(50) NOT NULL,
+    details JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,14 @@
+CREATE TABLE sessions (
+    session_id VARCHAR(64) PRIMARY KEY,
+    subject VARCHAR(50) NOT NULL,
+    account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('admin', 'doctor', 'patient')),
+    role VARCHAR(50) NOT NULL,
+    refresh_token_hash TEXT NOT NULL,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+CREATE INDEX sessions_subject_idx ON sessions (account_type, subject);
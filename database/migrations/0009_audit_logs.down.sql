@@ -0,0 +1,13 @@
+CREATE TABLE admin_account_changes (
+    id SERIAL PRIMARY KEY,
+    actor_admintag VARCHAR(50),
+    target_admintag VARCHAR(50),
+    action VARCHAR(50) NOT NULL,
+    details JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO admin_account_changes (actor_admintag, target_admintag, action, details, created_at)
+    SELECT actor_admintag, entity_id, action, after, created_at FROM audit_logs WHERE entity_type = 'admin';
+
+DROP TABLE audit_logs;
+DROP FUNCTION audit_logs_append_only();
@@ -0,0 +1,29 @@
+CREATE TABLE audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_admintag VARCHAR(50),
+    actor_role VARCHAR(50),
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100),
+    before JSONB,
+    after JSONB,
+    ip VARCHAR(64),
+    request_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX audit_logs_actor_idx ON audit_logs (actor_admintag, created_at);
+CREATE INDEX audit_logs_entity_idx ON audit_logs (entity_type, entity_id, created_at);
+
+CREATE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+-- Admin account changes are now part of the audit log.
+INSERT INTO audit_logs (actor_admintag, action, entity_type, entity_id, after, created_at)
+    SELECT actor_admintag, action, 'admin', target_admintag, details, created_at FROM admin_account_changes;
+DROP TABLE admin_account_changes;
@@ -0,0 +1,6 @@
+ALTER TABLE doctors DROP COLUMN otp_attempts;
+ALTER TABLE users DROP COLUMN otp_attempts;
+ALTER TABLE admins DROP COLUMN otp_sent_at;
+ALTER TABLE admins DROP COLUMN otp_attempts;
+
+DROP TABLE auth_lockouts;
@@ -0,0 +1,13 @@
+CREATE TABLE auth_lockouts (
+    scope VARCHAR(20) NOT NULL,
+    key VARCHAR(100) NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    last_failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
+
+ALTER TABLE admins ADD COLUMN otp_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE admins ADD COLUMN otp_sent_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN otp_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE doctors ADD COLUMN otp_attempts INT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP TABLE admin_password_history;
+
+ALTER TABLE admins DROP COLUMN reset_token_expiry;
+ALTER TABLE admins DROP COLUMN reset_token_hash;
@@ -0,0 +1,10 @@
+ALTER TABLE admins ADD COLUMN reset_token_hash VARCHAR(64);
+ALTER TABLE admins ADD COLUMN reset_token_expiry TIMESTAMP;
+
+CREATE TABLE admin_password_history (
+    id BIGSERIAL PRIMARY KEY,
+    admintag VARCHAR(50) NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX admin_password_history_admintag_idx ON admin_password_history (admintag, created_at);
@@ -0,0 +1,7 @@
+DROP TABLE security_settings;
+DROP TABLE admin_recovery_codes;
+
+ALTER TABLE admins DROP COLUMN totp_last_counter;
+ALTER TABLE admins DROP COLUMN totp_pending_secret;
+ALTER TABLE admins DROP COLUMN totp_secret;
+ALTER TABLE admins DROP COLUMN mfa_method;
@@ -0,0 +1,20 @@
+ALTER TABLE admins ADD COLUMN mfa_method VARCHAR(10) NOT NULL DEFAULT 'email' CHECK (mfa_method IN ('email', 'totp'));
+ALTER TABLE admins ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE admins ADD COLUMN totp_pending_secret VARCHAR(64);
+ALTER TABLE admins ADD COLUMN totp_last_counter BIGINT;
+
+CREATE TABLE admin_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    admintag VARCHAR(50) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX admin_recovery_codes_admintag_idx ON admin_recovery_codes (admintag);
+
+CREATE TABLE security_settings (
+    id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    require_totp_for_god_eye BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO security_settings (id) VALUES (1);
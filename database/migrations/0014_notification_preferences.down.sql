@@ -0,0 +1,2 @@
+DROP TABLE push_devices;
+DROP TABLE notification_preferences;
@@ -0,0 +1,19 @@
+CREATE TABLE notification_preferences (
+    account_type VARCHAR(20) NOT NULL,
+    subject VARCHAR(50) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('email', 'sms', 'push')),
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_type, subject, event, channel)
+);
+
+CREATE TABLE push_devices (
+    token VARCHAR(255) PRIMARY KEY,
+    account_type VARCHAR(20) NOT NULL,
+    subject VARCHAR(50) NOT NULL,
+    platform VARCHAR(20),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX push_devices_subject_idx ON push_devices (account_type, subject);
@@ -0,0 +1,8 @@
+CREATE TABLE appointment_reminders (
+    appointment_id INT NOT NULL REFERENCES appointments(appointment_id) ON DELETE CASCADE,
+    recipient VARCHAR(20) NOT NULL CHECK (recipient IN ('patient', 'doctor')),
+    offset_minutes INT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('sent', 'failed')),
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (appointment_id, recipient, offset_minutes)
+);
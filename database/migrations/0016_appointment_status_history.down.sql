@@ -0,0 +1,5 @@
+DROP TABLE appointment_status_history;
+
+-- No-shows keep their status, so the old check only applies to new rows.
+ALTER TABLE appointments DROP CONSTRAINT appointments_status_check;
+ALTER TABLE appointments ADD CONSTRAINT appointments_status_check CHECK (status IN ('pending', 'confirmed', 'completed', 'cancelled')) NOT VALID;
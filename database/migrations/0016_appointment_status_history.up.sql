@@ -0,0 +1,14 @@
+ALTER TABLE appointments DROP CONSTRAINT appointments_status_check;
+ALTER TABLE appointments ADD CONSTRAINT appointments_status_check CHECK (status IN ('pending', 'confirmed', 'completed', 'cancelled', 'no_show'));
+
+CREATE TABLE appointment_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    appointment_id INT NOT NULL REFERENCES appointments(appointment_id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    changed_by_type VARCHAR(20) NOT NULL CHECK (changed_by_type IN ('admin', 'doctor', 'patient')),
+    changed_by VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX appointment_status_history_appointment_idx ON appointment_status_history (appointment_id, created_at);
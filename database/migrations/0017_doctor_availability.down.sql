@@ -0,0 +1,19 @@
+ALTER TABLE appointments DROP COLUMN duration_minutes;
+
+ALTER TABLE doctors ADD COLUMN availability JSONB;
+
+-- Each override's start becomes one of the old one-off slots.
+UPDATE doctors d SET availability = o.slots
+FROM (
+    SELECT doctortag, jsonb_agg(to_char(date + start_time, 'YYYY-MM-DD"T"HH24:MI:SS') ORDER BY date, start_time) AS slots
+    FROM doctor_date_overrides GROUP BY doctortag
+) o
+WHERE o.doctortag = d.doctortag;
+
+DROP TABLE doctor_blackouts;
+DROP TABLE doctor_date_overrides;
+DROP TABLE doctor_weekly_hours;
+
+ALTER TABLE doctors DROP COLUMN buffer_minutes;
+ALTER TABLE doctors DROP COLUMN slot_minutes;
+ALTER TABLE doctors DROP COLUMN timezone;
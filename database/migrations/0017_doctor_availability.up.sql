@@ -0,0 +1,54 @@
+ALTER TABLE doctors ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE doctors ADD COLUMN slot_minutes INT NOT NULL DEFAULT 30 CHECK (slot_minutes BETWEEN 5 AND 480);
+ALTER TABLE doctors ADD COLUMN buffer_minutes INT NOT NULL DEFAULT 0 CHECK (buffer_minutes BETWEEN 0 AND 240);
+
+CREATE TABLE doctor_weekly_hours (
+    id BIGSERIAL PRIMARY KEY,
+    doctortag VARCHAR(50) NOT NULL REFERENCES doctors(doctortag) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 is Sunday
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    CHECK (end_time > start_time)
+);
+CREATE INDEX doctor_weekly_hours_doctor_idx ON doctor_weekly_hours (doctortag);
+
+CREATE TABLE doctor_date_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    doctortag VARCHAR(50) NOT NULL REFERENCES doctors(doctortag) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    CHECK (end_time > start_time)
+);
+CREATE INDEX doctor_date_overrides_doctor_idx ON doctor_date_overrides (doctortag, date);
+
+CREATE TABLE doctor_blackouts (
+    doctortag VARCHAR(50) NOT NULL REFERENCES doctors(doctortag) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    reason TEXT,
+    PRIMARY KEY (doctortag, date)
+);
+
+-- Carry the old one-off slots over as single-slot overrides on their dates.
+-- Doctors had no regular hours before, so they start with no weekly hours
+-- and add them through their availability endpoint. Entries that are not
+-- timestamps were never bookable and are dropped rather than failing the
+-- migration.
+INSERT INTO doctor_date_overrides (doctortag, date, start_time, end_time)
+SELECT d.doctortag, s.at::date, s.at::time, s.at::time + make_interval(mins => d.slot_minutes)
+FROM doctors d,
+     jsonb_array_elements_text(CASE WHEN jsonb_typeof(d.availability) = 'array' THEN d.availability ELSE '[]'::jsonb END) AS e(slot),
+     LATERAL (
+         SELECT CASE WHEN e.slot ~ '^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}'
+                     THEN e.slot::timestamptz AT TIME ZONE 'UTC' END AS at
+     ) s
+WHERE s.at IS NOT NULL
+AND s.at::time + make_interval(mins => d.slot_minutes) > s.at::time;
+
+ALTER TABLE doctors DROP COLUMN availability;
+
+-- Appointments keep the length of the slot they were booked into, so changing
+-- a doctor's slot length leaves existing bookings as they are. The old slots
+-- were 30 minutes.
+ALTER TABLE appointments ADD COLUMN duration_minutes INT NOT NULL DEFAULT 30 CHECK (duration_minutes > 0);
+ALTER TABLE appointments ALTER COLUMN duration_minutes DROP DEFAULT;
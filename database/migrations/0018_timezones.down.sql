@@ -0,0 +1,8 @@
+ALTER TABLE test_centres DROP COLUMN timezone;
+ALTER TABLE admins DROP COLUMN timezone;
+ALTER TABLE users DROP COLUMN timezone;
+
+ALTER TABLE appointment_status_history ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+ALTER TABLE appointment_reminders ALTER COLUMN sent_at TYPE TIMESTAMP USING sent_at AT TIME ZONE 'UTC';
+ALTER TABLE appointments ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+ALTER TABLE appointments ALTER COLUMN scheduled_at TYPE TIMESTAMP USING scheduled_at AT TIME ZONE 'UTC';
@@ -0,0 +1,7 @@
+-- pg_trgm is left installed; other schemas may rely on it.
+DROP INDEX users_search_fts, users_search_trgm,
+    doctors_search_fts, doctors_search_trgm,
+    inventory_search_fts, inventory_search_trgm,
+    hospitals_search_fts, hospitals_search_trgm,
+    pharmacies_search_fts, pharmacies_search_trgm,
+    test_centres_search_fts, test_centres_search_trgm;
@@ -0,0 +1,31 @@
+-- Each index expression must match the document built for its group in
+-- servers/search-servers.go.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX users_search_fts ON users USING GIN (to_tsvector('simple',
+    COALESCE(firstname, '') || ' ' || COALESCE(lastname, '') || ' ' || COALESCE(email, '') || ' ' || COALESCE(phone_no, '')));
+CREATE INDEX users_search_trgm ON users USING GIN (
+    (COALESCE(firstname, '') || ' ' || COALESCE(lastname, '') || ' ' || COALESCE(email, '') || ' ' || COALESCE(phone_no, '')) gin_trgm_ops);
+
+CREATE INDEX doctors_search_fts ON doctors USING GIN (to_tsvector('simple',
+    COALESCE(fullname, '') || ' ' || COALESCE(specialization, '') || ' ' || COALESCE(city, '')));
+CREATE INDEX doctors_search_trgm ON doctors USING GIN (
+    (COALESCE(fullname, '') || ' ' || COALESCE(specialization, '') || ' ' || COALESCE(city, '')) gin_trgm_ops);
+
+CREATE INDEX inventory_search_fts ON inventory USING GIN (to_tsvector('simple', COALESCE(name, '')));
+CREATE INDEX inventory_search_trgm ON inventory USING GIN ((COALESCE(name, '')) gin_trgm_ops);
+
+CREATE INDEX hospitals_search_fts ON hospitals USING GIN (to_tsvector('simple',
+    COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')));
+CREATE INDEX hospitals_search_trgm ON hospitals USING GIN (
+    (COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')) gin_trgm_ops);
+
+CREATE INDEX pharmacies_search_fts ON pharmacies USING GIN (to_tsvector('simple',
+    COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')));
+CREATE INDEX pharmacies_search_trgm ON pharmacies USING GIN (
+    (COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')) gin_trgm_ops);
+
+CREATE INDEX test_centres_search_fts ON test_centres USING GIN (to_tsvector('simple',
+    COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')));
+CREATE INDEX test_centres_search_trgm ON test_centres USING GIN (
+    (COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')) gin_trgm_ops);
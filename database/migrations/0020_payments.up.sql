@@ -0,0 +1,14 @@
+-- Payment analytics has always read from this table, but it was never part
+-- of the schema.
+CREATE TABLE payments (
+    payment_id BIGSERIAL PRIMARY KEY,
+    usertag VARCHAR(50) REFERENCES users(usertag) ON DELETE SET NULL,
+    appointment_id INT REFERENCES appointments(appointment_id) ON DELETE SET NULL,
+    order_id INT REFERENCES orders(order_id) ON DELETE SET NULL,
+    reference VARCHAR(100) UNIQUE,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'NGN',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed', 'refunded')),
+    payment_date TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX payments_date_idx ON payments (payment_date) WHERE status = 'completed';
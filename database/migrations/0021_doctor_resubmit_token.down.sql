@@ -0,0 +1,2 @@
+ALTER TABLE doctors DROP COLUMN resubmit_token_expiry;
+ALTER TABLE doctors DROP COLUMN resubmit_token_hash;
@@ -0,0 +1,5 @@
+-- An application sent back for more information can only be resubmitted with
+-- the single-use code emailed to the applicant, so nobody else who knows
+-- their email can overwrite it.
+ALTER TABLE doctors ADD COLUMN resubmit_token_hash TEXT;
+ALTER TABLE doctors ADD COLUMN resubmit_token_expiry TIMESTAMPTZ;
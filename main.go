@@ -3,22 +3,77 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"telemed/config"
+	"telemed/controllers"
 	"telemed/database"
+	"telemed/middleware"
+	"telemed/notifications"
+	"telemed/repository"
+	"telemed/responses"
 	"telemed/routes"
 	"telemed/servers"
+	_ "time/tzdata" // doctor timezones must resolve even where the host has no zoneinfo
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 func main() {
-	servers.Ctx = context.Background()
-	servers.Db = database.NewConnection()
+	ctx := context.Background()
+	db := database.NewConnection()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.RunMigrateCommand(ctx, db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if config.MigrateOnStart {
+		if err := database.Migrate(ctx, db); err != nil {
+			log.Fatal(err)
+		}
+	}
+	email, err := notifications.FromConfig(config.Mail())
+	if err != nil {
+		log.Fatal(err)
+	}
+	sms, err := notifications.SMSFromConfig(config.SMS())
+	if err != nil {
+		log.Fatal(err)
+	}
+	push, err := notifications.PushFromConfig(config.Push())
+	if err != nil {
+		log.Fatal(err)
+	}
+	notifications.Start(map[notifications.Channel]notifications.Notifier{
+		notifications.ChannelEmail: email,
+		notifications.ChannelSMS:   sms,
+		notifications.ChannelPush:  push,
+	}, 2)
+	repos := repository.NewPgx(db)
+	servers.StartReminderScheduler(ctx, repos, config.Reminders())
+	sessions := servers.SessionServer{Repos: repos}
+	controllers.SetAdminServer(servers.NewAdminServer(repos))
+	controllers.SetDoctorServer(servers.DoctorServer{Repos: repos})
+	controllers.SetPatientServer(servers.PatientServer{Repos: repos})
+	controllers.SetSessionServer(sessions)
+	controllers.SetNotificationServer(servers.NotificationServer{Repos: repos})
+	middleware.SetSessionCheck(sessions.Active)
+	proxies := config.Proxies()
+	if err := middleware.SetTrustedProxies(proxies); err != nil {
+		log.Fatal(err)
+	}
 	app := fiber.New(fiber.Config{
-		AppName: "Telemed Backend",
+		AppName:                 "Telemed Backend",
+		ErrorHandler:            middleware.ErrorHandler,
+		ProxyHeader:             proxies.Header,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          proxies.Trusted,
 	})
+	app.Use(requestid.New())
 	app.Use(logger.New())
+	app.Use(middleware.Timeout(config.Timeouts().Request))
 	app.Get("/admin/healthchecker", func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"success": true,
@@ -29,20 +84,14 @@ func main() {
 		auth := c.Get("G-auth")
 		if auth == "" || auth != config.GatewaySecret {
 			log.Println("Invalid password for authentication")
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"success": false,
-				"message": "access denied, invalid route authentication",
-			})
+			return responses.ErrorResponse(c, "access denied, invalid route authentication", fiber.StatusForbidden)
 		}
 		return c.Next()
 	})
 	routes.AdminRoutes(app)
 	routes.Routes(app)
 	app.All("*", func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Route not found",
-		})
+		return responses.ErrorResponse(c, "Route not found", fiber.StatusNotFound)
 	})
 
 	log.Fatal(app.Listen(":8080"))
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"telemed/apperrors"
+	"telemed/responses"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorHandler answers every error a handler returns. Fiber's own errors,
+// such as an unknown method or an oversized body, keep their status; the
+// rest get the status of their apperrors kind. Internal errors are logged
+// with their cause and reach the client only as SOMETHING_WRONG.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return responses.ErrorResponse(c, fiberErr.Message, fiberErr.Code)
+	}
+
+	appErr := apperrors.From(err)
+	if appErr.Kind == apperrors.KindInternal {
+		log.Printf("Internal error on %s %s: %v", c.Method(), c.Path(), appErr.Err)
+	}
+	return responses.ErrorResponse(c, appErr.Message, appErr.Kind.Status())
+}
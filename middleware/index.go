@@ -1,48 +1,59 @@
 package middleware
 
 import (
+	"context"
 	"log"
 	"strings"
 	"telemed/config"
+	"telemed/responses"
+	"telemed/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+var sessionActive func(ctx context.Context, sessionID string) bool
+
+// SetSessionCheck sets how JWTProtected asks whether the session behind a
+// token is still live. main sets it once, after connecting to the database.
+func SetSessionCheck(active func(ctx context.Context, sessionID string) bool) {
+	sessionActive = active
+}
+
 func JWTProtected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Missing or invalid Authorization header",
-			})
+			return responses.ErrorResponse(c, "Missing or invalid Authorization header", fiber.StatusUnauthorized)
 		}
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 		secret := config.JwtSecret
 		if secret == "" {
 			log.Println("No JWT secret key found in config")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Something went wrong, please try again later",
-			})
+			return responses.ErrorResponse(c, "Something went wrong, please try again later", fiber.StatusInternalServerError)
 		}
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			return []byte(secret), nil
-		})
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid or expired token",
-			})
+			return responses.ErrorResponse(c, "Invalid or expired token", fiber.StatusUnauthorized)
 		}
 
 		// set claims in context for handlers to use
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Locals("usertag", claims["usertag"])
-			c.Locals("role", claims["role"])
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			return responses.ErrorResponse(c, "Invalid or expired token", fiber.StatusUnauthorized)
+		}
+		usertag, _ := claims["usertag"].(string)
+		role, _ := claims["role"].(string)
+		sessionID, _ := claims["sid"].(string)
+		if usertag == "" || !utils.IsValidRole(role) || !sessionActive(c.UserContext(), sessionID) {
+			return responses.ErrorResponse(c, "Invalid or expired token", fiber.StatusUnauthorized)
 		}
+		c.Locals("usertag", usertag)
+		c.Locals("role", role)
+		c.Locals("sid", sessionID)
 
 		return c.Next()
 	}
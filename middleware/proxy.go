@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"telemed/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	proxyHeader    string
+	trustedProxies []*net.IPNet
+)
+
+// SetTrustedProxies sets which peers ClientIP believes about the client's
+// address. main sets it once, from the same config as the app's
+// TrustedProxies.
+func SetTrustedProxies(cfg config.ProxyConfig) error {
+	nets := make([]*net.IPNet, 0, len(cfg.Trusted))
+	for _, proxy := range cfg.Trusted {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("trusted proxy %q: %w", proxy, err)
+		}
+		nets = append(nets, n)
+	}
+	proxyHeader, trustedProxies = cfg.Header, nets
+	return nil
+}
+
+func trustedProxy(ip net.IP) bool {
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP is the address a request came from, for lockouts and the audit
+// log. A request from a trusted proxy is traced back through the proxy
+// header from its right-hand end, where our own proxies append, to the first
+// address that is not one of them. Anything left of that was sent by the
+// client and could be forged.
+func ClientIP(c *fiber.Ctx) string {
+	ip := c.Context().RemoteIP()
+	if proxyHeader == "" || !trustedProxy(ip) {
+		return ip.String()
+	}
+	hops := strings.Split(c.Get(proxyHeader), ",")
+	for i := len(hops) - 1; i >= 0 && trustedProxy(ip); i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+	}
+	return ip.String()
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"telemed/config"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestClientIP(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(ClientIP(c)) })
+	// app.Test requests arrive from 0.0.0.0.
+	tests := []struct {
+		name      string
+		trusted   []string
+		forwarded string
+		want      string
+	}{
+		{"no trusted proxies ignores the header", nil, "203.0.113.7", "0.0.0.0"},
+		{"no header", []string{"0.0.0.0"}, "", "0.0.0.0"},
+		{"one proxy", []string{"0.0.0.0"}, "203.0.113.7", "203.0.113.7"},
+		{"forged hop left of the client", []string{"0.0.0.0"}, "198.51.100.1, 203.0.113.7", "203.0.113.7"},
+		{"chain of trusted proxies", []string{"0.0.0.0", "10.0.0.0/8"}, "198.51.100.1, 203.0.113.7, 10.1.2.3", "203.0.113.7"},
+		{"garbage stops the walk", []string{"0.0.0.0", "10.0.0.0/8"}, "203.0.113.7, nonsense, 10.1.2.3", "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetTrustedProxies(config.ProxyConfig{Header: "X-Forwarded-For", Trusted: tt.trusted}); err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("got %q, want %q", body, tt.want)
+			}
+		})
+	}
+	if err := SetTrustedProxies(config.ProxyConfig{Trusted: []string{"not-an-ip"}}); err == nil {
+		t.Error("a malformed trusted proxy was accepted")
+	}
+}
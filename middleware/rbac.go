@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"telemed/responses"
+	"telemed/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Permission string
+
+const (
+	ViewDashboard         Permission = "dashboard:view"
+	ManageAppointments    Permission = "appointments:manage"
+	ViewDoctors           Permission = "doctors:view"
+	ReviewDoctors         Permission = "doctors:review"
+	DeleteDoctors         Permission = "doctors:delete"
+	ViewPatients          Permission = "patients:view"
+	EditPatients          Permission = "patients:edit"
+	DeletePatients        Permission = "patients:delete"
+	ViewPharmacies        Permission = "pharmacies:view"
+	ManagePharmacies      Permission = "pharmacies:manage"
+	ManageHospitals       Permission = "hospitals:manage"
+	ViewInventory         Permission = "inventory:view"
+	ManageInventory       Permission = "inventory:manage"
+	ManageOrders          Permission = "orders:manage"
+	ViewTestCentres       Permission = "test_centres:view"
+	ManageTestCentres     Permission = "test_centres:manage"
+	ManageReviews         Permission = "reviews:manage"
+	ManageOwnStaffProfile Permission = "staff_profile:manage"
+	ManageAdmins          Permission = "admins:manage"
+	ViewAuditLogs         Permission = "audit_logs:view"
+	Search                Permission = "search:use"
+	PatientSelfService    Permission = "patient:self"
+	DoctorSelfService     Permission = "doctor:self"
+)
+
+// permissionMatrix is the single source of truth for which roles may do what.
+// Anything not listed here is denied.
+var permissionMatrix = map[Permission][]string{
+	ViewDashboard:         {utils.RoleAdmin, utils.RoleGodEye},
+	ManageAppointments:    {utils.RoleAdmin, utils.RoleGodEye},
+	ViewDoctors:           {utils.RoleAdmin, utils.RoleGodEye},
+	ReviewDoctors:         {utils.RoleAdmin, utils.RoleGodEye},
+	DeleteDoctors:         {utils.RoleGodEye},
+	ViewPatients:          {utils.RoleAdmin, utils.RoleGodEye},
+	EditPatients:          {utils.RoleAdmin, utils.RoleGodEye},
+	DeletePatients:        {utils.RoleGodEye},
+	ViewPharmacies:        {utils.RoleAdmin, utils.RoleGodEye, utils.RolePharmacist},
+	ManagePharmacies:      {utils.RoleAdmin, utils.RoleGodEye},
+	ManageHospitals:       {utils.RoleAdmin, utils.RoleGodEye},
+	ViewInventory:         {utils.RoleAdmin, utils.RoleGodEye, utils.RolePharmacist},
+	ManageInventory:       {utils.RoleAdmin, utils.RoleGodEye, utils.RolePharmacist},
+	ManageOrders:          {utils.RoleAdmin, utils.RoleGodEye, utils.RolePharmacist},
+	ViewTestCentres:       {utils.RoleAdmin, utils.RoleGodEye, utils.RoleLabStaff},
+	ManageTestCentres:     {utils.RoleAdmin, utils.RoleGodEye, utils.RoleLabStaff},
+	ManageReviews:         {utils.RoleAdmin, utils.RoleGodEye},
+	ManageOwnStaffProfile: utils.StaffRoles,
+	ManageAdmins:          {utils.RoleGodEye},
+	ViewAuditLogs:         {utils.RoleGodEye},
+	Search:                utils.StaffRoles,
+	PatientSelfService:    {utils.RolePatient},
+	DoctorSelfService:     {utils.RoleDoctor},
+}
+
+func HasPermission(role string, permission Permission) bool {
+	for _, r := range permissionMatrix[permission] {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize checks the role claim that JWTProtected stored on the request
+// against the permission matrix. It must be mounted after JWTProtected so the
+// role it reads has come from a verified token.
+func Authorize(permission Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		if !HasPermission(role, permission) {
+			return responses.ErrorResponse(c, "Unauthorized access", fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"telemed/responses"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Timeout gives the rest of the chain a request context that ends after d,
+// or sooner if the server shuts down. Handlers pass it on with
+// c.UserContext() so their queries are cancelled with it. Each Timeout
+// starts again from the request rather than nesting, so a route can set a
+// longer or shorter deadline than the app-wide one.
+func Timeout(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.Context(), d)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		// A later Timeout may have replaced ctx, so ask whichever one the
+		// handler actually ran under.
+		if errors.Is(c.UserContext().Err(), context.DeadlineExceeded) {
+			return responses.ErrorResponse(c, responses.REQUEST_TIMEOUT, fiber.StatusServiceUnavailable)
+		}
+		return err
+	}
+}
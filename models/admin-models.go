@@ -7,37 +7,53 @@ import (
 )
 
 type Adminlogin struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required"`
+	MFAMethod string `json:"mfa_method" validate:"oneof=email totp"`
+	ClientInfo
 }
 
 type AdminLoginResponse struct {
-	Usertag string `json:"usertag"`
+	Usertag   string `json:"usertag"`
+	MFAMethod string `json:"mfa_method"`
 }
 
 type OTPVerify struct {
-	OTP     string `json:"otp"`
+	OTP     string `json:"otp" validate:"required"`
 	Usertag string `json:"usertag"`
+	// Method is "email" (the default), "totp" or "recovery".
+	Method string `json:"method" validate:"oneof=email totp recovery"`
+	ClientInfo
 }
 
 type ForgotPassword struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 }
 
 type VerifyPwdOTP struct {
-	OTP   string `json:"otp"`
-	Email string `json:"email"`
+	OTP   string `json:"otp" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+	ClientInfo
 }
 
 type ResetPassword struct {
-	Email       string `json:"email"`
-	NewPassword string `json:"new_password"`
+	Email       string `json:"email" validate:"required,email"`
+	ResetToken  string `json:"reset_token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
 }
 
 type AnalyticsReq struct {
-	Metric string `json:"metric"`
-	Month  string `json:"month"`
-	Year   string `json:"year"`
+	Metric string `json:"metric" validate:"required"`
+	Month  string `json:"month" validate:"required"`
+	Year   string `json:"year" validate:"required"`
+}
+
+type DashboardSummary struct {
+	PatientsCount     int `json:"patients_count"`
+	DoctorsCount      int `json:"doctors_count"`
+	AppointmentsCount int `json:"appointments_count"`
+	OrdersCount       int `json:"orders_count"`
+	DoctorRequests    int `json:"doctor_requests"`
 }
 
 type AnalyticsResp struct {
@@ -50,18 +66,21 @@ type AnalyticsResp struct {
 }
 
 type Appointment struct {
-	ID           string `json:"id"`
-	UserTag      string `json:"usertag"`
-	DoctorTag    string `json:"doctortag"`
-	Scheduled_at string `json:"appointment_date"`
-	Reason       string `json:"reason"`
-	Status       string `json:"status"`
-	Fileurl      string `json:"fileurl"`
-	Created_at   string `json:"created_at"`
+	ID           string    `json:"id"`
+	UserTag      string    `json:"usertag"`
+	DoctorTag    string    `json:"doctortag"`
+	Scheduled_at time.Time `json:"appointment_date"`
+	Reason       string    `json:"reason"`
+	Status       string    `json:"status"`
+	Fileurl      string    `json:"fileurl"`
+	Created_at   time.Time `json:"created_at"`
 }
 
+// AppointmentStatuses are all the statuses an appointment can be in.
+var AppointmentStatuses = []string{"pending", "confirmed", "completed", "cancelled", "no_show"}
+
 type AppointmentID struct {
-	ID string `json:"id"`
+	ID string `json:"id" validate:"required"`
 }
 
 type Userdata struct {
@@ -78,20 +97,48 @@ type Doctordata struct {
 }
 
 type AppointmentIDResp struct {
-	UserTag         string    `json:"usertag"`
-	DoctorTag       string    `json:"doctortag"`
-	Scheduled_At    string    `json:"appointment_date"`
-	Reason          string    `json:"reason"`
-	File_URL        string    `json:"fileurl"`
-	Status          string    `json:"status"`
-	Created_At      time.Time `json:"created_at"`
-	First_name      string    `json:"firstname"`
-	Last_name       string    `json:"lastname"`
-	Phone_No        string    `json:"phone_no"`
-	Gender          string    `json:"gender"`
-	Dob             string    `json:"dob"`
-	Doctor_Fullname string    `json:"doctor_fullname"`
-	Price           float64   `json:"price"`
+	UserTag         string                    `json:"usertag"`
+	DoctorTag       string                    `json:"doctortag"`
+	Scheduled_At    time.Time                 `json:"appointment_date"`
+	Reason          string                    `json:"reason"`
+	File_URL        string                    `json:"fileurl"`
+	Status          string                    `json:"status"`
+	Created_At      time.Time                 `json:"created_at"`
+	First_name      string                    `json:"firstname"`
+	Last_name       string                    `json:"lastname"`
+	Phone_No        string                    `json:"phone_no"`
+	Gender          string                    `json:"gender"`
+	Dob             string                    `json:"dob"`
+	Doctor_Fullname string                    `json:"doctor_fullname"`
+	Price           float64                   `json:"price"`
+	Reminders       []AppointmentReminder     `json:"reminders"`
+	StatusHistory   []AppointmentStatusChange `json:"status_history"`
+}
+
+type AppointmentReminder struct {
+	Recipient     string    `json:"recipient"`
+	OffsetMinutes int       `json:"offset_minutes"`
+	Status        string    `json:"status"`
+	SentAt        time.Time `json:"sent_at"`
+}
+
+// DueReminder is a confirmed appointment that has a reminder coming due.
+type DueReminder struct {
+	AppointmentID string
+	PatientTag    string
+	PatientName   string
+	DoctorTag     string
+	DoctorName    string
+	ScheduledAt   time.Time
+}
+
+type AppointmentStatusChange struct {
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	Reason        string    `json:"reason"`
+	ChangedByType string    `json:"changed_by_type"`
+	ChangedBy     string    `json:"changed_by"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Doctorreq struct {
@@ -110,29 +157,30 @@ type Doctor struct {
 	YearsOfExperience   int            `json:"yrs_of_experience"`
 	Price               float64        `json:"price_per_session"`
 	About               string         `json:"about"`
-	Availability        datatypes.JSON `json:"availability"` // or []string if unmarshalled
+	Availability        DoctorSchedule `json:"availability"`
 	ProfilePicURL       string         `json:"profile_pic_url"`
 	HospitalAffiliation string         `json:"hospital_affiliation"` // from hospital.name
 }
 
 type UpdateAppointmentStatus struct {
-	Status         string `json:"status"`
-	Appointment_id string `json:"appointment_id"`
+	Status         string `json:"status" validate:"required,oneof=pending confirmed completed cancelled no_show"`
+	Appointment_id string `json:"appointment_id" validate:"required"`
+	Reason         string `json:"reason"`
 }
 
 type RescheduleAppointmentReq struct {
-	Appointment_id string `json:"appointment_id"`
-	NewScheduledAt string `json:"new_scheduled_at"`
+	Appointment_id string `json:"appointment_id" validate:"required"`
+	NewScheduledAt string `json:"new_scheduled_at" validate:"required,datetime"`
 }
 
 type Patient struct {
-	UserTag   string `json:"usertag"`
-	Firstname string `json:"firstname"`
-	Lastname  string `json:"lastname"`
-	Email     string `json:"email"`
-	Phone_no  string `json:"phone_no"`
+	UserTag   string `json:"usertag" validate:"required"`
+	Firstname string `json:"firstname" validate:"required"`
+	Lastname  string `json:"lastname" validate:"required"`
+	Email     string `json:"email" validate:"email"`
+	Phone_no  string `json:"phone_no" validate:"required,e164"`
 	Gender    string `json:"gender"`
-	Dob       string `json:"dob"`
+	Dob       string `json:"dob" validate:"required,date"`
 }
 
 type PatientIdReq struct {
@@ -153,51 +201,92 @@ type PatientIdResp struct {
 
 type Pharmacy struct {
 	PharmacyID   string `json:"pharmacy_id"`
-	PharmacyName string `json:"pharmacy_name"`
-	Address      string `json:"address"`
-	Country      string `json:"country"`
-	State        string `json:"state"`
+	PharmacyName string `json:"pharmacy_name" validate:"required"`
+	Address      string `json:"address" validate:"required"`
+	Country      string `json:"country" validate:"required"`
+	State        string `json:"state" validate:"required"`
 	About        string `json:"about"`
 	Picture_url  string `json:"picture_url"`
 }
 
+// PharmacyPatch holds the fields of a PATCH request; nil fields are left
+// unchanged.
+type PharmacyPatch struct {
+	PharmacyName *string `json:"pharmacy_name" validate:"required"`
+	Address      *string `json:"address" validate:"required"`
+	Country      *string `json:"country" validate:"required"`
+	State        *string `json:"state" validate:"required"`
+	About        *string `json:"about"`
+	Picture_url  *string `json:"picture_url"`
+}
+
 type Hospital struct {
 	HospitalID   string `json:"hospital_id"`
-	HospitalName string `json:"hospital_name"`
-	Address      string `json:"address"`
-	Country      string `json:"country"`
-	State        string `json:"state"`
+	HospitalName string `json:"hospital_name" validate:"required"`
+	Address      string `json:"address" validate:"required"`
+	Country      string `json:"country" validate:"required"`
+	State        string `json:"state" validate:"required"`
 	About        string `json:"about"`
 	Picture_url  string `json:"picture_url"`
 }
 
+type HospitalPatch struct {
+	HospitalName *string `json:"hospital_name" validate:"required"`
+	Address      *string `json:"address" validate:"required"`
+	Country      *string `json:"country" validate:"required"`
+	State        *string `json:"state" validate:"required"`
+	About        *string `json:"about"`
+	Picture_url  *string `json:"picture_url"`
+}
+
 type Inventory struct {
 	ProductID         string  `json:"product_id"`
-	ProductName       string  `json:"product_name"`
-	Milligrams        string  `json:"milligrams"`
-	Price             float64 `json:"price"`
+	ProductName       string  `json:"product_name" validate:"required"`
+	Milligrams        string  `json:"milligrams" validate:"required"`
+	Price             float64 `json:"price" validate:"required,min=0"`
 	Product_image_url string  `json:"product_image_url"`
 }
 
+type InventoryPatch struct {
+	ProductName       *string  `json:"product_name" validate:"required"`
+	Milligrams        *string  `json:"milligrams" validate:"required"`
+	Price             *float64 `json:"price" validate:"required,min=0"`
+	Product_image_url *string  `json:"product_image_url"`
+}
+
 type Orders struct {
-	OrderID  string `json:"order_id"`
+	OrderID  string `json:"order_id" validate:"required"`
 	UserTag  string `json:"usertag"`
 	ItemName string `json:"item_name"`
-	Quantity int    `json:"quantity"`
-	Status   string `json:"status"`
+	Quantity int    `json:"quantity" validate:"min=0"`
+	Status   string `json:"status" validate:"required,oneof=pending shipped delivered cancelled"`
 }
 
 type TestCentre struct {
 	CentreID      string         `json:"centre_id"`
-	CentreName    string         `json:"centre_name"`
-	Address       string         `json:"address"`
-	Country       string         `json:"country"`
-	State         string         `json:"state"`
-	DailyCapacity int            `json:"daily_capacity"`
+	CentreName    string         `json:"centre_name" validate:"required"`
+	Address       string         `json:"address" validate:"required"`
+	Country       string         `json:"country" validate:"required"`
+	State         string         `json:"state" validate:"required"`
+	DailyCapacity int            `json:"daily_capacity" validate:"min=0"`
 	About         string         `json:"about"`
 	Availability  datatypes.JSON `json:"availability"`
-	TestType      string         `json:"test_type"`
-	Price         float64        `json:"price"`
+	TestType      string         `json:"test_type" validate:"required"`
+	Price         float64        `json:"price" validate:"min=0"`
+	Timezone      string         `json:"timezone" validate:"timezone"`
+}
+
+type TestCentrePatch struct {
+	CentreName    *string         `json:"centre_name" validate:"required"`
+	Address       *string         `json:"address" validate:"required"`
+	Country       *string         `json:"country" validate:"required"`
+	State         *string         `json:"state" validate:"required"`
+	DailyCapacity *int            `json:"daily_capacity" validate:"min=0"`
+	About         *string         `json:"about"`
+	Availability  *datatypes.JSON `json:"availability"`
+	TestType      *string         `json:"test_type" validate:"required"`
+	Price         *float64        `json:"price" validate:"min=0"`
+	Timezone      *string         `json:"timezone" validate:"timezone"`
 }
 
 type Reviews struct {
@@ -205,18 +294,66 @@ type Reviews struct {
 	UserTag   string `json:"usertag"`
 	DoctorTag string `json:"doctortag"`
 	Review    string `json:"review"`
-	Rating    int    `json:"rating"`
+	Rating    int    `json:"rating" validate:"min=1,max=5"`
 	Status    string `json:"status"`
 }
 
-type Getreviews struct {
-	Status string `json:"status"`
-}
-
 type AdminProfile struct {
 	AdminTag      string `json:"admintag"`
-	Firstname     string `json:"firstname"`
-	Lastname      string `json:"lastname"`
-	Email         string `json:"email"`
+	Firstname     string `json:"firstname" validate:"required"`
+	Lastname      string `json:"lastname" validate:"required"`
+	Email         string `json:"email" validate:"required,email"`
 	ProfilePicURL string `json:"profile_pic"`
+	Timezone      string `json:"timezone" validate:"timezone"`
+}
+
+type InviteAdmin struct {
+	Email     string `json:"email" validate:"required,email"`
+	Firstname string `json:"firstname" validate:"required"`
+	Lastname  string `json:"lastname" validate:"required"`
+	Role      string `json:"role" validate:"required,oneof=admin god_eye pharmacist lab_staff"`
+}
+
+type AcceptAdminInvite struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type AdminAccount struct {
+	AdminTag              string    `json:"admintag"`
+	Firstname             string    `json:"firstname"`
+	Lastname              string    `json:"lastname"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	Status                string    `json:"status"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+// AdminRecord is a whole admins row, secrets included, as the sign-in and
+// account flows need it. It is never sent to a client; its JSON names are the
+// column names, so an audit snapshot of it is redacted like the row itself.
+type AdminRecord struct {
+	AdminAccount
+	ProfilePicURL     string     `json:"profile_pic_url"`
+	Timezone          string     `json:"timezone"`
+	Password          string     `json:"password"`
+	MFAMethod         string     `json:"mfa_method"`
+	TOTPSecret        string     `json:"totp_secret"`
+	TOTPPendingSecret string     `json:"totp_pending_secret"`
+	TOTPLastCounter   *int64     `json:"totp_last_counter"`
+	OTP               string     `json:"otp"`
+	OTPExpiry         *time.Time `json:"otp_expiry"`
+	OTPAttempts       int        `json:"otp_attempts"`
+	OTPSentAt         *time.Time `json:"otp_sent_at"`
+	ResetTokenHash    string     `json:"reset_token_hash"`
+	ResetTokenExpiry  *time.Time `json:"reset_token_expiry"`
+	InviteTokenHash   string     `json:"invite_token_hash"`
+	InviteExpiry      *time.Time `json:"invite_expiry"`
+}
+
+type UpdateAdminAccount struct {
+	AdminTag string `json:"-" validate:"required"`
+	Status   string `json:"status" validate:"oneof=active suspended"`
+	Role     string `json:"role" validate:"oneof=admin god_eye pharmacist lab_staff"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Actor identifies the staff member behind a write, for the audit trail.
+type Actor struct {
+	AdminTag  string
+	Role      string
+	IP        string
+	RequestID string
+}
+
+type AuditLog struct {
+	ID         int64          `json:"id"`
+	ActorTag   string         `json:"actor_admintag"`
+	ActorRole  string         `json:"actor_role"`
+	Action     string         `json:"action"`
+	EntityType string         `json:"entity_type"`
+	EntityID   string         `json:"entity_id"`
+	Before     map[string]any `json:"before"`
+	After      map[string]any `json:"after"`
+	IP         string         `json:"ip"`
+	RequestID  string         `json:"request_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
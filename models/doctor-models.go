@@ -0,0 +1,177 @@
+package models
+
+import "time"
+
+type DoctorLogin struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type DoctorLoginResponse struct {
+	DoctorTag string `json:"doctortag"`
+}
+
+type DoctorOTPVerify struct {
+	OTP       string `json:"otp" validate:"required"`
+	DoctorTag string `json:"doctortag" validate:"required"`
+	ClientInfo
+}
+
+// DoctorRecord is the sign-in side of a doctors row, as the account flows
+// need it. It is never sent to a client.
+type DoctorRecord struct {
+	DoctorTag string
+	Email     string
+	Password  string
+	Status    string
+	OTP       string
+	OTPExpiry *time.Time
+}
+
+type DoctorAppointment struct {
+	ID               string    `json:"id"`
+	UserTag          string    `json:"usertag"`
+	PatientFirstname string    `json:"patient_firstname"`
+	PatientLastname  string    `json:"patient_lastname"`
+	Scheduled_at     time.Time `json:"appointment_date"`
+	Reason           string    `json:"reason"`
+	Status           string    `json:"status"`
+	Fileurl          string    `json:"fileurl"`
+	Created_at       time.Time `json:"created_at"`
+}
+
+type DoctorAppointmentStatus struct {
+	DoctorTag      string `json:"-"`
+	Appointment_id string `json:"appointment_id" validate:"required"`
+	Status         string `json:"status" validate:"required,oneof=pending confirmed completed cancelled no_show"`
+	Reason         string `json:"reason"`
+}
+
+type DoctorProfile struct {
+	DoctorTag         string  `json:"doctortag"`
+	FullName          string  `json:"fullname" validate:"required"`
+	Email             string  `json:"email" validate:"email"`
+	Phone_no          string  `json:"phone_number" validate:"required,e164"`
+	Gender            string  `json:"gender"`
+	Specialization    string  `json:"specialization" validate:"required"`
+	Country           string  `json:"country"`
+	City              string  `json:"city"`
+	YearsOfExperience int     `json:"yrs_of_experience" validate:"min=0"`
+	Price             float64 `json:"price_per_session" validate:"min=0"`
+	About             string  `json:"about"`
+	ProfilePicURL     string  `json:"profile_pic_url"`
+}
+
+// DoctorProfilePatch is a profile update. A field left out keeps its stored
+// value.
+type DoctorProfilePatch struct {
+	DoctorTag         string   `json:"-"`
+	FullName          *string  `json:"fullname" validate:"required"`
+	Phone_no          *string  `json:"phone_number" validate:"required,e164"`
+	Specialization    *string  `json:"specialization" validate:"required"`
+	Country           *string  `json:"country"`
+	City              *string  `json:"city"`
+	YearsOfExperience *int     `json:"yrs_of_experience" validate:"min=0"`
+	Price             *float64 `json:"price_per_session" validate:"min=0"`
+	About             *string  `json:"about"`
+	ProfilePicURL     *string  `json:"profile_pic_url"`
+}
+
+// DoctorSchedule is a doctor's bookable hours. Weekly hours repeat every
+// week; a date with overrides uses those windows instead, and a blackout
+// date has no slots at all. Times are "15:04" in the doctor's timezone.
+type DoctorSchedule struct {
+	DoctorTag     string         `json:"-"`
+	Timezone      string         `json:"timezone" validate:"timezone"`
+	SlotMinutes   int            `json:"slot_minutes" validate:"min=0"`
+	BufferMinutes int            `json:"buffer_minutes" validate:"min=0"`
+	Weekly        []WeeklyHours  `json:"weekly"`
+	Overrides     []DateOverride `json:"overrides"`
+	Blackouts     []Blackout     `json:"blackouts"`
+}
+
+// WeeklyHours is a recurring window on Weekday, where 0 is Sunday.
+type WeeklyHours struct {
+	Weekday int    `json:"weekday" validate:"min=0,max=6"`
+	Start   string `json:"start" validate:"required"`
+	End     string `json:"end" validate:"required"`
+}
+
+type DateOverride struct {
+	Date  string `json:"date" validate:"required,date"`
+	Start string `json:"start" validate:"required"`
+	End   string `json:"end" validate:"required"`
+}
+
+type Blackout struct {
+	Date   string `json:"date" validate:"required,date"`
+	Reason string `json:"reason"`
+}
+
+type SlotQuery struct {
+	DoctorTag string
+	From      string
+	To        string
+}
+
+type Slot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Booking is the time a live appointment takes up in its doctor's day. It
+// lasts as long as the slot it was booked into, whatever the doctor's slot
+// length is now.
+type Booking struct {
+	Start  time.Time
+	Length time.Duration
+}
+
+type FreeSlots struct {
+	DoctorTag   string `json:"doctortag"`
+	Timezone    string `json:"timezone"`
+	SlotMinutes int    `json:"slot_minutes"`
+	Slots       []Slot `json:"slots"`
+}
+
+type DoctorApplicationReq struct {
+	FullName          string   `json:"fullname" validate:"required"`
+	Email             string   `json:"email" validate:"required,email"`
+	Password          string   `json:"password" validate:"required"`
+	Phone_no          string   `json:"phone_number" validate:"required,e164"`
+	Gender            string   `json:"gender"`
+	Dob               string   `json:"date_of_birth" validate:"date"`
+	Specialization    string   `json:"specialization" validate:"required"`
+	Country           string   `json:"country"`
+	City              string   `json:"city"`
+	YearsOfExperience int      `json:"yrs_of_experience" validate:"min=0"`
+	Price             float64  `json:"price_per_session" validate:"min=0"`
+	About             string   `json:"about"`
+	LicenseNumber     string   `json:"license_number" validate:"required"`
+	Documents         []string `json:"documents" validate:"required"`
+	// ResubmitToken is the code emailed when more information was
+	// requested. Only a resubmission needs it.
+	ResubmitToken string `json:"resubmit_token"`
+}
+
+type DoctorApplication struct {
+	DoctorTag         string    `json:"doctortag"`
+	FullName          string    `json:"fullname"`
+	Email             string    `json:"email"`
+	Phone_no          string    `json:"phone_number"`
+	Specialization    string    `json:"specialization"`
+	Country           string    `json:"country"`
+	City              string    `json:"city"`
+	YearsOfExperience int       `json:"yrs_of_experience"`
+	LicenseNumber     string    `json:"license_number"`
+	Documents         []string  `json:"documents"`
+	Status            string    `json:"status"`
+	ReviewNote        string    `json:"review_note"`
+	SubmittedAt       time.Time `json:"submitted_at"`
+}
+
+type ReviewDoctorApplication struct {
+	DoctorTag string `json:"-" validate:"required"`
+	Status    string `json:"status" validate:"required,oneof=approved rejected info_requested"`
+	Reason    string `json:"reason"`
+}
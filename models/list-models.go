@@ -0,0 +1,23 @@
+package models
+
+// ListParams are the query parameters every list endpoint accepts. Sort is a
+// field name, prefixed with "-" for descending order; anything else in the
+// query string is a filter.
+type ListParams struct {
+	Limit   int
+	Cursor  string
+	Sort    string
+	Filters map[string]string
+}
+
+type PageMeta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type Page struct {
+	Items any
+	Meta  PageMeta
+}
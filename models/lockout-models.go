@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type AuthLockout struct {
+	Scope        string     `json:"scope"`
+	Key          string     `json:"key"`
+	FailedCount  int        `json:"failed_count"`
+	LockedUntil  *time.Time `json:"locked_until"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	Locked       bool       `json:"locked"`
+}
+
+type LockoutReq struct {
+	Scope string
+	Key   string
+}
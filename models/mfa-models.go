@@ -0,0 +1,26 @@
+package models
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TOTPCode struct {
+	AdminTag string `json:"-"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatus struct {
+	Method                 string `json:"method"`
+	TOTPEnabled            bool   `json:"totp_enabled"`
+	RecoveryCodesRemaining int    `json:"recovery_codes_remaining"`
+	TOTPRequired           bool   `json:"totp_required"`
+}
+
+type MFAPolicy struct {
+	RequireTOTPForGodEye bool `json:"require_totp_for_god_eye"`
+}
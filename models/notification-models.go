@@ -0,0 +1,27 @@
+package models
+
+type NotificationPreference struct {
+	Event   string `json:"event" validate:"required"`
+	Channel string `json:"channel" validate:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+type NotificationPreferencesReq struct {
+	Subject     string                   `json:"-"`
+	Role        string                   `json:"-"`
+	Preferences []NotificationPreference `json:"preferences" validate:"required"`
+}
+
+type PushDevice struct {
+	Subject  string `json:"-"`
+	Role     string `json:"-"`
+	Token    string `json:"token" validate:"required"`
+	Platform string `json:"platform"`
+}
+
+// Contact is everywhere an account can be reached.
+type Contact struct {
+	Email   string
+	Phone   string
+	Devices []string
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+type PatientRegister struct {
+	Firstname string `json:"firstname" validate:"required"`
+	Lastname  string `json:"lastname" validate:"required"`
+	Email     string `json:"email" validate:"required,email"`
+	Phone_no  string `json:"phone_no" validate:"required,e164"`
+	Gender    string `json:"gender"`
+	Dob       string `json:"dob" validate:"date"`
+	Password  string `json:"password" validate:"required"`
+}
+
+type PatientRegisterResponse struct {
+	Usertag string `json:"usertag"`
+	Email   string `json:"email"`
+}
+
+type PatientVerifyEmail struct {
+	Email string `json:"email" validate:"required,email"`
+	OTP   string `json:"otp" validate:"required"`
+	ClientInfo
+}
+
+type PatientLogin struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	ClientInfo
+}
+
+type PatientLoginResponse struct {
+	Usertag string `json:"usertag"`
+	SessionTokens
+}
+
+type PatientResetPassword struct {
+	Email       string `json:"email" validate:"required,email"`
+	OTP         string `json:"otp" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+	ClientInfo
+}
+
+type PatientProfile struct {
+	UserTag         string `json:"usertag"`
+	Firstname       string `json:"firstname" validate:"required"`
+	Lastname        string `json:"lastname" validate:"required"`
+	Email           string `json:"email" validate:"email"`
+	Phone_no        string `json:"phone_no" validate:"required,e164"`
+	Gender          string `json:"gender"`
+	Dob             string `json:"dob" validate:"date"`
+	State           string `json:"state"`
+	DeliveryAddress string `json:"delivery_address"`
+	ProfilePicURL   string `json:"profile_pic_url"`
+	Timezone        string `json:"timezone" validate:"timezone"`
+}
+
+// PatientProfilePatch is a profile update. A field left out keeps its stored
+// value; a timezone sent empty goes back to UTC.
+type PatientProfilePatch struct {
+	UserTag         string  `json:"-"`
+	Firstname       *string `json:"firstname" validate:"required"`
+	Lastname        *string `json:"lastname" validate:"required"`
+	Phone_no        *string `json:"phone_no" validate:"required,e164"`
+	Gender          *string `json:"gender"`
+	Dob             *string `json:"dob" validate:"date"`
+	State           *string `json:"state"`
+	DeliveryAddress *string `json:"delivery_address"`
+	ProfilePicURL   *string `json:"profile_pic_url"`
+	Timezone        *string `json:"timezone" validate:"timezone"`
+}
+
+// PatientRecord is the sign-in side of a users row, as the account flows
+// need it. It is never sent to a client.
+type PatientRecord struct {
+	UserTag       string
+	Email         string
+	Password      string
+	EmailVerified bool
+	OTP           string
+	OTPExpiry     *time.Time
+}
+
+type BookAppointmentReq struct {
+	UserTag     string `json:"-"`
+	DoctorTag   string `json:"doctortag" validate:"required"`
+	ScheduledAt string `json:"scheduled_at" validate:"required,datetime"`
+	Reason      string `json:"reason"`
+	Fileurl     string `json:"fileurl"`
+}
+
+type PatientAppointmentReq struct {
+	UserTag        string `json:"-"`
+	Appointment_id string `json:"appointment_id" validate:"required"`
+	NewScheduledAt string `json:"new_scheduled_at" validate:"datetime"`
+	Reason         string `json:"reason"`
+}
+
+type PatientAppointment struct {
+	ID             string    `json:"id"`
+	DoctorTag      string    `json:"doctortag"`
+	DoctorFullname string    `json:"doctor_fullname"`
+	Scheduled_at   time.Time `json:"appointment_date"`
+	Reason         string    `json:"reason"`
+	Status         string    `json:"status"`
+	Fileurl        string    `json:"fileurl"`
+	Created_at     time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+type SearchHit struct {
+	ID        string  `json:"id"`
+	Title     string  `json:"title"`
+	Subtitle  string  `json:"subtitle"`
+	Highlight string  `json:"highlight"`
+	Rank      float64 `json:"rank"`
+}
+
+type SearchGroup struct {
+	Type string      `json:"type"`
+	Hits []SearchHit `json:"hits"`
+}
+
+type SearchResults struct {
+	Query  string        `json:"query"`
+	Groups []SearchGroup `json:"groups"`
+}
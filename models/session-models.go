@@ -0,0 +1,33 @@
+package models
+
+type ClientInfo struct {
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type SessionTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	ClientInfo
+}
+
+type SessionReq struct {
+	SessionID string
+	Subject   string
+	Role      string
+}
+
+// Session is a sessions row. Only the hash of its refresh token is kept.
+type Session struct {
+	SessionID        string
+	Subject          string
+	AccountType      string
+	Role             string
+	RefreshTokenHash string
+	ClientInfo
+}
@@ -0,0 +1,149 @@
+// Package notifications delivers templated messages to users. Sending is
+// queued and handled by background workers so a slow mail server never holds
+// up a request.
+package notifications
+
+import (
+	"errors"
+	"log"
+	"telemed/config"
+	"time"
+)
+
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+	ChannelPush  Channel = "push"
+)
+
+var Channels = []Channel{ChannelEmail, ChannelSMS, ChannelPush}
+
+// Message is a rendered notification. To is an email address, a phone number
+// or a device token depending on Channel; SMS and push only use Text (and
+// push uses Subject as its title).
+type Message struct {
+	Channel Channel
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Notifier delivers a single rendered message on one channel.
+type Notifier interface {
+	Send(msg Message) error
+}
+
+const (
+	queueSize   = 256
+	maxAttempts = 3
+)
+
+var (
+	notifiers = map[Channel]Notifier{
+		ChannelEmail: LogNotifier{},
+		ChannelSMS:   LogNotifier{},
+		ChannelPush:  LogNotifier{},
+	}
+	queue = make(chan Message, queueSize)
+)
+
+// FromConfig builds the email notifier selected by cfg.Driver.
+func FromConfig(cfg config.MailConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return SMTPNotifier{Host: cfg.Host, Port: cfg.Port, Username: cfg.Username, Password: cfg.Password, From: cfg.From, TLS: cfg.TLS}, nil
+	case "log":
+		return LogNotifier{From: cfg.From, Dir: cfg.Dir}, nil
+	case "memory":
+		return &MemoryNotifier{}, nil
+	}
+	return nil, errors.New("unknown mail driver " + cfg.Driver)
+}
+
+// SMSFromConfig builds the SMS notifier selected by cfg.Driver.
+func SMSFromConfig(cfg config.SMSConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "http":
+		return SMSProvider{URL: cfg.URL, APIKey: cfg.APIKey, From: cfg.From}, nil
+	case "log":
+		return LogNotifier{}, nil
+	case "memory":
+		return &MemoryNotifier{}, nil
+	}
+	return nil, errors.New("unknown sms driver " + cfg.Driver)
+}
+
+// PushFromConfig builds the push notifier selected by cfg.Driver.
+func PushFromConfig(cfg config.PushConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "http":
+		return PushProvider{URL: cfg.URL, ServerKey: cfg.ServerKey}, nil
+	case "log":
+		return LogNotifier{}, nil
+	case "memory":
+		return &MemoryNotifier{}, nil
+	}
+	return nil, errors.New("unknown push driver " + cfg.Driver)
+}
+
+// Start sets the notifier each channel is delivered through and starts the
+// workers draining the queue. It must be called once, before serving.
+// Channels missing from channels keep logging their messages.
+func Start(channels map[Channel]Notifier, workers int) {
+	for channel, n := range channels {
+		notifiers[channel] = n
+	}
+	for i := 0; i < workers; i++ {
+		go work()
+	}
+}
+
+// Send renders event for data and queues it as an email to to.
+func Send(to string, event Event, data any) error {
+	return SendVia(ChannelEmail, to, event, data)
+}
+
+// SendVia renders event for data and queues it for delivery to to on
+// channel. Errors are only returned for problems known up front; delivery
+// failures are logged.
+func SendVia(channel Channel, to string, event Event, data any) error {
+	var msg Message
+	var err error
+	if channel == ChannelEmail {
+		msg, err = render(event, data)
+	} else {
+		msg, err = renderShort(event, data)
+	}
+	if err != nil {
+		return err
+	}
+	msg.Channel = channel
+	msg.To = to
+
+	select {
+	case queue <- msg:
+		return nil
+	default:
+		return errors.New("notification queue is full")
+	}
+}
+
+func work() {
+	for msg := range queue {
+		deliver(msg)
+	}
+}
+
+func deliver(msg Message) {
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err := notifiers[msg.Channel].Send(msg)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to send %s %q to %s (attempt %d): %v", msg.Channel, msg.Subject, msg.To, attempt, err)
+		time.Sleep(time.Duration(attempt) * 2 * time.Second)
+	}
+}
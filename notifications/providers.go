@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var providerClient = &http.Client{Timeout: 15 * time.Second}
+
+// SMSProvider sends SMS through an HTTP gateway that accepts
+// {"from", "to", "body"} as JSON with a bearer API key, the shape most
+// gateways (and StandIn) understand.
+type SMSProvider struct {
+	URL    string
+	APIKey string
+	From   string
+}
+
+func (p SMSProvider) Send(msg Message) error {
+	return postJSON(p.URL, p.APIKey, map[string]string{"from": p.From, "to": msg.To, "body": msg.Text})
+}
+
+// PushProvider sends push notifications to a device token through an
+// FCM-style HTTP endpoint.
+type PushProvider struct {
+	URL       string
+	ServerKey string
+}
+
+func (p PushProvider) Send(msg Message) error {
+	return postJSON(p.URL, p.ServerKey, map[string]any{
+		"to":           msg.To,
+		"notification": map[string]string{"title": msg.Subject, "body": msg.Text},
+	})
+}
+
+func postJSON(url, key string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	res, err := providerClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("provider returned %s: %s", res.Status, msg)
+	}
+	return nil
+}
+
+// StandIn is a local HTTP stand-in for an SMS or push provider. It accepts
+// whatever SMSProvider or PushProvider post and keeps it for inspection, so
+// tests and local runs can point the http driver at it.
+type StandIn struct {
+	mu       sync.Mutex
+	requests []map[string]any
+}
+
+func (s *StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, payload)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Requests returns a copy of every payload received so far.
+func (s *StandIn) Requests() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.requests...)
+}
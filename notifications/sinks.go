@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogNotifier is the development sink. It logs every message and, when Dir
+// is set, also writes it there as an .eml file that mail clients can open.
+type LogNotifier struct {
+	From string
+	Dir  string
+}
+
+func (n LogNotifier) Send(msg Message) error {
+	log.Printf("Notification (%s) to %s: %s", msg.Channel, msg.To, msg.Subject)
+	if n.Dir == "" || msg.Channel != ChannelEmail {
+		return nil
+	}
+	raw, err := buildMIME(n.From, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(n.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+	return os.WriteFile(filepath.Join(n.Dir, name), raw, 0o644)
+}
+
+// MemoryNotifier keeps sent messages in memory, for tests.
+type MemoryNotifier struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (n *MemoryNotifier) Send(msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+// Messages returns a copy of everything sent so far.
+func (n *MemoryNotifier) Messages() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Message(nil), n.sent...)
+}
+
+func (n *MemoryNotifier) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = nil
+}
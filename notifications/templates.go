@@ -0,0 +1,130 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+type Event string
+
+const (
+	EventOTP                  Event = "otp"
+	EventPasswordReset        Event = "password_reset"
+	EventAppointmentConfirmed Event = "appointment_confirmed"
+	EventAppointmentReminder  Event = "appointment_reminder"
+	EventOrderShipped         Event = "order_shipped"
+	// EventNotice is a one-off message whose subject and body are supplied by
+	// the caller, for emails that don't warrant a template of their own.
+	EventNotice Event = "notice"
+)
+
+type OTPData struct {
+	OTP            string
+	ExpiresMinutes int
+}
+
+type AppointmentData struct {
+	PatientName string
+	DoctorName  string
+	ScheduledAt string
+}
+
+// ReminderData is rendered for both the patient and the doctor, so it names
+// the recipient and the person they are meeting.
+type ReminderData struct {
+	RecipientName string
+	WithName      string
+	ScheduledAt   string
+	StartsIn      string
+}
+
+type OrderData struct {
+	CustomerName string
+	OrderID      string
+	ItemName     string
+	Quantity     int
+}
+
+type NoticeData struct {
+	Subject string
+	Body    string
+}
+
+// Paragraphs splits Body on blank lines for the HTML version.
+func (d NoticeData) Paragraphs() []string {
+	return strings.Split(d.Body, "\n\n")
+}
+
+// Events lists every event users can set channel preferences for.
+var Events = []Event{EventOTP, EventPasswordReset, EventAppointmentConfirmed, EventAppointmentReminder, EventOrderShipped}
+
+// subjects are text templates rendered with the same data as the body.
+var subjects = map[Event]string{
+	EventOTP:                  "Your Telemed verification code",
+	EventPasswordReset:        "Reset your Telemed password",
+	EventAppointmentConfirmed: "Your appointment with {{.DoctorName}} is confirmed",
+	EventAppointmentReminder:  "Reminder: your appointment with {{.WithName}} starts in {{.StartsIn}}",
+	EventOrderShipped:         "Your order {{.OrderID}} has shipped",
+	EventNotice:               "{{.Subject}}",
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+)
+
+func renderSubject(event Event, data any) (string, error) {
+	subject, err := texttemplate.New("subject").Parse(subjects[event])
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := subject.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func render(event Event, data any) (Message, error) {
+	var msg Message
+	var err error
+	if msg.Subject, err = renderSubject(event, data); err != nil {
+		return msg, err
+	}
+
+	var buf bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&buf, string(event)+".txt", data); err != nil {
+		return msg, err
+	}
+	msg.Text = buf.String()
+
+	buf.Reset()
+	if err := htmlTemplates.ExecuteTemplate(&buf, string(event)+".html", data); err != nil {
+		return msg, err
+	}
+	msg.HTML = buf.String()
+	return msg, nil
+}
+
+// renderShort renders the one-paragraph version of event used for SMS and
+// push, from templates/<event>.sms.txt.
+func renderShort(event Event, data any) (Message, error) {
+	var msg Message
+	var err error
+	if msg.Subject, err = renderSubject(event, data); err != nil {
+		return msg, err
+	}
+
+	var buf bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&buf, string(event)+".sms.txt", data); err != nil {
+		return msg, err
+	}
+	msg.Text = strings.TrimSpace(buf.String())
+	return msg, nil
+}
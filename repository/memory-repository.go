@@ -0,0 +1,1740 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"telemed/database"
+	"telemed/models"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Memory keeps every aggregate in maps, for tests that should not need
+// Postgres. Lists page through rows in key order but ignore sorts and
+// filters; cursors are plain keys.
+type Memory struct {
+	mu              sync.Mutex
+	appointments    map[string]models.Appointment
+	reminders       map[string][]models.AppointmentReminder
+	history         map[string][]models.AppointmentStatusChange
+	lengths         map[string]time.Duration
+	doctors         map[string]models.DoctorApplication
+	doctorProfiles  map[string]models.DoctorProfile
+	schedules       map[string]models.DoctorSchedule
+	doctorLogins    map[string]memLogin
+	resubmitTokens  map[string]memToken
+	patients        map[string]models.Patient
+	patientProfiles map[string]models.PatientProfile
+	logins          map[string]memLogin
+	pharmacies      map[string]models.Pharmacy
+	hospitals       map[string]models.Hospital
+	inventory       map[string]models.Inventory
+	orders          map[string]models.Orders
+	testCentres     map[string]models.TestCentre
+	reviews         map[string]models.Reviews
+	admins          map[string]models.AdminRecord
+	passwords       map[string][]string
+	recovery        map[string]map[string]bool
+	mfaPolicy       models.MFAPolicy
+	lockouts        map[string]models.AuthLockout
+	sessions        map[string]memSession
+	devices         map[string]models.PushDevice
+	preferences     map[memPreference]bool
+	audit           []models.AuditLog
+	lastID          int
+}
+
+// memLogin is the sign-in state of a patient or doctor, which their models
+// leave out.
+type memLogin struct {
+	password    string
+	verified    bool
+	otp         string
+	otpExpiry   *time.Time
+	otpAttempts int
+}
+
+// memToken is a stored single-use code.
+type memToken struct {
+	hash   string
+	expiry time.Time
+}
+
+// memPreference keys a notification_preferences row.
+type memPreference struct {
+	accountType, subject, event, channel string
+}
+
+// memSession is a stored session; a revoked one is kept, as its row would be.
+type memSession struct {
+	models.Session
+	expiresAt time.Time
+	revoked   bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		appointments:    map[string]models.Appointment{},
+		reminders:       map[string][]models.AppointmentReminder{},
+		history:         map[string][]models.AppointmentStatusChange{},
+		lengths:         map[string]time.Duration{},
+		doctors:         map[string]models.DoctorApplication{},
+		doctorProfiles:  map[string]models.DoctorProfile{},
+		schedules:       map[string]models.DoctorSchedule{},
+		doctorLogins:    map[string]memLogin{},
+		resubmitTokens:  map[string]memToken{},
+		patients:        map[string]models.Patient{},
+		patientProfiles: map[string]models.PatientProfile{},
+		logins:          map[string]memLogin{},
+		pharmacies:      map[string]models.Pharmacy{},
+		hospitals:       map[string]models.Hospital{},
+		inventory:       map[string]models.Inventory{},
+		orders:          map[string]models.Orders{},
+		testCentres:     map[string]models.TestCentre{},
+		reviews:         map[string]models.Reviews{},
+		admins:          map[string]models.AdminRecord{},
+		passwords:       map[string][]string{},
+		recovery:        map[string]map[string]bool{},
+		lockouts:        map[string]models.AuthLockout{},
+		sessions:        map[string]memSession{},
+		devices:         map[string]models.PushDevice{},
+		preferences:     map[memPreference]bool{},
+	}
+}
+
+func (m *Memory) Repositories() Repositories {
+	return Repositories{
+		Appointments:  memAppointments{m},
+		Calendar:      memCalendar{m: m},
+		Doctors:       memDoctors{m},
+		Patients:      memPatients{m},
+		Pharmacies:    memPharmacies{m},
+		Hospitals:     memHospitals{m},
+		Inventory:     memInventory{m},
+		Orders:        memOrders{m},
+		TestCentres:   memTestCentres{m},
+		Reviews:       memReviews{m},
+		Admins:        memAdmins{m},
+		MFA:           memMFA{m},
+		Lockouts:      memLockouts{m},
+		Sessions:      memSessions{m},
+		Reports:       memReports{m},
+		Audit:         memAudit{m},
+		Reminders:     memReminders{m},
+		Accounts:      memAccounts{m},
+		Notifications: memNotifications{m},
+		Search:        memSearch{m},
+	}
+}
+
+// The Add methods seed aggregates the admin server can read or change but
+// never create.
+
+func (m *Memory) AddAppointment(a models.Appointment) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appointments[a.ID] = a
+}
+
+func (m *Memory) AddDoctor(d models.DoctorApplication) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.doctors[d.DoctorTag] = d
+}
+
+// AddSchedule replaces a doctor's schedule. Doctors without one have the
+// schedule a new doctors row has.
+func (m *Memory) AddSchedule(s models.DoctorSchedule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schedules[s.DoctorTag] = s
+}
+
+func (m *Memory) AddPatient(p models.Patient) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.patients[p.UserTag] = p
+}
+
+func (m *Memory) AddOrder(o models.Orders) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[o.OrderID] = o
+}
+
+func (m *Memory) AddReview(r models.Reviews) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reviews[r.ReviewID] = r
+}
+
+func (m *Memory) AddAdmin(a models.AdminRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.admins[a.AdminTag] = a
+}
+
+// AuditEntries returns everything appended to the audit log so far.
+func (m *Memory) AuditEntries() []models.AuditLog {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.AuditLog(nil), m.audit...)
+}
+
+func memGet[T any](m *Memory, rows map[string]T, id string) (T, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	row, ok := rows[id]
+	if !ok {
+		return row, ErrNotFound
+	}
+	return row, nil
+}
+
+// memUpdate only touches rows that exist, like an UPDATE ... WHERE would.
+func memUpdate[T any](m *Memory, rows map[string]T, id string, row T) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := rows[id]; ok {
+		rows[id] = row
+	}
+}
+
+// memCreate stores row under the next number, as a SERIAL key would be
+// assigned, after setKey has written that number into it.
+func memCreate[T any](m *Memory, rows map[string]T, row T, setKey func(*T, string)) T {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	id := strconv.Itoa(m.lastID)
+	setKey(&row, id)
+	rows[id] = row
+	return row
+}
+
+func memPatch[T any](m *Memory, rows map[string]T, id string, apply func(*T)) (T, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	row, ok := rows[id]
+	if !ok {
+		return row, ErrNotFound
+	}
+	apply(&row)
+	rows[id] = row
+	return row, nil
+}
+
+// memDelete returns ErrNotFound if there was nothing to delete.
+func memDelete[T any](m *Memory, rows map[string]T, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := rows[id]; !ok {
+		return ErrNotFound
+	}
+	delete(rows, id)
+	return nil
+}
+
+// set copies *value into field unless value is nil, like database.Set.
+func set[V any](field *V, value *V) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func memList[T any](m *Memory, rows map[string]T, params models.ListParams, keep func(T) bool) (models.Page, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	items := []T{}
+	page := models.Page{Items: items}
+
+	limit := params.Limit
+	if limit == 0 {
+		limit = database.DefaultLimit
+	}
+	if limit < 1 || limit > database.MaxLimit {
+		return page, fmt.Errorf("%w: limit must be between 1 and %d", database.ErrBadList, database.MaxLimit)
+	}
+
+	var keys []string
+	for key, row := range rows {
+		if keep == nil || keep(row) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	page.Meta.Total = int64(len(keys))
+	last := ""
+	for _, key := range keys {
+		if params.Cursor != "" && key <= params.Cursor {
+			continue
+		}
+		if len(items) == limit {
+			page.Meta.NextCursor = last
+			break
+		}
+		items = append(items, rows[key])
+		last = key
+	}
+	page.Items = items
+	page.Meta.Limit = limit
+	page.Meta.Sort = params.Sort
+	return page, nil
+}
+
+type memAppointments struct{ m *Memory }
+
+func (r memAppointments) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.appointments, params, nil)
+}
+
+func (r memAppointments) Get(ctx context.Context, id string) (models.AppointmentIDResp, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	a, ok := r.m.appointments[id]
+	if !ok {
+		return models.AppointmentIDResp{}, ErrNotFound
+	}
+	patient, okPatient := r.m.patients[a.UserTag]
+	doctor, okDoctor := r.m.doctors[a.DoctorTag]
+	if !okPatient || !okDoctor {
+		return models.AppointmentIDResp{}, ErrNotFound
+	}
+	return models.AppointmentIDResp{
+		UserTag:         a.UserTag,
+		DoctorTag:       a.DoctorTag,
+		Scheduled_At:    a.Scheduled_at,
+		Reason:          a.Reason,
+		File_URL:        a.Fileurl,
+		Status:          a.Status,
+		Created_At:      a.Created_at,
+		First_name:      patient.Firstname,
+		Last_name:       patient.Lastname,
+		Phone_No:        patient.Phone_no,
+		Gender:          patient.Gender,
+		Dob:             patient.Dob,
+		Doctor_Fullname: doctor.FullName,
+	}, nil
+}
+
+func (r memAppointments) Reminders(ctx context.Context, id string) ([]models.AppointmentReminder, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	return append([]models.AppointmentReminder{}, r.m.reminders[id]...), nil
+}
+
+func (r memAppointments) StatusHistory(ctx context.Context, id string) ([]models.AppointmentStatusChange, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	return append([]models.AppointmentStatusChange{}, r.m.history[id]...), nil
+}
+
+func (r memAppointments) ForPatient(ctx context.Context, userTag string) ([]models.PatientAppointment, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	appointments := []models.PatientAppointment{}
+	for _, a := range r.m.appointments {
+		if a.UserTag != userTag {
+			continue
+		}
+		appointments = append(appointments, models.PatientAppointment{
+			ID:             a.ID,
+			DoctorTag:      a.DoctorTag,
+			DoctorFullname: r.m.doctors[a.DoctorTag].FullName,
+			Scheduled_at:   a.Scheduled_at,
+			Reason:         a.Reason,
+			Status:         a.Status,
+			Fileurl:        a.Fileurl,
+			Created_at:     a.Created_at,
+		})
+	}
+	sort.Slice(appointments, func(i, j int) bool { return appointments[i].Scheduled_at.After(appointments[j].Scheduled_at) })
+	return appointments, nil
+}
+
+func (r memAppointments) ForDoctor(ctx context.Context, doctorTag string) ([]models.DoctorAppointment, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	appointments := []models.DoctorAppointment{}
+	for _, a := range r.m.appointments {
+		if a.DoctorTag != doctorTag {
+			continue
+		}
+		patient := r.m.patients[a.UserTag]
+		appointments = append(appointments, models.DoctorAppointment{
+			ID:               a.ID,
+			UserTag:          a.UserTag,
+			PatientFirstname: patient.Firstname,
+			PatientLastname:  patient.Lastname,
+			Scheduled_at:     a.Scheduled_at,
+			Reason:           a.Reason,
+			Status:           a.Status,
+			Fileurl:          a.Fileurl,
+			Created_at:       a.Created_at,
+		})
+	}
+	sort.Slice(appointments, func(i, j int) bool { return appointments[i].Scheduled_at.Before(appointments[j].Scheduled_at) })
+	return appointments, nil
+}
+
+func (r memAppointments) ChangeStatus(ctx context.Context, id string, change models.AppointmentStatusChange, check func(current models.Appointment) error) (string, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	current, ok := r.m.appointments[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+	if err := check(current); err != nil {
+		return "", err
+	}
+	from := current.Status
+	current.Status = change.ToStatus
+	r.m.appointments[id] = current
+	change.FromStatus = from
+	change.CreatedAt = time.Now()
+	r.m.history[id] = append(r.m.history[id], change)
+	return from, nil
+}
+
+// Book and Reschedule hold the store's lock while reserve runs, which stands
+// in for the transaction.
+func (r memAppointments) Book(ctx context.Context, a models.Appointment, bookedBy models.AppointmentStatusChange, reserve Reserve) (string, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	length, err := reserve(memCalendar{m: r.m, held: true}, a)
+	if err != nil {
+		return "", err
+	}
+	r.m.lastID++
+	a.ID = strconv.Itoa(r.m.lastID)
+	a.Scheduled_at = a.Scheduled_at.UTC()
+	a.Created_at = time.Now()
+	r.m.appointments[a.ID] = a
+	r.m.lengths[a.ID] = length
+	bookedBy.FromStatus, bookedBy.ToStatus, bookedBy.CreatedAt = "", a.Status, a.Created_at
+	r.m.history[a.ID] = append(r.m.history[a.ID], bookedBy)
+	return a.ID, nil
+}
+
+func (r memAppointments) Reschedule(ctx context.Context, id string, at time.Time, change models.AppointmentStatusChange, reserve Reserve) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	current, ok := r.m.appointments[id]
+	if !ok {
+		return ErrNotFound
+	}
+	moved := current
+	moved.Scheduled_at = at.UTC()
+	length, err := reserve(memCalendar{m: r.m, held: true}, moved)
+	if err != nil {
+		return err
+	}
+	if change.ToStatus != "" {
+		moved.Status = change.ToStatus
+	}
+	r.m.appointments[id] = moved
+	r.m.lengths[id] = length
+	delete(r.m.reminders, id)
+	if moved.Status != current.Status {
+		change.FromStatus, change.CreatedAt = current.Status, time.Now()
+		r.m.history[id] = append(r.m.history[id], change)
+	}
+	return nil
+}
+
+// memCalendar reads the store for Calendar. held says the caller already
+// holds the store's lock, as Book and Reschedule do.
+type memCalendar struct {
+	m    *Memory
+	held bool
+}
+
+func (c memCalendar) lock() func() {
+	if c.held {
+		return func() {}
+	}
+	c.m.mu.Lock()
+	return c.m.mu.Unlock
+}
+
+func (c memCalendar) Schedule(ctx context.Context, doctorTag string) (models.DoctorSchedule, error) {
+	defer c.lock()()
+	if d, ok := c.m.doctors[doctorTag]; !ok || d.Status != "approved" {
+		return models.DoctorSchedule{}, ErrNotFound
+	}
+	return c.m.schedule(doctorTag), nil
+}
+
+// Booked counts appointments seeded with AddAppointment as 30 minutes long,
+// the length 0017 gave appointments booked before lengths were stored.
+func (c memCalendar) Booked(ctx context.Context, doctorTag string, from, to time.Time, excludeID string) ([]models.Booking, error) {
+	defer c.lock()()
+	var booked []models.Booking
+	for id, a := range c.m.appointments {
+		length, ok := c.m.lengths[id]
+		if !ok {
+			length = 30 * time.Minute
+		}
+		if a.DoctorTag != doctorTag || id == excludeID || (a.Status != "pending" && a.Status != "confirmed") ||
+			!a.Scheduled_at.Before(to) || !a.Scheduled_at.Add(length).After(from) {
+			continue
+		}
+		booked = append(booked, models.Booking{Start: a.Scheduled_at, Length: length})
+	}
+	return booked, nil
+}
+
+// schedule returns the doctor's stored schedule, or the one a new doctors
+// row has. The caller holds m.mu.
+func (m *Memory) schedule(doctorTag string) models.DoctorSchedule {
+	if s, ok := m.schedules[doctorTag]; ok {
+		return s
+	}
+	return models.DoctorSchedule{
+		DoctorTag:   doctorTag,
+		Timezone:    "UTC",
+		SlotMinutes: 30,
+		Weekly:      []models.WeeklyHours{},
+		Overrides:   []models.DateOverride{},
+		Blackouts:   []models.Blackout{},
+	}
+}
+
+type memDoctors struct{ m *Memory }
+
+func (r memDoctors) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := memList(r.m, r.m.doctors, params, func(d models.DoctorApplication) bool { return d.Status == "approved" })
+	if err != nil {
+		return page, err
+	}
+	doctors := []models.Doctor{}
+	for _, d := range page.Items.([]models.DoctorApplication) {
+		doctors = append(doctors, models.Doctor{
+			DoctorTag:         d.DoctorTag,
+			FullName:          d.FullName,
+			Phone_no:          d.Phone_no,
+			Specialization:    d.Specialization,
+			Country:           d.Country,
+			City:              d.City,
+			YearsOfExperience: d.YearsOfExperience,
+		})
+	}
+	page.Items = doctors
+	return page, nil
+}
+
+func (r memDoctors) Get(ctx context.Context, doctorTag string) (models.Doctor, error) {
+	d, err := memGet(r.m, r.m.doctors, doctorTag)
+	if err != nil {
+		return models.Doctor{}, err
+	}
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	return models.Doctor{
+		DoctorTag:         d.DoctorTag,
+		FullName:          d.FullName,
+		Phone_no:          d.Phone_no,
+		Specialization:    d.Specialization,
+		Country:           d.Country,
+		City:              d.City,
+		YearsOfExperience: d.YearsOfExperience,
+		Availability:      r.m.schedule(doctorTag),
+	}, nil
+}
+
+func (r memDoctors) Delete(ctx context.Context, doctorTag string) error {
+	return memDelete(r.m, r.m.doctors, doctorTag)
+}
+
+func (r memDoctors) Applications(ctx context.Context, params models.ListParams) (models.Page, error) {
+	status := params.Filters["status"]
+	return memList(r.m, r.m.doctors, params, func(d models.DoctorApplication) bool { return status == "" || d.Status == status })
+}
+
+func (r memDoctors) Application(ctx context.Context, doctorTag string) (models.DoctorApplication, error) {
+	return memGet(r.m, r.m.doctors, doctorTag)
+}
+
+func (r memDoctors) ReviewApplication(ctx context.Context, doctorTag, status, note, resubmitTokenHash string, resubmitTTL time.Duration) (string, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	d, ok := r.m.doctors[doctorTag]
+	if !ok || d.Status != "pending" {
+		return "", ErrNotFound
+	}
+	d.Status = status
+	d.ReviewNote = note
+	r.m.doctors[doctorTag] = d
+	delete(r.m.resubmitTokens, doctorTag)
+	if resubmitTokenHash != "" {
+		r.m.resubmitTokens[doctorTag] = memToken{hash: resubmitTokenHash, expiry: time.Now().Add(resubmitTTL)}
+	}
+	return d.Email, nil
+}
+
+func (r memDoctors) Account(ctx context.Context, doctorTag string) (models.DoctorRecord, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	d, ok := r.m.doctors[doctorTag]
+	if !ok {
+		return models.DoctorRecord{}, ErrNotFound
+	}
+	l := r.m.doctorLogins[doctorTag]
+	return models.DoctorRecord{DoctorTag: d.DoctorTag, Email: d.Email, Password: l.password, Status: d.Status, OTP: l.otp, OTPExpiry: l.otpExpiry}, nil
+}
+
+func (r memDoctors) AccountByEmail(ctx context.Context, email string) (models.DoctorRecord, error) {
+	r.m.mu.Lock()
+	tag := ""
+	for _, d := range r.m.doctors {
+		if d.Email == email {
+			tag = d.DoctorTag
+		}
+	}
+	r.m.mu.Unlock()
+	return r.Account(ctx, tag)
+}
+
+func (r memDoctors) Apply(ctx context.Context, doctorTag string, a models.DoctorApplicationReq, passwordHash string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	r.m.doctors[doctorTag] = models.DoctorApplication{DoctorTag: doctorTag, Email: a.Email, Status: "pending"}
+	r.m.applyApplication(doctorTag, a, passwordHash)
+	return nil
+}
+
+func (r memDoctors) Resubmit(ctx context.Context, doctorTag, tokenHash string, a models.DoctorApplicationReq, passwordHash string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	token, ok := r.m.resubmitTokens[doctorTag]
+	if d := r.m.doctors[doctorTag]; d.Status != "info_requested" || !ok || token.hash != tokenHash || !time.Now().Before(token.expiry) {
+		return ErrNotFound
+	}
+	delete(r.m.resubmitTokens, doctorTag)
+	r.m.applyApplication(doctorTag, a, passwordHash)
+	return nil
+}
+
+// applyApplication writes a submitted application over the doctor's row and
+// returns it to the review queue. The caller holds m.mu.
+func (m *Memory) applyApplication(doctorTag string, a models.DoctorApplicationReq, passwordHash string) {
+	d := m.doctors[doctorTag]
+	d.FullName, d.Phone_no, d.Specialization, d.Country, d.City = a.FullName, a.Phone_no, a.Specialization, a.Country, a.City
+	d.YearsOfExperience, d.LicenseNumber, d.Documents = a.YearsOfExperience, a.LicenseNumber, a.Documents
+	d.Status, d.ReviewNote, d.SubmittedAt = "pending", "", time.Now()
+	m.doctors[doctorTag] = d
+	m.doctorProfiles[doctorTag] = models.DoctorProfile{Gender: a.Gender, Price: a.Price, About: a.About}
+	l := m.doctorLogins[doctorTag]
+	l.password = passwordHash
+	m.doctorLogins[doctorTag] = l
+}
+
+func (r memDoctors) Profile(ctx context.Context, doctorTag string) (models.DoctorProfile, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	d, ok := r.m.doctors[doctorTag]
+	if !ok {
+		return models.DoctorProfile{}, ErrNotFound
+	}
+	p := r.m.doctorProfiles[doctorTag]
+	p.DoctorTag, p.FullName, p.Email, p.Phone_no = d.DoctorTag, d.FullName, d.Email, d.Phone_no
+	p.Specialization, p.Country, p.City, p.YearsOfExperience = d.Specialization, d.Country, d.City, d.YearsOfExperience
+	return p, nil
+}
+
+func (r memDoctors) UpdateProfile(ctx context.Context, patch models.DoctorProfilePatch) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	d, ok := r.m.doctors[patch.DoctorTag]
+	if !ok {
+		return ErrNotFound
+	}
+	set(&d.FullName, patch.FullName)
+	set(&d.Phone_no, patch.Phone_no)
+	set(&d.Specialization, patch.Specialization)
+	set(&d.Country, patch.Country)
+	set(&d.City, patch.City)
+	set(&d.YearsOfExperience, patch.YearsOfExperience)
+	r.m.doctors[patch.DoctorTag] = d
+	extra := r.m.doctorProfiles[patch.DoctorTag]
+	set(&extra.Price, patch.Price)
+	set(&extra.About, patch.About)
+	set(&extra.ProfilePicURL, patch.ProfilePicURL)
+	r.m.doctorProfiles[patch.DoctorTag] = extra
+	return nil
+}
+
+func (r memDoctors) Schedule(ctx context.Context, doctorTag string) (models.DoctorSchedule, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if _, ok := r.m.doctors[doctorTag]; !ok {
+		return models.DoctorSchedule{}, ErrNotFound
+	}
+	return r.m.schedule(doctorTag), nil
+}
+
+func (r memDoctors) SetSchedule(ctx context.Context, s models.DoctorSchedule) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if _, ok := r.m.doctors[s.DoctorTag]; !ok {
+		return ErrNotFound
+	}
+	r.m.schedules[s.DoctorTag] = s
+	return nil
+}
+
+func (r memDoctors) SetOTP(ctx context.Context, doctorTag, otp string, ttl time.Duration) error {
+	expiry := time.Now().Add(ttl)
+	r.login(doctorTag, func(l *memLogin) { l.otp, l.otpExpiry, l.otpAttempts = otp, &expiry, 0 })
+	return nil
+}
+
+func (r memDoctors) ClearOTP(ctx context.Context, doctorTag string) error {
+	r.login(doctorTag, func(l *memLogin) { l.otp, l.otpExpiry, l.otpAttempts = "", nil, 0 })
+	return nil
+}
+
+func (r memDoctors) CountWrongOTP(ctx context.Context, doctorTag string) (int, error) {
+	var attempts int
+	found := r.login(doctorTag, func(l *memLogin) { l.otpAttempts++; attempts = l.otpAttempts })
+	if !found {
+		return 0, ErrNotFound
+	}
+	return attempts, nil
+}
+
+func (r memDoctors) ResetOTPAttempts(ctx context.Context, doctorTag string) error {
+	r.login(doctorTag, func(l *memLogin) { l.otpAttempts = 0 })
+	return nil
+}
+
+// login applies change to the doctor's sign-in state, reporting false if
+// there is no such doctor.
+func (r memDoctors) login(doctorTag string, change func(*memLogin)) bool {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if _, ok := r.m.doctors[doctorTag]; !ok {
+		return false
+	}
+	l := r.m.doctorLogins[doctorTag]
+	change(&l)
+	r.m.doctorLogins[doctorTag] = l
+	return true
+}
+
+type memPatients struct{ m *Memory }
+
+func (r memPatients) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.patients, params, nil)
+}
+
+// Get, like its SQL, only finds patients with at least one appointment and
+// reports the first of them.
+func (r memPatients) Get(ctx context.Context, userTag string) (models.PatientIdResp, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	p, ok := r.m.patients[userTag]
+	if !ok {
+		return models.PatientIdResp{}, ErrNotFound
+	}
+	var ids []string
+	for id, a := range r.m.appointments {
+		if a.UserTag == userTag {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return models.PatientIdResp{}, ErrNotFound
+	}
+	sort.Strings(ids)
+	a := r.m.appointments[ids[0]]
+	dob, _ := time.Parse("2006-01-02", p.Dob)
+	return models.PatientIdResp{
+		UserTag:          p.UserTag,
+		Name:             p.Firstname + " " + p.Lastname,
+		Phone_No:         p.Phone_no,
+		Gender:           p.Gender,
+		Dob:              datatypes.Date(dob),
+		Reason:           a.Reason,
+		Attending_Doctor: a.DoctorTag,
+		File_URL:         a.Fileurl,
+		Status:           a.Status,
+	}, nil
+}
+
+func (r memPatients) Update(ctx context.Context, patient models.Patient) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if p, ok := r.m.patients[patient.UserTag]; ok {
+		p.Firstname, p.Lastname, p.Phone_no, p.Dob = patient.Firstname, patient.Lastname, patient.Phone_no, patient.Dob
+		r.m.patients[patient.UserTag] = p
+	}
+	return nil
+}
+
+func (r memPatients) Delete(ctx context.Context, userTag string) error {
+	return memDelete(r.m, r.m.patients, userTag)
+}
+
+func (r memPatients) AccountByEmail(ctx context.Context, email string) (models.PatientRecord, error) {
+	var record models.PatientRecord
+	found := r.login(email, func(l *memLogin) {
+		record = models.PatientRecord{Email: email, Password: l.password, EmailVerified: l.verified, OTP: l.otp, OTPExpiry: l.otpExpiry}
+	})
+	if !found {
+		return record, ErrNotFound
+	}
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for tag, p := range r.m.patients {
+		if p.Email == email {
+			record.UserTag = tag
+		}
+	}
+	return record, nil
+}
+
+func (r memPatients) Register(ctx context.Context, p models.PatientProfile, passwordHash, otp string, ttl time.Duration) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	expiry := time.Now().Add(ttl)
+	p.Timezone = "UTC"
+	r.m.patients[p.UserTag] = models.Patient{UserTag: p.UserTag, Firstname: p.Firstname, Lastname: p.Lastname, Email: p.Email,
+		Phone_no: p.Phone_no, Gender: p.Gender, Dob: p.Dob}
+	r.m.patientProfiles[p.UserTag] = p
+	r.m.logins[p.UserTag] = memLogin{password: passwordHash, otp: otp, otpExpiry: &expiry}
+	return nil
+}
+
+// Profile combines the patient seeded with AddPatient with the fields only
+// the patient app sets, which default as a new users row does.
+func (r memPatients) Profile(ctx context.Context, userTag string) (models.PatientProfile, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	p, ok := r.m.patients[userTag]
+	if !ok {
+		return models.PatientProfile{}, ErrNotFound
+	}
+	profile, ok := r.m.patientProfiles[userTag]
+	if !ok {
+		profile.Timezone = "UTC"
+	}
+	profile.UserTag, profile.Firstname, profile.Lastname, profile.Email = p.UserTag, p.Firstname, p.Lastname, p.Email
+	profile.Phone_no, profile.Gender, profile.Dob = p.Phone_no, p.Gender, p.Dob
+	return profile, nil
+}
+
+func (r memPatients) UpdateProfile(ctx context.Context, patch models.PatientProfilePatch) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	p, ok := r.m.patients[patch.UserTag]
+	if !ok {
+		return ErrNotFound
+	}
+	set(&p.Firstname, patch.Firstname)
+	set(&p.Lastname, patch.Lastname)
+	set(&p.Phone_no, patch.Phone_no)
+	set(&p.Gender, patch.Gender)
+	set(&p.Dob, patch.Dob)
+	r.m.patients[patch.UserTag] = p
+	profile, ok := r.m.patientProfiles[patch.UserTag]
+	if !ok {
+		profile.Timezone = "UTC"
+	}
+	set(&profile.State, patch.State)
+	set(&profile.DeliveryAddress, patch.DeliveryAddress)
+	set(&profile.ProfilePicURL, patch.ProfilePicURL)
+	set(&profile.Timezone, patch.Timezone)
+	r.m.patientProfiles[patch.UserTag] = profile
+	return nil
+}
+
+func (r memPatients) SetOTP(ctx context.Context, email, otp string, ttl time.Duration) error {
+	expiry := time.Now().Add(ttl)
+	r.login(email, func(l *memLogin) { l.otp, l.otpExpiry, l.otpAttempts = otp, &expiry, 0 })
+	return nil
+}
+
+func (r memPatients) VerifyEmail(ctx context.Context, email string) error {
+	r.login(email, func(l *memLogin) { l.verified, l.otp, l.otpExpiry, l.otpAttempts = true, "", nil, 0 })
+	return nil
+}
+
+func (r memPatients) SetPassword(ctx context.Context, email, passwordHash string) error {
+	r.login(email, func(l *memLogin) { l.password, l.otp, l.otpExpiry, l.otpAttempts = passwordHash, "", nil, 0 })
+	return nil
+}
+
+func (r memPatients) ClearOTP(ctx context.Context, email string) error {
+	r.login(email, func(l *memLogin) { l.otp, l.otpExpiry, l.otpAttempts = "", nil, 0 })
+	return nil
+}
+
+func (r memPatients) CountWrongOTP(ctx context.Context, email string) (int, error) {
+	var attempts int
+	found := r.login(email, func(l *memLogin) { l.otpAttempts++; attempts = l.otpAttempts })
+	if !found {
+		return 0, ErrNotFound
+	}
+	return attempts, nil
+}
+
+func (r memPatients) ResetOTPAttempts(ctx context.Context, email string) error {
+	r.login(email, func(l *memLogin) { l.otpAttempts = 0 })
+	return nil
+}
+
+// login applies change to the sign-in state of the patient with email,
+// reporting false if there is no such patient.
+func (r memPatients) login(email string, change func(*memLogin)) bool {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for tag, p := range r.m.patients {
+		if p.Email == email {
+			l := r.m.logins[tag]
+			change(&l)
+			r.m.logins[tag] = l
+			return true
+		}
+	}
+	return false
+}
+
+type memPharmacies struct{ m *Memory }
+
+func (r memPharmacies) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.pharmacies, params, nil)
+}
+
+func (r memPharmacies) Get(ctx context.Context, id string) (models.Pharmacy, error) {
+	return memGet(r.m, r.m.pharmacies, id)
+}
+
+func (r memPharmacies) Create(ctx context.Context, pharmacy models.Pharmacy) (models.Pharmacy, error) {
+	return memCreate(r.m, r.m.pharmacies, pharmacy, func(p *models.Pharmacy, id string) { p.PharmacyID = id }), nil
+}
+
+func (r memPharmacies) Patch(ctx context.Context, id string, patch models.PharmacyPatch) (models.Pharmacy, error) {
+	return memPatch(r.m, r.m.pharmacies, id, func(p *models.Pharmacy) {
+		set(&p.PharmacyName, patch.PharmacyName)
+		set(&p.Address, patch.Address)
+		set(&p.Country, patch.Country)
+		set(&p.State, patch.State)
+		set(&p.About, patch.About)
+		set(&p.Picture_url, patch.Picture_url)
+	})
+}
+
+func (r memPharmacies) Delete(ctx context.Context, id string) error {
+	return memDelete(r.m, r.m.pharmacies, id)
+}
+
+type memHospitals struct{ m *Memory }
+
+func (r memHospitals) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.hospitals, params, nil)
+}
+
+func (r memHospitals) Get(ctx context.Context, id string) (models.Hospital, error) {
+	return memGet(r.m, r.m.hospitals, id)
+}
+
+func (r memHospitals) Create(ctx context.Context, hospital models.Hospital) (models.Hospital, error) {
+	return memCreate(r.m, r.m.hospitals, hospital, func(h *models.Hospital, id string) { h.HospitalID = id }), nil
+}
+
+func (r memHospitals) Patch(ctx context.Context, id string, patch models.HospitalPatch) (models.Hospital, error) {
+	return memPatch(r.m, r.m.hospitals, id, func(h *models.Hospital) {
+		set(&h.HospitalName, patch.HospitalName)
+		set(&h.Address, patch.Address)
+		set(&h.Country, patch.Country)
+		set(&h.State, patch.State)
+		set(&h.About, patch.About)
+		set(&h.Picture_url, patch.Picture_url)
+	})
+}
+
+func (r memHospitals) Delete(ctx context.Context, id string) error {
+	return memDelete(r.m, r.m.hospitals, id)
+}
+
+type memInventory struct{ m *Memory }
+
+func (r memInventory) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.inventory, params, nil)
+}
+
+func (r memInventory) Get(ctx context.Context, id string) (models.Inventory, error) {
+	return memGet(r.m, r.m.inventory, id)
+}
+
+func (r memInventory) Create(ctx context.Context, item models.Inventory) (models.Inventory, error) {
+	return memCreate(r.m, r.m.inventory, item, func(i *models.Inventory, id string) { i.ProductID = id }), nil
+}
+
+func (r memInventory) Patch(ctx context.Context, id string, patch models.InventoryPatch) (models.Inventory, error) {
+	return memPatch(r.m, r.m.inventory, id, func(i *models.Inventory) {
+		set(&i.ProductName, patch.ProductName)
+		set(&i.Milligrams, patch.Milligrams)
+		set(&i.Price, patch.Price)
+		set(&i.Product_image_url, patch.Product_image_url)
+	})
+}
+
+func (r memInventory) Delete(ctx context.Context, id string) error {
+	return memDelete(r.m, r.m.inventory, id)
+}
+
+type memOrders struct{ m *Memory }
+
+func (r memOrders) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.orders, params, nil)
+}
+
+func (r memOrders) Get(ctx context.Context, id string) (models.Orders, error) {
+	return memGet(r.m, r.m.orders, id)
+}
+
+func (r memOrders) Update(ctx context.Context, order models.Orders) error {
+	memUpdate(r.m, r.m.orders, order.OrderID, order)
+	return nil
+}
+
+type memTestCentres struct{ m *Memory }
+
+func (r memTestCentres) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.testCentres, params, nil)
+}
+
+func (r memTestCentres) Get(ctx context.Context, id string) (models.TestCentre, error) {
+	return memGet(r.m, r.m.testCentres, id)
+}
+
+func (r memTestCentres) Create(ctx context.Context, centre models.TestCentre) (models.TestCentre, error) {
+	return memCreate(r.m, r.m.testCentres, centre, func(c *models.TestCentre, id string) { c.CentreID = id }), nil
+}
+
+func (r memTestCentres) Patch(ctx context.Context, id string, patch models.TestCentrePatch) (models.TestCentre, error) {
+	return memPatch(r.m, r.m.testCentres, id, func(c *models.TestCentre) {
+		set(&c.CentreName, patch.CentreName)
+		set(&c.Address, patch.Address)
+		set(&c.Country, patch.Country)
+		set(&c.State, patch.State)
+		set(&c.DailyCapacity, patch.DailyCapacity)
+		set(&c.About, patch.About)
+		set(&c.Availability, patch.Availability)
+		set(&c.TestType, patch.TestType)
+		set(&c.Price, patch.Price)
+		set(&c.Timezone, patch.Timezone)
+	})
+}
+
+func (r memTestCentres) Delete(ctx context.Context, id string) error {
+	return memDelete(r.m, r.m.testCentres, id)
+}
+
+type memReviews struct{ m *Memory }
+
+func (r memReviews) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return memList(r.m, r.m.reviews, params, nil)
+}
+
+func (r memReviews) Get(ctx context.Context, id string) (models.Reviews, error) {
+	return memGet(r.m, r.m.reviews, id)
+}
+
+func (r memReviews) Delete(ctx context.Context, id string) error {
+	return memDelete(r.m, r.m.reviews, id)
+}
+
+type memAdmins struct{ m *Memory }
+
+func (r memAdmins) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := memList(r.m, r.m.admins, params, nil)
+	if err != nil {
+		return page, err
+	}
+	admins := []models.AdminAccount{}
+	for _, a := range page.Items.([]models.AdminRecord) {
+		admins = append(admins, a.AdminAccount)
+	}
+	page.Items = admins
+	return page, nil
+}
+
+func (r memAdmins) Profile(ctx context.Context, adminTag string) (models.AdminProfile, error) {
+	a, err := memGet(r.m, r.m.admins, adminTag)
+	return models.AdminProfile{AdminTag: a.AdminTag, Firstname: a.Firstname, Lastname: a.Lastname, Email: a.Email,
+		ProfilePicURL: a.ProfilePicURL, Timezone: a.Timezone}, err
+}
+
+func (r memAdmins) UpdateProfile(ctx context.Context, profile models.AdminProfile) error {
+	r.patch(profile.AdminTag, func(a *models.AdminRecord) {
+		a.Firstname, a.Lastname, a.Email = profile.Firstname, profile.Lastname, profile.Email
+		a.ProfilePicURL, a.Timezone = profile.ProfilePicURL, profile.Timezone
+	})
+	return nil
+}
+
+func (r memAdmins) patch(adminTag string, apply func(*models.AdminRecord)) error {
+	_, err := memPatch(r.m, r.m.admins, adminTag, apply)
+	return err
+}
+
+// find returns the first admin keep accepts, or ErrNotFound.
+func (r memAdmins) find(keep func(models.AdminRecord) bool) (models.AdminRecord, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, a := range r.m.admins {
+		if keep(a) {
+			return a, nil
+		}
+	}
+	return models.AdminRecord{}, ErrNotFound
+}
+
+func (r memAdmins) Get(ctx context.Context, adminTag string) (models.AdminRecord, error) {
+	return memGet(r.m, r.m.admins, adminTag)
+}
+
+func (r memAdmins) GetByEmail(ctx context.Context, email string) (models.AdminRecord, error) {
+	return r.find(func(a models.AdminRecord) bool { return a.Email == email })
+}
+
+func (r memAdmins) GetByInvite(ctx context.Context, tokenHash string) (models.AdminRecord, error) {
+	return r.find(func(a models.AdminRecord) bool { return a.Status == "invited" && a.InviteTokenHash == tokenHash })
+}
+
+func (r memAdmins) Invite(ctx context.Context, admin models.AdminRecord, inviteTTL time.Duration) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	expiry := time.Now().Add(inviteTTL)
+	admin.Status, admin.Password, admin.MFAMethod, admin.Timezone = "invited", "", "email", "UTC"
+	admin.CreatedAt, admin.InviteExpiry = time.Now(), &expiry
+	r.m.admins[admin.AdminTag] = admin
+	return nil
+}
+
+func (r memAdmins) AcceptInvite(ctx context.Context, adminTag, passwordHash string) error {
+	r.patch(adminTag, func(a *models.AdminRecord) {
+		a.Password, a.Status, a.InviteTokenHash, a.InviteExpiry = passwordHash, "active", "", nil
+	})
+	return nil
+}
+
+func (r memAdmins) SetStatus(ctx context.Context, adminTag, status string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	a, ok := r.m.admins[adminTag]
+	if !ok || a.Status == "invited" {
+		return ErrNotFound
+	}
+	a.Status = status
+	r.m.admins[adminTag] = a
+	return nil
+}
+
+func (r memAdmins) SetRole(ctx context.Context, adminTag, role string) error {
+	return r.patch(adminTag, func(a *models.AdminRecord) { a.Role = role })
+}
+
+func (r memAdmins) RequirePasswordReset(ctx context.Context, adminTag string) (string, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	a, ok := r.m.admins[adminTag]
+	if !ok || a.Status == "invited" {
+		return "", ErrNotFound
+	}
+	a.PasswordResetRequired = true
+	r.m.admins[adminTag] = a
+	return a.Email, nil
+}
+
+func (r memAdmins) SetOTP(ctx context.Context, adminTag, otp string, ttl time.Duration, emailed bool) error {
+	now := time.Now()
+	expiry := now.Add(ttl)
+	r.patch(adminTag, func(a *models.AdminRecord) {
+		a.OTP, a.OTPExpiry, a.OTPAttempts = otp, &expiry, 0
+		if emailed {
+			a.OTPSentAt = &now
+		}
+	})
+	return nil
+}
+
+func (r memAdmins) ClearOTP(ctx context.Context, adminTag string) error {
+	r.patch(adminTag, func(a *models.AdminRecord) { a.OTP, a.OTPExpiry, a.OTPAttempts = "", nil, 0 })
+	return nil
+}
+
+func (r memAdmins) CountWrongOTP(ctx context.Context, adminTag string) (int, error) {
+	a, err := memPatch(r.m, r.m.admins, adminTag, func(a *models.AdminRecord) { a.OTPAttempts++ })
+	return a.OTPAttempts, err
+}
+
+func (r memAdmins) ResetOTPAttempts(ctx context.Context, adminTag string) error {
+	r.patch(adminTag, func(a *models.AdminRecord) { a.OTPAttempts = 0 })
+	return nil
+}
+
+func (r memAdmins) SetResetToken(ctx context.Context, adminTag, tokenHash string, ttl time.Duration) error {
+	expiry := time.Now().Add(ttl)
+	r.patch(adminTag, func(a *models.AdminRecord) {
+		a.OTP, a.OTPExpiry, a.OTPAttempts = "", nil, 0
+		a.ResetTokenHash, a.ResetTokenExpiry = tokenHash, &expiry
+	})
+	return nil
+}
+
+func (r memAdmins) PasswordHashes(ctx context.Context, adminTag string, depth int) ([]string, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var hashes []string
+	if a, ok := r.m.admins[adminTag]; ok && a.Password != "" {
+		hashes = append(hashes, a.Password)
+	}
+	history := r.m.passwords[adminTag]
+	if len(history) > depth {
+		history = history[:depth]
+	}
+	return append(hashes, history...), nil
+}
+
+// ResetPassword keeps the history newest first.
+func (r memAdmins) ResetPassword(ctx context.Context, adminTag, tokenHash, passwordHash string, depth int) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	a, ok := r.m.admins[adminTag]
+	if !ok || a.ResetTokenHash == "" || a.ResetTokenHash != tokenHash {
+		return ErrNotFound
+	}
+	if a.Password != "" {
+		history := append([]string{a.Password}, r.m.passwords[adminTag]...)
+		if len(history) > depth {
+			history = history[:depth]
+		}
+		r.m.passwords[adminTag] = history
+	}
+	a.Password, a.PasswordResetRequired, a.ResetTokenHash, a.ResetTokenExpiry = passwordHash, false, "", nil
+	r.m.admins[adminTag] = a
+	return nil
+}
+
+type memMFA struct{ m *Memory }
+
+func (r memMFA) patch(adminTag string, apply func(*models.AdminRecord)) error {
+	_, err := memPatch(r.m, r.m.admins, adminTag, apply)
+	return err
+}
+
+func (r memMFA) SetPendingTOTP(ctx context.Context, adminTag, secret string) error {
+	r.patch(adminTag, func(a *models.AdminRecord) { a.TOTPPendingSecret = secret })
+	return nil
+}
+
+func (r memMFA) EnableTOTP(ctx context.Context, adminTag string, counter int64) error {
+	r.patch(adminTag, func(a *models.AdminRecord) {
+		a.TOTPSecret, a.TOTPPendingSecret, a.TOTPLastCounter, a.MFAMethod = a.TOTPPendingSecret, "", &counter, "totp"
+	})
+	return nil
+}
+
+func (r memMFA) DisableTOTP(ctx context.Context, adminTag string) error {
+	r.patch(adminTag, func(a *models.AdminRecord) { a.TOTPSecret, a.TOTPLastCounter, a.MFAMethod = "", nil, "email" })
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	delete(r.m.recovery, adminTag)
+	return nil
+}
+
+func (r memMFA) UseTOTPCounter(ctx context.Context, adminTag string, counter int64) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	a, ok := r.m.admins[adminTag]
+	if !ok || (a.TOTPLastCounter != nil && *a.TOTPLastCounter >= counter) {
+		return false, nil
+	}
+	a.TOTPLastCounter = &counter
+	r.m.admins[adminTag] = a
+	return true, nil
+}
+
+// ReplaceRecoveryCodes keeps each code hash with whether it has been used.
+func (r memMFA) ReplaceRecoveryCodes(ctx context.Context, adminTag string, codeHashes []string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	codes := map[string]bool{}
+	for _, hash := range codeHashes {
+		codes[hash] = false
+	}
+	r.m.recovery[adminTag] = codes
+	return nil
+}
+
+func (r memMFA) UseRecoveryCode(ctx context.Context, adminTag, codeHash string) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	used, ok := r.m.recovery[adminTag][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.m.recovery[adminTag][codeHash] = true
+	return true, nil
+}
+
+func (r memMFA) RecoveryCodesLeft(ctx context.Context, adminTag string) (int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	left := 0
+	for _, used := range r.m.recovery[adminTag] {
+		if !used {
+			left++
+		}
+	}
+	return left, nil
+}
+
+func (r memMFA) Policy(ctx context.Context) (models.MFAPolicy, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	return r.m.mfaPolicy, nil
+}
+
+func (r memMFA) SetPolicy(ctx context.Context, policy models.MFAPolicy) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	r.m.mfaPolicy = policy
+	return nil
+}
+
+type memLockouts struct{ m *Memory }
+
+func (r memLockouts) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	now := time.Now()
+	locked := func(l models.AuthLockout) bool { return l.LockedUntil != nil && l.LockedUntil.After(now) }
+	page, err := memList(r.m, r.m.lockouts, params, func(l models.AuthLockout) bool {
+		return l.LastFailedAt.After(now.Add(-24*time.Hour)) || locked(l)
+	})
+	if err != nil {
+		return page, err
+	}
+	lockouts := page.Items.([]models.AuthLockout)
+	for i := range lockouts {
+		lockouts[i].Locked = locked(lockouts[i])
+	}
+	return page, nil
+}
+
+func (r memLockouts) Locked(ctx context.Context, scope, key string) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	l := r.m.lockouts[scope+":"+key]
+	return l.LockedUntil != nil && l.LockedUntil.After(time.Now()), nil
+}
+
+func (r memLockouts) RecordFailure(ctx context.Context, scope, key string) (int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	now := time.Now()
+	l, ok := r.m.lockouts[scope+":"+key]
+	if !ok || l.LastFailedAt.Before(now.Add(-24*time.Hour)) {
+		l = models.AuthLockout{Scope: scope, Key: key, LockedUntil: l.LockedUntil}
+	}
+	l.FailedCount++
+	l.LastFailedAt = now
+	r.m.lockouts[scope+":"+key] = l
+	return l.FailedCount, nil
+}
+
+func (r memLockouts) Lock(ctx context.Context, scope, key string, d time.Duration) error {
+	until := time.Now().Add(d)
+	memPatch(r.m, r.m.lockouts, scope+":"+key, func(l *models.AuthLockout) { l.LockedUntil = &until })
+	return nil
+}
+
+func (r memLockouts) Clear(ctx context.Context, scope, key string) (models.AuthLockout, error) {
+	l, err := memGet(r.m, r.m.lockouts, scope+":"+key)
+	if err != nil {
+		return l, err
+	}
+	return l, memDelete(r.m, r.m.lockouts, scope+":"+key)
+}
+
+type memSessions struct{ m *Memory }
+
+func (r memSessions) Create(ctx context.Context, session models.Session, ttl time.Duration) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	r.m.sessions[session.SessionID] = memSession{Session: session, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (r memSessions) Rotate(ctx context.Context, sessionID, oldHash, newHash string, client models.ClientInfo) (models.Session, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	s, ok := r.m.sessions[sessionID]
+	if !ok || s.revoked || !s.expiresAt.After(time.Now()) {
+		return models.Session{}, ErrNotFound
+	}
+	if s.RefreshTokenHash != oldHash {
+		s.revoked = true
+		r.m.sessions[sessionID] = s
+		return s.Session, ErrTokenReused
+	}
+	s.RefreshTokenHash, s.ClientInfo = newHash, client
+	r.m.sessions[sessionID] = s
+	return s.Session, nil
+}
+
+func (r memSessions) Active(ctx context.Context, sessionID string) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	s, ok := r.m.sessions[sessionID]
+	return ok && !s.revoked && s.expiresAt.After(time.Now()), nil
+}
+
+func (r memSessions) Revoke(ctx context.Context, sessionID string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if s, ok := r.m.sessions[sessionID]; ok {
+		s.revoked = true
+		r.m.sessions[sessionID] = s
+	}
+	return nil
+}
+
+func (r memSessions) RevokeAll(ctx context.Context, accountType, subject string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for id, s := range r.m.sessions {
+		if s.AccountType == accountType && s.Subject == subject {
+			s.revoked = true
+			r.m.sessions[id] = s
+		}
+	}
+	return nil
+}
+
+type memReports struct{ m *Memory }
+
+func (r memReports) Dashboard(ctx context.Context) (models.DashboardSummary, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	summary := models.DashboardSummary{
+		PatientsCount:     len(r.m.patients),
+		AppointmentsCount: len(r.m.appointments),
+		OrdersCount:       len(r.m.orders),
+	}
+	for _, d := range r.m.doctors {
+		switch d.Status {
+		case "approved":
+			summary.DoctorsCount++
+		case "pending":
+			summary.DoctorRequests++
+		}
+	}
+	return summary, nil
+}
+
+// Payments always reports none, since the store keeps no payments.
+func (r memReports) Payments(ctx context.Context, month, year string) (models.AnalyticsResp, error) {
+	return models.AnalyticsResp{Metric: "payments", Month: month, Year: year}, nil
+}
+
+type memAudit struct{ m *Memory }
+
+// List pages through the entries oldest first, keyed by their padded ids.
+func (r memAudit) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	entries := map[string]models.AuditLog{}
+	for _, e := range r.m.AuditEntries() {
+		entries[fmt.Sprintf("%012d", e.ID)] = e
+	}
+	return memList(r.m, entries, params, nil)
+}
+
+// Snapshot returns the stored row under its JSON field names rather than
+// its column names, which is enough to compare before and after.
+func (r memAudit) Snapshot(ctx context.Context, table, keyColumn, id string) (map[string]any, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var row any
+	var ok bool
+	switch table {
+	case "appointments":
+		row, ok = r.m.appointments[id]
+	case "doctors":
+		row, ok = r.m.doctors[id]
+	case "users":
+		row, ok = r.m.patients[id]
+	case "pharmacies":
+		row, ok = r.m.pharmacies[id]
+	case "hospitals":
+		row, ok = r.m.hospitals[id]
+	case "inventory":
+		row, ok = r.m.inventory[id]
+	case "orders":
+		row, ok = r.m.orders[id]
+	case "test_centres":
+		row, ok = r.m.testCentres[id]
+	case "reviews":
+		row, ok = r.m.reviews[id]
+	case "admins":
+		row, ok = r.m.admins[id]
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	b, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot map[string]any
+	return snapshot, json.Unmarshal(b, &snapshot)
+}
+
+func (r memAudit) Append(ctx context.Context, entry models.AuditLog) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	entry.ID = int64(len(r.m.audit) + 1)
+	entry.CreatedAt = time.Now()
+	r.m.audit = append(r.m.audit, entry)
+	return nil
+}
+
+type memReminders struct{ m *Memory }
+
+func (r memReminders) Due(ctx context.Context, lower, upper time.Duration) ([]models.DueReminder, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	now := time.Now()
+	var due []models.DueReminder
+	for id, a := range r.m.appointments {
+		if a.Status != "confirmed" || !a.Scheduled_at.After(now.Add(lower)) || a.Scheduled_at.After(now.Add(upper)) {
+			continue
+		}
+		patient, okPatient := r.m.patients[a.UserTag]
+		doctor, okDoctor := r.m.doctors[a.DoctorTag]
+		if !okPatient || !okDoctor {
+			continue
+		}
+		claimed := 0
+		for _, reminder := range r.m.reminders[id] {
+			if reminder.OffsetMinutes == int(upper.Minutes()) && reminder.Status == "sent" {
+				claimed++
+			}
+		}
+		if claimed >= 2 {
+			continue
+		}
+		due = append(due, models.DueReminder{
+			AppointmentID: id,
+			PatientTag:    a.UserTag,
+			PatientName:   strings.TrimSpace(patient.Firstname + " " + patient.Lastname),
+			DoctorTag:     a.DoctorTag,
+			DoctorName:    doctor.FullName,
+			ScheduledAt:   a.Scheduled_at,
+		})
+	}
+	return due, nil
+}
+
+func (r memReminders) Claim(ctx context.Context, appointmentID, recipient string, offset time.Duration) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for i, reminder := range r.m.reminders[appointmentID] {
+		if reminder.Recipient == recipient && reminder.OffsetMinutes == int(offset.Minutes()) {
+			if reminder.Status != "failed" {
+				return false, nil
+			}
+			r.m.reminders[appointmentID][i].Status, r.m.reminders[appointmentID][i].SentAt = "sent", time.Now()
+			return true, nil
+		}
+	}
+	r.m.reminders[appointmentID] = append(r.m.reminders[appointmentID], models.AppointmentReminder{
+		Recipient:     recipient,
+		OffsetMinutes: int(offset.Minutes()),
+		Status:        "sent",
+		SentAt:        time.Now(),
+	})
+	return true, nil
+}
+
+func (r memReminders) Fail(ctx context.Context, appointmentID, recipient string, offset time.Duration) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for i, reminder := range r.m.reminders[appointmentID] {
+		if reminder.Recipient == recipient && reminder.OffsetMinutes == int(offset.Minutes()) {
+			r.m.reminders[appointmentID][i].Status = "failed"
+		}
+	}
+	return nil
+}
+
+type memAccounts struct{ m *Memory }
+
+func (r memAccounts) Contact(ctx context.Context, accountType, subject string) (models.Contact, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var c models.Contact
+	switch accountType {
+	case "patient":
+		p, ok := r.m.patients[subject]
+		if !ok {
+			return c, ErrNotFound
+		}
+		c.Email, c.Phone = p.Email, p.Phone_no
+	case "doctor":
+		d, ok := r.m.doctors[subject]
+		if !ok {
+			return c, ErrNotFound
+		}
+		c.Email, c.Phone = d.Email, d.Phone_no
+	default:
+		a, ok := r.m.admins[subject]
+		if !ok {
+			return c, ErrNotFound
+		}
+		c.Email = a.Email
+	}
+	tokens := make([]string, 0, len(r.m.devices))
+	for token := range r.m.devices {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	for _, token := range tokens {
+		if d := r.m.devices[token]; d.Role == accountType && d.Subject == subject {
+			c.Devices = append(c.Devices, token)
+		}
+	}
+	return c, nil
+}
+
+func (r memAccounts) Timezone(ctx context.Context, accountType, subject string) (string, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	switch accountType {
+	case "patient":
+		if _, ok := r.m.patients[subject]; !ok {
+			return "", ErrNotFound
+		}
+		if p, ok := r.m.patientProfiles[subject]; ok {
+			return p.Timezone, nil
+		}
+		return "UTC", nil
+	case "doctor":
+		if _, ok := r.m.doctors[subject]; !ok {
+			return "", ErrNotFound
+		}
+		return r.m.schedule(subject).Timezone, nil
+	default:
+		a, ok := r.m.admins[subject]
+		if !ok {
+			return "", ErrNotFound
+		}
+		return a.Timezone, nil
+	}
+}
+
+type memNotifications struct{ m *Memory }
+
+func (r memNotifications) Preferences(ctx context.Context, accountType, subject, event string) (map[string]bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	prefs := map[string]bool{}
+	for key, enabled := range r.m.preferences {
+		if key.accountType == accountType && key.subject == subject && key.event == event {
+			prefs[key.channel] = enabled
+		}
+	}
+	return prefs, nil
+}
+
+func (r memNotifications) SetPreferences(ctx context.Context, accountType, subject string, preferences []models.NotificationPreference) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, p := range preferences {
+		r.m.preferences[memPreference{accountType, subject, p.Event, p.Channel}] = p.Enabled
+	}
+	return nil
+}
+
+// Devices are stored with Role holding the account type.
+func (r memNotifications) RegisterDevice(ctx context.Context, accountType string, d models.PushDevice) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	d.Role = accountType
+	r.m.devices[d.Token] = d
+	return nil
+}
+
+func (r memNotifications) RemoveDevice(ctx context.Context, accountType string, d models.PushDevice) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	stored, ok := r.m.devices[d.Token]
+	if !ok || stored.Role != accountType || stored.Subject != d.Subject {
+		return ErrNotFound
+	}
+	delete(r.m.devices, d.Token)
+	return nil
+}
+
+// memSearch matches a case-insensitive substring of the title, and ranks
+// every hit the same.
+type memSearch struct{ m *Memory }
+
+func (r memSearch) Groups() []string {
+	return searchGroupNames()
+}
+
+func (r memSearch) Find(ctx context.Context, group, term string, limit int) ([]models.SearchHit, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var candidates []models.SearchHit
+	switch group {
+	case "patients":
+		for tag, p := range r.m.patients {
+			candidates = append(candidates, models.SearchHit{ID: tag, Title: p.Firstname + " " + p.Lastname, Subtitle: p.Email})
+		}
+	case "doctors":
+		for tag, d := range r.m.doctors {
+			candidates = append(candidates, models.SearchHit{ID: tag, Title: d.FullName, Subtitle: d.Specialization})
+		}
+	case "inventory":
+		for id, i := range r.m.inventory {
+			candidates = append(candidates, models.SearchHit{ID: id, Title: i.ProductName, Subtitle: i.Milligrams})
+		}
+	case "hospitals":
+		for id, h := range r.m.hospitals {
+			candidates = append(candidates, models.SearchHit{ID: id, Title: h.HospitalName, Subtitle: h.State})
+		}
+	case "pharmacies":
+		for id, p := range r.m.pharmacies {
+			candidates = append(candidates, models.SearchHit{ID: id, Title: p.PharmacyName, Subtitle: p.State})
+		}
+	case "test_centres":
+		for id, c := range r.m.testCentres {
+			candidates = append(candidates, models.SearchHit{ID: id, Title: c.CentreName, Subtitle: c.State})
+		}
+	default:
+		return nil, fmt.Errorf("unknown search group %q", group)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+
+	hits := []models.SearchHit{}
+	for _, hit := range candidates {
+		if len(hits) == limit {
+			break
+		}
+		if strings.Contains(strings.ToLower(hit.Title), strings.ToLower(term)) {
+			hit.Highlight, hit.Rank = hit.Title, 1
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
@@ -0,0 +1,1890 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"telemed/database"
+	"telemed/models"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// NewPgx returns repositories backed by db.
+func NewPgx(db *pgxpool.Pool) Repositories {
+	return Repositories{
+		Appointments:  pgxAppointments{db},
+		Calendar:      pgxCalendar{q: db},
+		Doctors:       pgxDoctors{db},
+		Patients:      pgxPatients{db},
+		Pharmacies:    pgxPharmacies{db},
+		Hospitals:     pgxHospitals{db},
+		Inventory:     pgxInventory{db},
+		Orders:        pgxOrders{db},
+		TestCentres:   pgxTestCentres{db},
+		Reviews:       pgxReviews{db},
+		Admins:        pgxAdmins{db},
+		MFA:           pgxMFA{db},
+		Lockouts:      pgxLockouts{db},
+		Sessions:      pgxSessions{db},
+		Reports:       pgxReports{db},
+		Audit:         pgxAudit{db},
+		Reminders:     pgxReminders{db},
+		Accounts:      pgxAccounts{db},
+		Notifications: pgxNotifications{db},
+		Search:        pgxSearch{db},
+	}
+}
+
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type pgxAppointments struct{ db *pgxpool.Pool }
+
+var appointmentList = database.ListSpec{
+	Select: `appointment_id::text, patient_tag, doctor_tag, scheduled_at, COALESCE(reason, ''), status, COALESCE(file_url, ''),
+		created_at`,
+	From: "appointments",
+	Key:  "appointment_id",
+	Sorts: map[string]database.Sort{
+		"scheduled_at": {Expr: "scheduled_at", Type: "timestamptz"},
+		"created_at":   {Expr: "created_at", Type: "timestamptz"},
+		"status":       {Expr: "status", Type: "text"},
+	},
+	DefaultSort: "-scheduled_at",
+	Filters: map[string]database.Filter{
+		"status":  {Expr: "status", Op: "=", Values: models.AppointmentStatuses},
+		"doctor":  {Expr: "doctor_tag", Op: "="},
+		"patient": {Expr: "patient_tag", Op: "="},
+		"from":    {Expr: "scheduled_at", Kind: database.Time, Op: ">="},
+		"to":      {Expr: "scheduled_at", Kind: database.Time, Op: "<"},
+	},
+}
+
+func (r pgxAppointments) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, appointmentList, params, func(a *models.Appointment) []any {
+		return []any{&a.ID, &a.UserTag, &a.DoctorTag, &a.Scheduled_at, &a.Reason, &a.Status, &a.Fileurl, &a.Created_at}
+	})
+}
+
+func (r pgxAppointments) Get(ctx context.Context, id string) (models.AppointmentIDResp, error) {
+	var data models.AppointmentIDResp
+	query := `
+		SELECT a.patient_tag, a.doctor_tag, a.scheduled_at, a.reason, a.file_url, a.status, a.created_at,
+		       u.firstname, u.lastname, u.phone_no, u.gender, u.date_of_birth,
+		       d.fullname, d.price_per_session
+		FROM appointments a
+		JOIN users u ON a.patient_tag = u.usertag
+		JOIN doctors d ON a.doctor_tag = d.doctortag
+		WHERE a.appointment_id = $1
+	`
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&data.UserTag,
+		&data.DoctorTag,
+		&data.Scheduled_At,
+		&data.Reason,
+		&data.File_URL,
+		&data.Status,
+		&data.Created_At,
+		&data.First_name,
+		&data.Last_name,
+		&data.Phone_No,
+		&data.Gender,
+		&data.Dob,
+		&data.Doctor_Fullname,
+		&data.Price,
+	)
+	return data, notFound(err)
+}
+
+func (r pgxAppointments) Reminders(ctx context.Context, id string) ([]models.AppointmentReminder, error) {
+	reminders := []models.AppointmentReminder{}
+	rows, err := r.db.Query(ctx, `SELECT recipient, offset_minutes, status, sent_at FROM appointment_reminders
+			WHERE appointment_id::text = $1 ORDER BY sent_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var reminder models.AppointmentReminder
+		if err := rows.Scan(&reminder.Recipient, &reminder.OffsetMinutes, &reminder.Status, &reminder.SentAt); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+	return reminders, rows.Err()
+}
+
+func (r pgxAppointments) StatusHistory(ctx context.Context, id string) ([]models.AppointmentStatusChange, error) {
+	history := []models.AppointmentStatusChange{}
+	rows, err := r.db.Query(ctx, `SELECT COALESCE(from_status, ''), to_status, COALESCE(reason, ''), changed_by_type, changed_by, created_at
+			FROM appointment_status_history WHERE appointment_id::text = $1 ORDER BY created_at, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var change models.AppointmentStatusChange
+		if err := rows.Scan(&change.FromStatus, &change.ToStatus, &change.Reason, &change.ChangedByType, &change.ChangedBy, &change.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+func (r pgxAppointments) ForPatient(ctx context.Context, userTag string) ([]models.PatientAppointment, error) {
+	appointments := []models.PatientAppointment{}
+	rows, err := r.db.Query(ctx, `SELECT a.appointment_id::text, a.doctor_tag, COALESCE(d.fullname, ''), a.scheduled_at, COALESCE(a.reason, ''),
+				a.status, COALESCE(a.file_url, ''), a.created_at
+			FROM appointments a
+			LEFT JOIN doctors d ON a.doctor_tag = d.doctortag
+			WHERE a.patient_tag = $1
+			ORDER BY a.scheduled_at DESC`, userTag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a models.PatientAppointment
+		if err := rows.Scan(&a.ID, &a.DoctorTag, &a.DoctorFullname, &a.Scheduled_at, &a.Reason, &a.Status, &a.Fileurl, &a.Created_at); err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, a)
+	}
+	return appointments, rows.Err()
+}
+
+func (r pgxAppointments) ForDoctor(ctx context.Context, doctorTag string) ([]models.DoctorAppointment, error) {
+	appointments := []models.DoctorAppointment{}
+	rows, err := r.db.Query(ctx, `SELECT a.appointment_id::text, a.patient_tag, COALESCE(u.firstname, ''), COALESCE(u.lastname, ''), a.scheduled_at,
+				COALESCE(a.reason, ''), a.status, COALESCE(a.file_url, ''), a.created_at
+			FROM appointments a
+			LEFT JOIN users u ON a.patient_tag = u.usertag
+			WHERE a.doctor_tag = $1
+			ORDER BY a.scheduled_at`, doctorTag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a models.DoctorAppointment
+		if err := rows.Scan(&a.ID, &a.UserTag, &a.PatientFirstname, &a.PatientLastname, &a.Scheduled_at, &a.Reason, &a.Status,
+			&a.Fileurl, &a.Created_at); err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, a)
+	}
+	return appointments, rows.Err()
+}
+
+func (r pgxAppointments) ChangeStatus(ctx context.Context, id string, change models.AppointmentStatusChange, check func(current models.Appointment) error) (string, error) {
+	current := models.Appointment{ID: id}
+	err := database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, "SELECT status, patient_tag, doctor_tag FROM appointments WHERE appointment_id::text = $1 FOR UPDATE", id).
+			Scan(&current.Status, &current.UserTag, &current.DoctorTag)
+		if err != nil {
+			return notFound(err)
+		}
+		if err := check(current); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE appointments SET status = $1 WHERE appointment_id::text = $2", change.ToStatus, id); err != nil {
+			return err
+		}
+		change.FromStatus = current.Status
+		return recordStatusChange(ctx, tx, id, change)
+	})
+	if err != nil {
+		return "", err
+	}
+	return current.Status, nil
+}
+
+// recordStatusChange adds a row to an appointment's status history inside
+// tx. change.FromStatus is empty for the status an appointment was booked
+// with.
+func recordStatusChange(ctx context.Context, tx pgx.Tx, appointmentID string, change models.AppointmentStatusChange) error {
+	_, err := tx.Exec(ctx, `INSERT INTO appointment_status_history (appointment_id, from_status, to_status, reason, changed_by_type, changed_by)
+			VALUES ($1::int, NULLIF($2, ''), $3, NULLIF($4, ''), $5, $6)`,
+		appointmentID, change.FromStatus, change.ToStatus, change.Reason, change.ChangedByType, change.ChangedBy)
+	return err
+}
+
+func (r pgxAppointments) Book(ctx context.Context, a models.Appointment, bookedBy models.AppointmentStatusChange, reserve Reserve) (string, error) {
+	var id string
+	err := database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		length, err := reserve(pgxCalendar{q: tx, lock: true}, a)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRow(ctx, `INSERT INTO appointments (patient_tag, doctor_tag, scheduled_at, duration_minutes, reason, file_url, status)
+				VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING appointment_id::text`,
+			a.UserTag, a.DoctorTag, a.Scheduled_at.UTC(), int(length/time.Minute), a.Reason, a.Fileurl, a.Status).Scan(&id)
+		if err != nil {
+			return err
+		}
+		bookedBy.FromStatus, bookedBy.ToStatus = "", a.Status
+		return recordStatusChange(ctx, tx, id, bookedBy)
+	})
+	return id, err
+}
+
+func (r pgxAppointments) Reschedule(ctx context.Context, id string, at time.Time, change models.AppointmentStatusChange, reserve Reserve) error {
+	return database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		current := models.Appointment{ID: id}
+		err := tx.QueryRow(ctx, `SELECT status, patient_tag, doctor_tag, COALESCE(reason, ''), COALESCE(file_url, ''), created_at
+				FROM appointments WHERE appointment_id::text = $1 FOR UPDATE`, id).
+			Scan(&current.Status, &current.UserTag, &current.DoctorTag, &current.Reason, &current.Fileurl, &current.Created_at)
+		if err != nil {
+			return notFound(err)
+		}
+		moved := current
+		moved.Scheduled_at = at
+		length, err := reserve(pgxCalendar{q: tx, lock: true}, moved)
+		if err != nil {
+			return err
+		}
+		if change.ToStatus != "" {
+			moved.Status = change.ToStatus
+		}
+		_, err = tx.Exec(ctx, "UPDATE appointments SET scheduled_at = $1, duration_minutes = $2, status = $3 WHERE appointment_id::text = $4",
+			at.UTC(), int(length/time.Minute), moved.Status, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM appointment_reminders WHERE appointment_id::text = $1", id); err != nil {
+			return err
+		}
+		if moved.Status == current.Status {
+			return nil
+		}
+		change.FromStatus = current.Status
+		return recordStatusChange(ctx, tx, id, change)
+	})
+}
+
+// pgxCalendar reads through q, which is the pool or a booking's
+// transaction. lock is set inside a booking.
+type pgxCalendar struct {
+	q    database.Queryer
+	lock bool
+}
+
+func (c pgxCalendar) Schedule(ctx context.Context, doctorTag string) (models.DoctorSchedule, error) {
+	query := "SELECT doctortag FROM doctors WHERE doctortag = $1 AND status = 'approved'"
+	if c.lock {
+		query += " FOR UPDATE"
+	}
+	var approved string
+	if err := c.q.QueryRow(ctx, query, doctorTag).Scan(&approved); err != nil {
+		return models.DoctorSchedule{}, notFound(err)
+	}
+	return loadSchedule(ctx, c.q, doctorTag)
+}
+
+func (c pgxCalendar) Booked(ctx context.Context, doctorTag string, from, to time.Time, excludeID string) ([]models.Booking, error) {
+	rows, err := c.q.Query(ctx, `SELECT scheduled_at, duration_minutes FROM appointments
+			WHERE doctor_tag = $1 AND status IN ('pending', 'confirmed')
+			AND scheduled_at < $3 AND scheduled_at + make_interval(mins => duration_minutes) > $2
+			AND ($4 = '' OR appointment_id::text <> $4)`, doctorTag, from.UTC(), to.UTC(), excludeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var booked []models.Booking
+	for rows.Next() {
+		var b models.Booking
+		var minutes int
+		if err := rows.Scan(&b.Start, &minutes); err != nil {
+			return nil, err
+		}
+		b.Length = time.Duration(minutes) * time.Minute
+		booked = append(booked, b)
+	}
+	return booked, rows.Err()
+}
+
+type pgxDoctors struct{ db *pgxpool.Pool }
+
+var doctorList = database.ListSpec{
+	Select: `doctortag, COALESCE(fullname, ''), COALESCE(date_of_birth::text, ''), COALESCE(phone_number, ''), COALESCE(gender, ''),
+		COALESCE(specialization, ''), COALESCE(country, ''), COALESCE(city, ''), COALESCE(yrs_of_experience, 0),
+		COALESCE(price_per_session, 0)`,
+	From:  "doctors",
+	Where: "status = 'approved'",
+	Key:   "doctortag",
+	Sorts: map[string]database.Sort{
+		"fullname":   {Expr: "COALESCE(fullname, '')", Type: "text"},
+		"price":      {Expr: "COALESCE(price_per_session, 0)", Type: "numeric"},
+		"experience": {Expr: "COALESCE(yrs_of_experience, 0)", Type: "integer"},
+	},
+	DefaultSort: "fullname",
+	Filters: map[string]database.Filter{
+		"name":           {Expr: "fullname", Op: "contains"},
+		"specialization": {Expr: "specialization", Op: "ieq"},
+		"country":        {Expr: "country", Op: "ieq"},
+		"city":           {Expr: "city", Op: "ieq"},
+		"gender":         {Expr: "gender", Op: "ieq"},
+		"hospital":       {Expr: "hospital_id::text", Op: "="},
+		"min_price":      {Expr: "price_per_session", Kind: database.Number, Op: ">="},
+		"max_price":      {Expr: "price_per_session", Kind: database.Number, Op: "<="},
+	},
+}
+
+func (r pgxDoctors) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, doctorList, params, func(d *models.Doctor) []any {
+		return []any{&d.DoctorTag, &d.FullName, &d.Dob, &d.Phone_no, &d.Gender, &d.Specialization, &d.Country, &d.City,
+			&d.YearsOfExperience, &d.Price}
+	})
+}
+
+func (r pgxDoctors) Get(ctx context.Context, doctorTag string) (models.Doctor, error) {
+	var doctor models.Doctor
+	query := `
+	SELECT
+		d.doctortag,
+		COALESCE(d.fullname, ''),
+		COALESCE(d.date_of_birth::text, ''),
+		COALESCE(d.phone_number, ''),
+		COALESCE(d.gender, ''),
+		COALESCE(d.specialization, ''),
+		COALESCE(d.country, ''),
+		COALESCE(d.city, ''),
+		COALESCE(d.yrs_of_experience, 0),
+		COALESCE(d.price_per_session, 0),
+		COALESCE(d.about, ''),
+		COALESCE(d.profile_pic_url, ''),
+		COALESCE(h.name, '') AS hospital_affiliation
+	FROM doctors d
+	LEFT JOIN hospitals h ON d.hospital_id = h.hospital_id
+	WHERE d.doctortag = $1
+	`
+	err := r.db.QueryRow(ctx, query, doctorTag).Scan(
+		&doctor.DoctorTag,
+		&doctor.FullName,
+		&doctor.Dob,
+		&doctor.Phone_no,
+		&doctor.Gender,
+		&doctor.Specialization,
+		&doctor.Country,
+		&doctor.City,
+		&doctor.YearsOfExperience,
+		&doctor.Price,
+		&doctor.About,
+		&doctor.ProfilePicURL,
+		&doctor.HospitalAffiliation,
+	)
+	if err != nil {
+		return doctor, notFound(err)
+	}
+	doctor.Availability, err = loadSchedule(ctx, r.db, doctorTag)
+	return doctor, err
+}
+
+// loadSchedule reads a doctor's schedule through q, which may be a
+// transaction. It returns pgx.ErrNoRows if the doctor does not exist.
+func loadSchedule(ctx context.Context, q database.Queryer, doctorTag string) (models.DoctorSchedule, error) {
+	s := models.DoctorSchedule{
+		DoctorTag: doctorTag,
+		Weekly:    []models.WeeklyHours{},
+		Overrides: []models.DateOverride{},
+		Blackouts: []models.Blackout{},
+	}
+	err := q.QueryRow(ctx, "SELECT timezone, slot_minutes, buffer_minutes FROM doctors WHERE doctortag = $1", doctorTag).
+		Scan(&s.Timezone, &s.SlotMinutes, &s.BufferMinutes)
+	if err != nil {
+		return s, err
+	}
+
+	rows, err := q.Query(ctx, `SELECT weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+			FROM doctor_weekly_hours WHERE doctortag = $1 ORDER BY weekday, start_time`, doctorTag)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var h models.WeeklyHours
+		if err := rows.Scan(&h.Weekday, &h.Start, &h.End); err != nil {
+			rows.Close()
+			return s, err
+		}
+		s.Weekly = append(s.Weekly, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	rows, err = q.Query(ctx, `SELECT to_char(date, 'YYYY-MM-DD'), to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+			FROM doctor_date_overrides WHERE doctortag = $1 ORDER BY date, start_time`, doctorTag)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var o models.DateOverride
+		if err := rows.Scan(&o.Date, &o.Start, &o.End); err != nil {
+			rows.Close()
+			return s, err
+		}
+		s.Overrides = append(s.Overrides, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	rows, err = q.Query(ctx, `SELECT to_char(date, 'YYYY-MM-DD'), COALESCE(reason, '')
+			FROM doctor_blackouts WHERE doctortag = $1 ORDER BY date`, doctorTag)
+	if err != nil {
+		return s, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b models.Blackout
+		if err := rows.Scan(&b.Date, &b.Reason); err != nil {
+			return s, err
+		}
+		s.Blackouts = append(s.Blackouts, b)
+	}
+	return s, rows.Err()
+}
+
+func (r pgxDoctors) Delete(ctx context.Context, doctorTag string) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM doctors WHERE doctortag = $1", doctorTag)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+const doctorApplicationColumns = `doctortag, COALESCE(fullname, ''), COALESCE(email, ''), COALESCE(phone_number, ''), COALESCE(specialization, ''),
+		COALESCE(country, ''), COALESCE(city, ''), COALESCE(yrs_of_experience, 0), COALESCE(license_number, ''),
+		COALESCE(documents, '[]'::jsonb), status, COALESCE(review_note, ''), COALESCE(submitted_at, NOW())`
+
+func doctorApplicationFields(a *models.DoctorApplication) []any {
+	return []any{&a.DoctorTag, &a.FullName, &a.Email, &a.Phone_no, &a.Specialization, &a.Country, &a.City, &a.YearsOfExperience,
+		&a.LicenseNumber, &a.Documents, &a.Status, &a.ReviewNote, &a.SubmittedAt}
+}
+
+var doctorApplicationList = database.ListSpec{
+	Select: doctorApplicationColumns,
+	From:   "doctors",
+	Key:    "doctortag",
+	Sorts: map[string]database.Sort{
+		"submitted_at": {Expr: "COALESCE(submitted_at, 'epoch')", Type: "timestamp"},
+		"fullname":     {Expr: "COALESCE(fullname, '')", Type: "text"},
+	},
+	DefaultSort: "submitted_at",
+	Filters: map[string]database.Filter{
+		"status":         {Expr: "status", Op: "=", Values: []string{"pending", "approved", "rejected", "info_requested"}},
+		"specialization": {Expr: "specialization", Op: "ieq"},
+		"country":        {Expr: "country", Op: "ieq"},
+	},
+}
+
+func (r pgxDoctors) Applications(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, doctorApplicationList, params, doctorApplicationFields)
+}
+
+func (r pgxDoctors) Application(ctx context.Context, doctorTag string) (models.DoctorApplication, error) {
+	var application models.DoctorApplication
+	err := r.db.QueryRow(ctx, "SELECT "+doctorApplicationColumns+" FROM doctors WHERE doctortag = $1", doctorTag).
+		Scan(doctorApplicationFields(&application)...)
+	return application, notFound(err)
+}
+
+func (r pgxDoctors) ReviewApplication(ctx context.Context, doctorTag, status, note, resubmitTokenHash string, resubmitTTL time.Duration) (string, error) {
+	var email string
+	err := r.db.QueryRow(ctx, `UPDATE doctors SET status = $1, review_note = NULLIF($2, ''), resubmit_token_hash = NULLIF($3, ''),
+				resubmit_token_expiry = CASE WHEN $3 = '' THEN NULL ELSE NOW() + make_interval(secs => $4) END
+			WHERE doctortag = $5 AND status = 'pending' RETURNING COALESCE(email, '')`,
+		status, note, resubmitTokenHash, int(resubmitTTL.Seconds()), doctorTag).Scan(&email)
+	return email, notFound(err)
+}
+
+const doctorRecordColumns = "doctortag, COALESCE(email, ''), password, status, COALESCE(otp, ''), otp_expiry"
+
+func scanDoctorRecord(row pgx.Row) (models.DoctorRecord, error) {
+	var d models.DoctorRecord
+	err := row.Scan(&d.DoctorTag, &d.Email, &d.Password, &d.Status, &d.OTP, &d.OTPExpiry)
+	return d, notFound(err)
+}
+
+func (r pgxDoctors) Account(ctx context.Context, doctorTag string) (models.DoctorRecord, error) {
+	return scanDoctorRecord(r.db.QueryRow(ctx, "SELECT "+doctorRecordColumns+" FROM doctors WHERE doctortag = $1", doctorTag))
+}
+
+func (r pgxDoctors) AccountByEmail(ctx context.Context, email string) (models.DoctorRecord, error) {
+	return scanDoctorRecord(r.db.QueryRow(ctx, "SELECT "+doctorRecordColumns+" FROM doctors WHERE email = $1", email))
+}
+
+func (r pgxDoctors) Apply(ctx context.Context, doctorTag string, a models.DoctorApplicationReq, passwordHash string) error {
+	documents, err := json.Marshal(a.Documents)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `INSERT INTO doctors (doctortag, fullname, email, password, phone_number, gender, date_of_birth, specialization, country, city,
+				yrs_of_experience, price_per_session, about, license_number, documents, status, submitted_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::date, $8, $9, $10, $11, $12, $13, $14, $15, 'pending', NOW())`,
+		doctorTag, a.FullName, a.Email, passwordHash, a.Phone_no, a.Gender, a.Dob, a.Specialization, a.Country, a.City,
+		a.YearsOfExperience, a.Price, a.About, a.LicenseNumber, string(documents))
+	return err
+}
+
+func (r pgxDoctors) Resubmit(ctx context.Context, doctorTag, tokenHash string, a models.DoctorApplicationReq, passwordHash string) error {
+	documents, err := json.Marshal(a.Documents)
+	if err != nil {
+		return err
+	}
+	tag, err := r.db.Exec(ctx, `UPDATE doctors SET fullname = $1, password = $2, phone_number = $3, gender = $4, date_of_birth = NULLIF($5, '')::date,
+				specialization = $6, country = $7, city = $8, yrs_of_experience = $9, price_per_session = $10, about = $11,
+				license_number = $12, documents = $13, status = 'pending', review_note = NULL, submitted_at = NOW(),
+				resubmit_token_hash = NULL, resubmit_token_expiry = NULL
+			WHERE doctortag = $14 AND status = 'info_requested' AND resubmit_token_hash = $15 AND resubmit_token_expiry > NOW()`,
+		a.FullName, passwordHash, a.Phone_no, a.Gender, a.Dob, a.Specialization, a.Country, a.City, a.YearsOfExperience, a.Price,
+		a.About, a.LicenseNumber, string(documents), doctorTag, tokenHash)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+var doctorProfileTable = database.NewTable("doctors",
+	database.Column{Name: "doctortag"},
+	[]database.Column{
+		{Name: "fullname", Select: "COALESCE(%s, '')"},
+		{Name: "email", Select: "COALESCE(%s, '')"},
+		{Name: "phone_number", Select: "COALESCE(%s, '')"},
+		{Name: "gender", Select: "COALESCE(%s, '')"},
+		{Name: "specialization", Select: "COALESCE(%s, '')"},
+		{Name: "country", Select: "COALESCE(%s, '')"},
+		{Name: "city", Select: "COALESCE(%s, '')"},
+		{Name: "yrs_of_experience", Select: "COALESCE(%s, 0)"},
+		{Name: "price_per_session", Select: "COALESCE(%s, 0)"},
+		{Name: "about", Select: "COALESCE(%s, '')"},
+		{Name: "profile_pic_url", Select: "COALESCE(%s, '')"},
+	},
+	func(d *models.DoctorProfile) []any {
+		return []any{&d.DoctorTag, &d.FullName, &d.Email, &d.Phone_no, &d.Gender, &d.Specialization, &d.Country, &d.City,
+			&d.YearsOfExperience, &d.Price, &d.About, &d.ProfilePicURL}
+	})
+
+func (r pgxDoctors) Profile(ctx context.Context, doctorTag string) (models.DoctorProfile, error) {
+	d, err := doctorProfileTable.Get(ctx, r.db, doctorTag)
+	return d, notFound(err)
+}
+
+func (r pgxDoctors) UpdateProfile(ctx context.Context, patch models.DoctorProfilePatch) error {
+	changes := database.Changes{}
+	database.Set(changes, "fullname", patch.FullName)
+	database.Set(changes, "phone_number", patch.Phone_no)
+	database.Set(changes, "specialization", patch.Specialization)
+	database.Set(changes, "country", patch.Country)
+	database.Set(changes, "city", patch.City)
+	database.Set(changes, "yrs_of_experience", patch.YearsOfExperience)
+	database.Set(changes, "price_per_session", patch.Price)
+	database.Set(changes, "about", patch.About)
+	database.Set(changes, "profile_pic_url", patch.ProfilePicURL)
+	_, err := doctorProfileTable.Patch(ctx, r.db, patch.DoctorTag, changes)
+	return notFound(err)
+}
+
+func (r pgxDoctors) Schedule(ctx context.Context, doctorTag string) (models.DoctorSchedule, error) {
+	s, err := loadSchedule(ctx, r.db, doctorTag)
+	return s, notFound(err)
+}
+
+func (r pgxDoctors) SetSchedule(ctx context.Context, s models.DoctorSchedule) error {
+	return database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "UPDATE doctors SET timezone = $1, slot_minutes = $2, buffer_minutes = $3 WHERE doctortag = $4",
+			s.Timezone, s.SlotMinutes, s.BufferMinutes, s.DoctorTag)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		for _, table := range []string{"doctor_weekly_hours", "doctor_date_overrides", "doctor_blackouts"} {
+			if _, err := tx.Exec(ctx, "DELETE FROM "+table+" WHERE doctortag = $1", s.DoctorTag); err != nil {
+				return err
+			}
+		}
+		for _, h := range s.Weekly {
+			_, err := tx.Exec(ctx, "INSERT INTO doctor_weekly_hours (doctortag, weekday, start_time, end_time) VALUES ($1, $2, $3::time, $4::time)",
+				s.DoctorTag, h.Weekday, h.Start, h.End)
+			if err != nil {
+				return err
+			}
+		}
+		for _, o := range s.Overrides {
+			_, err := tx.Exec(ctx, "INSERT INTO doctor_date_overrides (doctortag, date, start_time, end_time) VALUES ($1, $2::date, $3::time, $4::time)",
+				s.DoctorTag, o.Date, o.Start, o.End)
+			if err != nil {
+				return err
+			}
+		}
+		for _, b := range s.Blackouts {
+			_, err := tx.Exec(ctx, `INSERT INTO doctor_blackouts (doctortag, date, reason) VALUES ($1, $2::date, NULLIF($3, ''))
+					ON CONFLICT (doctortag, date) DO UPDATE SET reason = NULLIF($3, '')`, s.DoctorTag, b.Date, b.Reason)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r pgxDoctors) SetOTP(ctx context.Context, doctorTag, otp string, ttl time.Duration) error {
+	_, err := r.db.Exec(ctx, "UPDATE doctors SET otp = $1, otp_expiry = NOW() + make_interval(secs => $2), otp_attempts = 0 WHERE doctortag = $3",
+		otp, int(ttl.Seconds()), doctorTag)
+	return err
+}
+
+func (r pgxDoctors) ClearOTP(ctx context.Context, doctorTag string) error {
+	_, err := r.db.Exec(ctx, "UPDATE doctors SET otp = NULL, otp_expiry = NULL, otp_attempts = 0 WHERE doctortag = $1", doctorTag)
+	return err
+}
+
+func (r pgxDoctors) CountWrongOTP(ctx context.Context, doctorTag string) (int, error) {
+	var attempts int
+	err := r.db.QueryRow(ctx, "UPDATE doctors SET otp_attempts = otp_attempts + 1 WHERE doctortag = $1 RETURNING otp_attempts", doctorTag).
+		Scan(&attempts)
+	return attempts, notFound(err)
+}
+
+func (r pgxDoctors) ResetOTPAttempts(ctx context.Context, doctorTag string) error {
+	_, err := r.db.Exec(ctx, "UPDATE doctors SET otp_attempts = 0 WHERE doctortag = $1", doctorTag)
+	return err
+}
+
+type pgxPatients struct{ db *pgxpool.Pool }
+
+var patientList = database.ListSpec{
+	Select: `usertag, COALESCE(firstname, ''), COALESCE(lastname, ''), COALESCE(email, ''), COALESCE(phone_no, ''),
+		COALESCE(gender, ''), COALESCE(date_of_birth::text, '')`,
+	From: "users",
+	Key:  "usertag",
+	Sorts: map[string]database.Sort{
+		"firstname": {Expr: "COALESCE(firstname, '')", Type: "text"},
+		"lastname":  {Expr: "COALESCE(lastname, '')", Type: "text"},
+		"email":     {Expr: "COALESCE(email, '')", Type: "text"},
+	},
+	DefaultSort: "lastname",
+	Filters: map[string]database.Filter{
+		"name":           {Expr: "COALESCE(firstname, '') || ' ' || COALESCE(lastname, '')", Op: "contains"},
+		"email":          {Expr: "email", Op: "contains"},
+		"gender":         {Expr: "gender", Op: "ieq"},
+		"state":          {Expr: "state", Op: "ieq"},
+		"email_verified": {Expr: "email_verified", Kind: database.Bool, Op: "="},
+	},
+}
+
+func (r pgxPatients) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, patientList, params, func(p *models.Patient) []any {
+		return []any{&p.UserTag, &p.Firstname, &p.Lastname, &p.Email, &p.Phone_no, &p.Gender, &p.Dob}
+	})
+}
+
+func (r pgxPatients) Get(ctx context.Context, userTag string) (models.PatientIdResp, error) {
+	var patient models.PatientIdResp
+	var firstname, lastname string
+	query := `select u.usertag, u.firstname, u.lastname, u.phone_no, u.gender, u.date_of_birth , ap.doctor_tag, ap.reason, ap.file_url, ap.status
+				from users AS u
+				inner join appointments AS ap
+				on u.usertag = ap.patient_tag
+				where usertag = $1 `
+	err := r.db.QueryRow(ctx, query, userTag).Scan(&patient.UserTag, &firstname, &lastname, &patient.Phone_No, &patient.Gender, &patient.Dob,
+		&patient.Attending_Doctor, &patient.Reason, &patient.File_URL, &patient.Status)
+	patient.Name = firstname + " " + lastname
+	return patient, notFound(err)
+}
+
+func (r pgxPatients) Update(ctx context.Context, patient models.Patient) error {
+	_, err := r.db.Exec(ctx, `UPDATE users SET firstname = $1, lastname = $2, phone_no = $3, date_of_birth = $4 WHERE usertag = $5`,
+		patient.Firstname, patient.Lastname, patient.Phone_no, patient.Dob, patient.UserTag)
+	return err
+}
+
+func (r pgxPatients) Delete(ctx context.Context, userTag string) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM users WHERE usertag = $1", userTag)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r pgxPatients) AccountByEmail(ctx context.Context, email string) (models.PatientRecord, error) {
+	var p models.PatientRecord
+	err := r.db.QueryRow(ctx, `SELECT usertag, email, password, email_verified, COALESCE(otp, ''), otp_expiry
+			FROM users WHERE email = $1`, email).Scan(&p.UserTag, &p.Email, &p.Password, &p.EmailVerified, &p.OTP, &p.OTPExpiry)
+	return p, notFound(err)
+}
+
+func (r pgxPatients) Register(ctx context.Context, p models.PatientProfile, passwordHash, otp string, ttl time.Duration) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO users (usertag, firstname, lastname, email, phone_no, gender, date_of_birth, password, otp, otp_expiry)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::date, $8, $9, NOW() + make_interval(secs => $10))`,
+		p.UserTag, p.Firstname, p.Lastname, p.Email, p.Phone_no, p.Gender, p.Dob, passwordHash, otp, int(ttl.Seconds()))
+	return err
+}
+
+var patientProfileTable = database.NewTable("users",
+	database.Column{Name: "usertag"},
+	[]database.Column{
+		{Name: "firstname", Select: "COALESCE(%s, '')"},
+		{Name: "lastname", Select: "COALESCE(%s, '')"},
+		{Name: "email", Select: "COALESCE(%s, '')"},
+		{Name: "phone_no", Select: "COALESCE(%s, '')"},
+		{Name: "gender", Select: "COALESCE(%s, '')"},
+		{Name: "date_of_birth", Select: "COALESCE(%s::text, '')", Value: "NULLIF(%s, '')::date"},
+		{Name: "state", Select: "COALESCE(%s, '')"},
+		{Name: "delivery_address", Select: "COALESCE(%s, '')"},
+		{Name: "profile_pic_url", Select: "COALESCE(%s, '')"},
+		{Name: "timezone"},
+	},
+	func(p *models.PatientProfile) []any {
+		return []any{&p.UserTag, &p.Firstname, &p.Lastname, &p.Email, &p.Phone_no, &p.Gender, &p.Dob, &p.State,
+			&p.DeliveryAddress, &p.ProfilePicURL, &p.Timezone}
+	})
+
+func (r pgxPatients) Profile(ctx context.Context, userTag string) (models.PatientProfile, error) {
+	p, err := patientProfileTable.Get(ctx, r.db, userTag)
+	return p, notFound(err)
+}
+
+func (r pgxPatients) UpdateProfile(ctx context.Context, patch models.PatientProfilePatch) error {
+	changes := database.Changes{}
+	database.Set(changes, "firstname", patch.Firstname)
+	database.Set(changes, "lastname", patch.Lastname)
+	database.Set(changes, "phone_no", patch.Phone_no)
+	database.Set(changes, "gender", patch.Gender)
+	database.Set(changes, "date_of_birth", patch.Dob)
+	database.Set(changes, "state", patch.State)
+	database.Set(changes, "delivery_address", patch.DeliveryAddress)
+	database.Set(changes, "profile_pic_url", patch.ProfilePicURL)
+	database.Set(changes, "timezone", patch.Timezone)
+	_, err := patientProfileTable.Patch(ctx, r.db, patch.UserTag, changes)
+	return notFound(err)
+}
+
+func (r pgxPatients) SetOTP(ctx context.Context, email, otp string, ttl time.Duration) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET otp = $1, otp_expiry = NOW() + make_interval(secs => $2), otp_attempts = 0 WHERE email = $3",
+		otp, int(ttl.Seconds()), email)
+	return err
+}
+
+func (r pgxPatients) VerifyEmail(ctx context.Context, email string) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET email_verified = TRUE, otp = NULL, otp_expiry = NULL, otp_attempts = 0 WHERE email = $1", email)
+	return err
+}
+
+func (r pgxPatients) SetPassword(ctx context.Context, email, passwordHash string) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET password = $1, otp = NULL, otp_expiry = NULL, otp_attempts = 0 WHERE email = $2", passwordHash, email)
+	return err
+}
+
+func (r pgxPatients) ClearOTP(ctx context.Context, email string) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET otp = NULL, otp_expiry = NULL, otp_attempts = 0 WHERE email = $1", email)
+	return err
+}
+
+func (r pgxPatients) CountWrongOTP(ctx context.Context, email string) (int, error) {
+	var attempts int
+	err := r.db.QueryRow(ctx, "UPDATE users SET otp_attempts = otp_attempts + 1 WHERE email = $1 RETURNING otp_attempts", email).
+		Scan(&attempts)
+	return attempts, notFound(err)
+}
+
+func (r pgxPatients) ResetOTPAttempts(ctx context.Context, email string) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET otp_attempts = 0 WHERE email = $1", email)
+	return err
+}
+
+type pgxPharmacies struct{ db *pgxpool.Pool }
+
+var pharmacyList = database.ListSpec{
+	Select: `pharmacy_id::text, COALESCE(name, ''), COALESCE(address, ''), COALESCE(country, ''), COALESCE(state, ''),
+		COALESCE(about, ''), COALESCE(pharmacy_picture_url, '')`,
+	From: "pharmacies",
+	Key:  "pharmacy_id",
+	Sorts: map[string]database.Sort{
+		"name":    {Expr: "COALESCE(name, '')", Type: "text"},
+		"country": {Expr: "COALESCE(country, '')", Type: "text"},
+	},
+	DefaultSort: "name",
+	Filters: map[string]database.Filter{
+		"name":    {Expr: "name", Op: "contains"},
+		"country": {Expr: "country", Op: "ieq"},
+		"state":   {Expr: "state", Op: "ieq"},
+	},
+}
+
+func (r pgxPharmacies) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, pharmacyList, params, func(p *models.Pharmacy) []any {
+		return []any{&p.PharmacyID, &p.PharmacyName, &p.Address, &p.Country, &p.State, &p.About, &p.Picture_url}
+	})
+}
+
+var pharmacyTable = database.NewTable("pharmacies",
+	database.Column{Name: "pharmacy_id", Select: "%s::text"},
+	[]database.Column{
+		{Name: "name", Select: "COALESCE(%s, '')"},
+		{Name: "address", Select: "COALESCE(%s, '')"},
+		{Name: "country", Select: "COALESCE(%s, '')"},
+		{Name: "state", Select: "COALESCE(%s, '')"},
+		{Name: "about", Select: "COALESCE(%s, '')"},
+		{Name: "pharmacy_picture_url", Select: "COALESCE(%s, '')"},
+	},
+	func(p *models.Pharmacy) []any {
+		return []any{&p.PharmacyID, &p.PharmacyName, &p.Address, &p.Country, &p.State, &p.About, &p.Picture_url}
+	})
+
+func (r pgxPharmacies) Get(ctx context.Context, id string) (models.Pharmacy, error) {
+	pharmacy, err := pharmacyTable.Get(ctx, r.db, id)
+	return pharmacy, notFound(err)
+}
+
+func (r pgxPharmacies) Create(ctx context.Context, pharmacy models.Pharmacy) (models.Pharmacy, error) {
+	return pharmacyTable.Insert(ctx, r.db, pharmacy)
+}
+
+func (r pgxPharmacies) Patch(ctx context.Context, id string, patch models.PharmacyPatch) (models.Pharmacy, error) {
+	changes := database.Changes{}
+	database.Set(changes, "name", patch.PharmacyName)
+	database.Set(changes, "address", patch.Address)
+	database.Set(changes, "country", patch.Country)
+	database.Set(changes, "state", patch.State)
+	database.Set(changes, "about", patch.About)
+	database.Set(changes, "pharmacy_picture_url", patch.Picture_url)
+	pharmacy, err := pharmacyTable.Patch(ctx, r.db, id, changes)
+	return pharmacy, notFound(err)
+}
+
+func (r pgxPharmacies) Delete(ctx context.Context, id string) error {
+	return notFound(pharmacyTable.Delete(ctx, r.db, id))
+}
+
+type pgxHospitals struct{ db *pgxpool.Pool }
+
+var hospitalList = database.ListSpec{
+	Select: `hospital_id::text, COALESCE(name, ''), COALESCE(address, ''), COALESCE(country, ''), COALESCE(state, ''),
+		COALESCE(about, ''), COALESCE(profile_pic_url, '')`,
+	From: "hospitals",
+	Key:  "hospital_id",
+	Sorts: map[string]database.Sort{
+		"name":    {Expr: "COALESCE(name, '')", Type: "text"},
+		"country": {Expr: "COALESCE(country, '')", Type: "text"},
+	},
+	DefaultSort: "name",
+	Filters: map[string]database.Filter{
+		"name":    {Expr: "name", Op: "contains"},
+		"country": {Expr: "country", Op: "ieq"},
+		"state":   {Expr: "state", Op: "ieq"},
+	},
+}
+
+func (r pgxHospitals) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, hospitalList, params, func(h *models.Hospital) []any {
+		return []any{&h.HospitalID, &h.HospitalName, &h.Address, &h.Country, &h.State, &h.About, &h.Picture_url}
+	})
+}
+
+var hospitalTable = database.NewTable("hospitals",
+	database.Column{Name: "hospital_id", Select: "%s::text"},
+	[]database.Column{
+		{Name: "name", Select: "COALESCE(%s, '')"},
+		{Name: "address", Select: "COALESCE(%s, '')"},
+		{Name: "country", Select: "COALESCE(%s, '')"},
+		{Name: "state", Select: "COALESCE(%s, '')"},
+		{Name: "about", Select: "COALESCE(%s, '')"},
+		{Name: "profile_pic_url", Select: "COALESCE(%s, '')"},
+	},
+	func(h *models.Hospital) []any {
+		return []any{&h.HospitalID, &h.HospitalName, &h.Address, &h.Country, &h.State, &h.About, &h.Picture_url}
+	})
+
+func (r pgxHospitals) Get(ctx context.Context, id string) (models.Hospital, error) {
+	hospital, err := hospitalTable.Get(ctx, r.db, id)
+	return hospital, notFound(err)
+}
+
+func (r pgxHospitals) Create(ctx context.Context, hospital models.Hospital) (models.Hospital, error) {
+	return hospitalTable.Insert(ctx, r.db, hospital)
+}
+
+func (r pgxHospitals) Patch(ctx context.Context, id string, patch models.HospitalPatch) (models.Hospital, error) {
+	changes := database.Changes{}
+	database.Set(changes, "name", patch.HospitalName)
+	database.Set(changes, "address", patch.Address)
+	database.Set(changes, "country", patch.Country)
+	database.Set(changes, "state", patch.State)
+	database.Set(changes, "about", patch.About)
+	database.Set(changes, "profile_pic_url", patch.Picture_url)
+	hospital, err := hospitalTable.Patch(ctx, r.db, id, changes)
+	return hospital, notFound(err)
+}
+
+func (r pgxHospitals) Delete(ctx context.Context, id string) error {
+	return notFound(hospitalTable.Delete(ctx, r.db, id))
+}
+
+type pgxInventory struct{ db *pgxpool.Pool }
+
+var inventoryList = database.ListSpec{
+	Select: "product_id::text, COALESCE(name, ''), COALESCE(milligram, ''), COALESCE(price, 0), COALESCE(product_image_url, '')",
+	From:   "inventory",
+	Key:    "product_id",
+	Sorts: map[string]database.Sort{
+		"name":  {Expr: "COALESCE(name, '')", Type: "text"},
+		"price": {Expr: "COALESCE(price, 0)", Type: "numeric"},
+	},
+	DefaultSort: "name",
+	Filters: map[string]database.Filter{
+		"name":      {Expr: "name", Op: "contains"},
+		"min_price": {Expr: "price", Kind: database.Number, Op: ">="},
+		"max_price": {Expr: "price", Kind: database.Number, Op: "<="},
+	},
+}
+
+func (r pgxInventory) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, inventoryList, params, func(i *models.Inventory) []any {
+		return []any{&i.ProductID, &i.ProductName, &i.Milligrams, &i.Price, &i.Product_image_url}
+	})
+}
+
+var inventoryTable = database.NewTable("inventory",
+	database.Column{Name: "product_id", Select: "%s::text"},
+	[]database.Column{
+		{Name: "name", Select: "COALESCE(%s, '')"},
+		{Name: "milligram", Select: "COALESCE(%s, '')"},
+		{Name: "price", Select: "COALESCE(%s, 0)"},
+		{Name: "product_image_url", Select: "COALESCE(%s, '')"},
+	},
+	func(i *models.Inventory) []any {
+		return []any{&i.ProductID, &i.ProductName, &i.Milligrams, &i.Price, &i.Product_image_url}
+	})
+
+func (r pgxInventory) Get(ctx context.Context, id string) (models.Inventory, error) {
+	item, err := inventoryTable.Get(ctx, r.db, id)
+	return item, notFound(err)
+}
+
+func (r pgxInventory) Create(ctx context.Context, item models.Inventory) (models.Inventory, error) {
+	return inventoryTable.Insert(ctx, r.db, item)
+}
+
+func (r pgxInventory) Patch(ctx context.Context, id string, patch models.InventoryPatch) (models.Inventory, error) {
+	changes := database.Changes{}
+	database.Set(changes, "name", patch.ProductName)
+	database.Set(changes, "milligram", patch.Milligrams)
+	database.Set(changes, "price", patch.Price)
+	database.Set(changes, "product_image_url", patch.Product_image_url)
+	item, err := inventoryTable.Patch(ctx, r.db, id, changes)
+	return item, notFound(err)
+}
+
+func (r pgxInventory) Delete(ctx context.Context, id string) error {
+	return notFound(inventoryTable.Delete(ctx, r.db, id))
+}
+
+type pgxOrders struct{ db *pgxpool.Pool }
+
+var orderList = database.ListSpec{
+	Select: "order_id::text, COALESCE(usertag, ''), COALESCE(item_name, ''), COALESCE(quantity, 0), COALESCE(status, '')",
+	From:   "orders",
+	Key:    "order_id",
+	Sorts: map[string]database.Sort{
+		"id":       {Expr: "order_id", Type: "integer"},
+		"status":   {Expr: "COALESCE(status, '')", Type: "text"},
+		"quantity": {Expr: "COALESCE(quantity, 0)", Type: "integer"},
+	},
+	DefaultSort: "-id",
+	Filters: map[string]database.Filter{
+		"status":  {Expr: "status", Op: "=", Values: []string{"pending", "shipped", "delivered", "cancelled"}},
+		"usertag": {Expr: "usertag", Op: "="},
+		"item":    {Expr: "item_name", Op: "contains"},
+	},
+}
+
+func (r pgxOrders) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, orderList, params, func(o *models.Orders) []any {
+		return []any{&o.OrderID, &o.UserTag, &o.ItemName, &o.Quantity, &o.Status}
+	})
+}
+
+func (r pgxOrders) Get(ctx context.Context, id string) (models.Orders, error) {
+	var order models.Orders
+	err := r.db.QueryRow(ctx, "SELECT order_id, usertag, item_name, quantity, status FROM orders WHERE order_id = $1", id).
+		Scan(&order.OrderID, &order.UserTag, &order.ItemName, &order.Quantity, &order.Status)
+	return order, notFound(err)
+}
+
+func (r pgxOrders) Update(ctx context.Context, order models.Orders) error {
+	_, err := r.db.Exec(ctx, `UPDATE orders SET usertag = $1, item_name = $2, quantity = $3, status = $4 WHERE order_id = $5`,
+		order.UserTag, order.ItemName, order.Quantity, order.Status, order.OrderID)
+	return err
+}
+
+type pgxTestCentres struct{ db *pgxpool.Pool }
+
+var testCentreList = database.ListSpec{
+	Select: `center_id::text, COALESCE(name, ''), COALESCE(address, ''), COALESCE(country, ''), COALESCE(state, ''),
+		COALESCE(daily_capacity, 0), COALESCE(about, ''), availability, COALESCE(array_to_string(test_types, ', '), ''),
+		COALESCE(price_per_test, 0), timezone`,
+	From: "test_centres",
+	Key:  "center_id",
+	Sorts: map[string]database.Sort{
+		"name":     {Expr: "COALESCE(name, '')", Type: "text"},
+		"price":    {Expr: "COALESCE(price_per_test, 0)", Type: "numeric"},
+		"capacity": {Expr: "COALESCE(daily_capacity, 0)", Type: "integer"},
+	},
+	DefaultSort: "name",
+	Filters: map[string]database.Filter{
+		"name":      {Expr: "name", Op: "contains"},
+		"country":   {Expr: "country", Op: "ieq"},
+		"state":     {Expr: "state", Op: "ieq"},
+		"test_type": {Expr: "test_types", Op: "any"},
+	},
+}
+
+func (r pgxTestCentres) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, testCentreList, params, func(c *models.TestCentre) []any {
+		return []any{&c.CentreID, &c.CentreName, &c.Address, &c.Country, &c.State, &c.DailyCapacity, &c.About, &c.Availability,
+			&c.TestType, &c.Price, &c.Timezone}
+	})
+}
+
+// test_types is a text[] but reaches the API as one comma separated string.
+var testCentreTable = database.NewTable("test_centres",
+	database.Column{Name: "center_id", Select: "%s::text"},
+	[]database.Column{
+		{Name: "name", Select: "COALESCE(%s, '')"},
+		{Name: "address", Select: "COALESCE(%s, '')"},
+		{Name: "country", Select: "COALESCE(%s, '')"},
+		{Name: "state", Select: "COALESCE(%s, '')"},
+		{Name: "daily_capacity", Select: "COALESCE(%s, 0)"},
+		{Name: "about", Select: "COALESCE(%s, '')"},
+		{Name: "availability"},
+		{Name: "test_types", Select: "COALESCE(array_to_string(%s, ', '), '')", Value: `regexp_split_to_array(%s, '\s*,\s*')`},
+		{Name: "price_per_test", Select: "COALESCE(%s, 0)"},
+		{Name: "timezone"},
+	},
+	func(c *models.TestCentre) []any {
+		return []any{&c.CentreID, &c.CentreName, &c.Address, &c.Country, &c.State, &c.DailyCapacity, &c.About, &c.Availability,
+			&c.TestType, &c.Price, &c.Timezone}
+	})
+
+func (r pgxTestCentres) Get(ctx context.Context, id string) (models.TestCentre, error) {
+	centre, err := testCentreTable.Get(ctx, r.db, id)
+	return centre, notFound(err)
+}
+
+func (r pgxTestCentres) Create(ctx context.Context, centre models.TestCentre) (models.TestCentre, error) {
+	return testCentreTable.Insert(ctx, r.db, centre)
+}
+
+func (r pgxTestCentres) Patch(ctx context.Context, id string, patch models.TestCentrePatch) (models.TestCentre, error) {
+	changes := database.Changes{}
+	database.Set(changes, "name", patch.CentreName)
+	database.Set(changes, "address", patch.Address)
+	database.Set(changes, "country", patch.Country)
+	database.Set(changes, "state", patch.State)
+	database.Set(changes, "daily_capacity", patch.DailyCapacity)
+	database.Set(changes, "about", patch.About)
+	database.Set(changes, "availability", patch.Availability)
+	database.Set(changes, "test_types", patch.TestType)
+	database.Set(changes, "price_per_test", patch.Price)
+	database.Set(changes, "timezone", patch.Timezone)
+	centre, err := testCentreTable.Patch(ctx, r.db, id, changes)
+	return centre, notFound(err)
+}
+
+func (r pgxTestCentres) Delete(ctx context.Context, id string) error {
+	return notFound(testCentreTable.Delete(ctx, r.db, id))
+}
+
+type pgxReviews struct{ db *pgxpool.Pool }
+
+var reviewList = database.ListSpec{
+	Select: "review_id::text, COALESCE(usertag, ''), COALESCE(doctortag, ''), COALESCE(review, ''), COALESCE(star_rating, 0), COALESCE(status, '')",
+	From:   "reviews",
+	Key:    "review_id",
+	Sorts: map[string]database.Sort{
+		"id":     {Expr: "review_id", Type: "integer"},
+		"rating": {Expr: "COALESCE(star_rating, 0)", Type: "integer"},
+	},
+	DefaultSort: "-id",
+	Filters: map[string]database.Filter{
+		"status":     {Expr: "status", Op: "=", Values: []string{"approved", "pending"}},
+		"doctor":     {Expr: "doctortag", Op: "="},
+		"usertag":    {Expr: "usertag", Op: "="},
+		"min_rating": {Expr: "star_rating", Kind: database.Int, Op: ">="},
+	},
+}
+
+func (r pgxReviews) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, reviewList, params, func(rv *models.Reviews) []any {
+		return []any{&rv.ReviewID, &rv.UserTag, &rv.DoctorTag, &rv.Review, &rv.Rating, &rv.Status}
+	})
+}
+
+func (r pgxReviews) Get(ctx context.Context, id string) (models.Reviews, error) {
+	var review models.Reviews
+	err := r.db.QueryRow(ctx, "SELECT review_id, usertag, doctortag, review, star_rating, status FROM reviews WHERE review_id = $1", id).
+		Scan(&review.ReviewID, &review.UserTag, &review.DoctorTag, &review.Review, &review.Rating, &review.Status)
+	return review, notFound(err)
+}
+
+func (r pgxReviews) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM reviews WHERE review_id = $1", id)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+type pgxAdmins struct{ db *pgxpool.Pool }
+
+var adminList = database.ListSpec{
+	Select: `admintag, COALESCE(firstname, ''), COALESCE(lastname, ''), COALESCE(email, ''), role, status,
+		password_reset_required, COALESCE(created_at, NOW())`,
+	From: "admins",
+	Key:  "admintag",
+	Sorts: map[string]database.Sort{
+		"created_at": {Expr: "COALESCE(created_at, 'epoch')", Type: "timestamp"},
+		"email":      {Expr: "COALESCE(email, '')", Type: "text"},
+	},
+	DefaultSort: "created_at",
+	Filters: map[string]database.Filter{
+		"role":   {Expr: "role", Op: "="},
+		"status": {Expr: "status", Op: "=", Values: []string{"invited", "active", "suspended"}},
+		"email":  {Expr: "email", Op: "contains"},
+	},
+}
+
+func (r pgxAdmins) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, adminList, params, func(a *models.AdminAccount) []any {
+		return []any{&a.AdminTag, &a.Firstname, &a.Lastname, &a.Email, &a.Role, &a.Status, &a.PasswordResetRequired, &a.CreatedAt}
+	})
+}
+
+func (r pgxAdmins) Profile(ctx context.Context, adminTag string) (models.AdminProfile, error) {
+	admin := models.AdminProfile{AdminTag: adminTag}
+	err := r.db.QueryRow(ctx, "SELECT email, firstname, lastname, profile_pic_url, timezone from admins WHERE admintag = $1", adminTag).
+		Scan(&admin.Email, &admin.Firstname, &admin.Lastname, &admin.ProfilePicURL, &admin.Timezone)
+	return admin, notFound(err)
+}
+
+func (r pgxAdmins) UpdateProfile(ctx context.Context, profile models.AdminProfile) error {
+	_, err := r.db.Exec(ctx, `UPDATE admins SET firstname = $1, lastname = $2, profile_pic_url = $3 , email = $4, timezone = $5 where admintag = $6`,
+		profile.Firstname, profile.Lastname, profile.ProfilePicURL, profile.Email, profile.Timezone, profile.AdminTag)
+	return err
+}
+
+const adminRecordColumns = `admintag, COALESCE(firstname, ''), COALESCE(lastname, ''), COALESCE(email, ''), COALESCE(role, ''), status,
+		password_reset_required, COALESCE(created_at, NOW()), COALESCE(profile_pic_url, ''), timezone, password, mfa_method,
+		COALESCE(totp_secret, ''), COALESCE(totp_pending_secret, ''), totp_last_counter, COALESCE(otp, ''), otp_expiry, otp_attempts,
+		otp_sent_at, COALESCE(reset_token_hash, ''), reset_token_expiry, COALESCE(invite_token_hash, ''), invite_expiry`
+
+func adminRecordFields(a *models.AdminRecord) []any {
+	return []any{&a.AdminTag, &a.Firstname, &a.Lastname, &a.Email, &a.Role, &a.Status, &a.PasswordResetRequired, &a.CreatedAt,
+		&a.ProfilePicURL, &a.Timezone, &a.Password, &a.MFAMethod, &a.TOTPSecret, &a.TOTPPendingSecret, &a.TOTPLastCounter, &a.OTP,
+		&a.OTPExpiry, &a.OTPAttempts, &a.OTPSentAt, &a.ResetTokenHash, &a.ResetTokenExpiry, &a.InviteTokenHash, &a.InviteExpiry}
+}
+
+func (r pgxAdmins) record(ctx context.Context, where string, arg any) (models.AdminRecord, error) {
+	var admin models.AdminRecord
+	err := r.db.QueryRow(ctx, "SELECT "+adminRecordColumns+" FROM admins WHERE "+where, arg).Scan(adminRecordFields(&admin)...)
+	return admin, notFound(err)
+}
+
+func (r pgxAdmins) Get(ctx context.Context, adminTag string) (models.AdminRecord, error) {
+	return r.record(ctx, "admintag = $1", adminTag)
+}
+
+func (r pgxAdmins) GetByEmail(ctx context.Context, email string) (models.AdminRecord, error) {
+	return r.record(ctx, "email = $1", email)
+}
+
+func (r pgxAdmins) GetByInvite(ctx context.Context, tokenHash string) (models.AdminRecord, error) {
+	return r.record(ctx, "invite_token_hash = $1 AND status = 'invited'", tokenHash)
+}
+
+func (r pgxAdmins) Invite(ctx context.Context, admin models.AdminRecord, inviteTTL time.Duration) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO admins (admintag, firstname, lastname, email, password, role, status, invite_token_hash, invite_expiry)
+			VALUES ($1, $2, $3, $4, '', $5, 'invited', $6, NOW() + make_interval(secs => $7))`,
+		admin.AdminTag, admin.Firstname, admin.Lastname, admin.Email, admin.Role, admin.InviteTokenHash, int(inviteTTL.Seconds()))
+	return err
+}
+
+func (r pgxAdmins) AcceptInvite(ctx context.Context, adminTag, passwordHash string) error {
+	_, err := r.db.Exec(ctx, `UPDATE admins SET password = $1, status = 'active', invite_token_hash = NULL, invite_expiry = NULL
+			WHERE admintag = $2`, passwordHash, adminTag)
+	return err
+}
+
+func (r pgxAdmins) SetStatus(ctx context.Context, adminTag, status string) error {
+	tag, err := r.db.Exec(ctx, "UPDATE admins SET status = $1 WHERE admintag = $2 AND status <> 'invited'", status, adminTag)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r pgxAdmins) SetRole(ctx context.Context, adminTag, role string) error {
+	tag, err := r.db.Exec(ctx, "UPDATE admins SET role = $1 WHERE admintag = $2", role, adminTag)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r pgxAdmins) RequirePasswordReset(ctx context.Context, adminTag string) (string, error) {
+	var email string
+	err := r.db.QueryRow(ctx, `UPDATE admins SET password_reset_required = TRUE WHERE admintag = $1 AND status <> 'invited'
+			RETURNING COALESCE(email, '')`, adminTag).Scan(&email)
+	return email, notFound(err)
+}
+
+func (r pgxAdmins) SetOTP(ctx context.Context, adminTag, otp string, ttl time.Duration, emailed bool) error {
+	_, err := r.db.Exec(ctx, `UPDATE admins SET otp = $1, otp_expiry = NOW() + make_interval(secs => $2), otp_attempts = 0,
+			otp_sent_at = CASE WHEN $3 THEN NOW() ELSE otp_sent_at END
+			WHERE admintag = $4`, otp, int(ttl.Seconds()), emailed, adminTag)
+	return err
+}
+
+func (r pgxAdmins) ClearOTP(ctx context.Context, adminTag string) error {
+	_, err := r.db.Exec(ctx, "UPDATE admins SET otp = NULL, otp_expiry = NULL, otp_attempts = 0 WHERE admintag = $1", adminTag)
+	return err
+}
+
+func (r pgxAdmins) CountWrongOTP(ctx context.Context, adminTag string) (int, error) {
+	var attempts int
+	err := r.db.QueryRow(ctx, "UPDATE admins SET otp_attempts = otp_attempts + 1 WHERE admintag = $1 RETURNING otp_attempts", adminTag).
+		Scan(&attempts)
+	return attempts, notFound(err)
+}
+
+func (r pgxAdmins) ResetOTPAttempts(ctx context.Context, adminTag string) error {
+	_, err := r.db.Exec(ctx, "UPDATE admins SET otp_attempts = 0 WHERE admintag = $1", adminTag)
+	return err
+}
+
+func (r pgxAdmins) SetResetToken(ctx context.Context, adminTag, tokenHash string, ttl time.Duration) error {
+	_, err := r.db.Exec(ctx, `UPDATE admins SET otp = NULL, otp_expiry = NULL, otp_attempts = 0,
+			reset_token_hash = $1, reset_token_expiry = NOW() + make_interval(secs => $2) WHERE admintag = $3`,
+		tokenHash, int(ttl.Seconds()), adminTag)
+	return err
+}
+
+func (r pgxAdmins) PasswordHashes(ctx context.Context, adminTag string, depth int) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT password FROM admins WHERE admintag = $1 AND password <> ''
+			UNION ALL
+			(SELECT password_hash FROM admin_password_history WHERE admintag = $1 ORDER BY created_at DESC LIMIT $2)`,
+		adminTag, depth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+func (r pgxAdmins) ResetPassword(ctx context.Context, adminTag, tokenHash, passwordHash string, depth int) error {
+	return database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		var old string
+		err := tx.QueryRow(ctx, "SELECT password FROM admins WHERE admintag = $1 FOR UPDATE", adminTag).Scan(&old)
+		if err != nil {
+			return notFound(err)
+		}
+		tag, err := tx.Exec(ctx, `UPDATE admins SET password = $1, password_reset_required = FALSE, reset_token_hash = NULL, reset_token_expiry = NULL
+				WHERE admintag = $2 AND reset_token_hash = $3`, passwordHash, adminTag, tokenHash)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() != 1 {
+			return ErrNotFound
+		}
+
+		if old == "" {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO admin_password_history (admintag, password_hash) VALUES ($1, $2)", adminTag, old); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM admin_password_history WHERE admintag = $1 AND id NOT IN
+				(SELECT id FROM admin_password_history WHERE admintag = $1 ORDER BY created_at DESC LIMIT $2)`, adminTag, depth)
+		return err
+	})
+}
+
+type pgxMFA struct{ db *pgxpool.Pool }
+
+func (r pgxMFA) SetPendingTOTP(ctx context.Context, adminTag, secret string) error {
+	_, err := r.db.Exec(ctx, "UPDATE admins SET totp_pending_secret = $1 WHERE admintag = $2", secret, adminTag)
+	return err
+}
+
+func (r pgxMFA) EnableTOTP(ctx context.Context, adminTag string, counter int64) error {
+	_, err := r.db.Exec(ctx, `UPDATE admins SET totp_secret = totp_pending_secret, totp_pending_secret = NULL, totp_last_counter = $1,
+			mfa_method = 'totp' WHERE admintag = $2`, counter, adminTag)
+	return err
+}
+
+func (r pgxMFA) DisableTOTP(ctx context.Context, adminTag string) error {
+	return database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE admins SET totp_secret = NULL, totp_last_counter = NULL, mfa_method = 'email'
+				WHERE admintag = $1`, adminTag)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, "DELETE FROM admin_recovery_codes WHERE admintag = $1", adminTag)
+		return err
+	})
+}
+
+func (r pgxMFA) UseTOTPCounter(ctx context.Context, adminTag string, counter int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE admins SET totp_last_counter = $1
+			WHERE admintag = $2 AND (totp_last_counter IS NULL OR totp_last_counter < $1)`, counter, adminTag)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r pgxMFA) ReplaceRecoveryCodes(ctx context.Context, adminTag string, codeHashes []string) error {
+	return database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM admin_recovery_codes WHERE admintag = $1", adminTag); err != nil {
+			return err
+		}
+		for _, hash := range codeHashes {
+			if _, err := tx.Exec(ctx, "INSERT INTO admin_recovery_codes (admintag, code_hash) VALUES ($1, $2)", adminTag, hash); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r pgxMFA) UseRecoveryCode(ctx context.Context, adminTag, codeHash string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE admin_recovery_codes SET used_at = NOW()
+			WHERE admintag = $1 AND code_hash = $2 AND used_at IS NULL`, adminTag, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r pgxMFA) RecoveryCodesLeft(ctx context.Context, adminTag string) (int, error) {
+	var left int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM admin_recovery_codes WHERE admintag = $1 AND used_at IS NULL", adminTag).Scan(&left)
+	return left, err
+}
+
+// Policy falls back to the defaults while security_settings has no row.
+func (r pgxMFA) Policy(ctx context.Context) (models.MFAPolicy, error) {
+	var policy models.MFAPolicy
+	err := r.db.QueryRow(ctx, "SELECT require_totp_for_god_eye FROM security_settings WHERE id = 1").Scan(&policy.RequireTOTPForGodEye)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return policy, nil
+	}
+	return policy, err
+}
+
+func (r pgxMFA) SetPolicy(ctx context.Context, policy models.MFAPolicy) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO security_settings (id, require_totp_for_god_eye, updated_at) VALUES (1, $1, NOW())
+			ON CONFLICT (id) DO UPDATE SET require_totp_for_god_eye = $1, updated_at = NOW()`, policy.RequireTOTPForGodEye)
+	return err
+}
+
+type pgxLockouts struct{ db *pgxpool.Pool }
+
+var lockoutList = database.ListSpec{
+	Select: `scope, key, failed_count, locked_until, last_failed_at,
+		locked_until IS NOT NULL AND locked_until > NOW()`,
+	From:  "auth_lockouts",
+	Where: "last_failed_at > NOW() - INTERVAL '24 hours' OR locked_until > NOW()",
+	Key:   "scope || ':' || key",
+	Sorts: map[string]database.Sort{
+		"last_failed_at": {Expr: "last_failed_at", Type: "timestamp"},
+		"failed_count":   {Expr: "failed_count", Type: "integer"},
+	},
+	DefaultSort: "-last_failed_at",
+	Filters: map[string]database.Filter{
+		"scope": {Expr: "scope", Op: "=", Values: []string{"admin", "patient", "doctor", "ip"}},
+	},
+}
+
+func (r pgxLockouts) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, lockoutList, params, func(l *models.AuthLockout) []any {
+		return []any{&l.Scope, &l.Key, &l.FailedCount, &l.LockedUntil, &l.LastFailedAt, &l.Locked}
+	})
+}
+
+func (r pgxLockouts) Locked(ctx context.Context, scope, key string) (bool, error) {
+	var locked bool
+	err := r.db.QueryRow(ctx, "SELECT locked_until IS NOT NULL AND locked_until > NOW() FROM auth_lockouts WHERE scope = $1 AND key = $2",
+		scope, key).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return locked, err
+}
+
+func (r pgxLockouts) RecordFailure(ctx context.Context, scope, key string) (int, error) {
+	var failures int
+	err := r.db.QueryRow(ctx, `INSERT INTO auth_lockouts (scope, key, failed_count, last_failed_at) VALUES ($1, $2, 1, NOW())
+			ON CONFLICT (scope, key) DO UPDATE SET
+				failed_count = CASE WHEN auth_lockouts.last_failed_at < NOW() - INTERVAL '24 hours' THEN 1
+					ELSE auth_lockouts.failed_count + 1 END,
+				last_failed_at = NOW()
+			RETURNING failed_count`, scope, key).Scan(&failures)
+	return failures, err
+}
+
+func (r pgxLockouts) Lock(ctx context.Context, scope, key string, d time.Duration) error {
+	_, err := r.db.Exec(ctx, "UPDATE auth_lockouts SET locked_until = NOW() + make_interval(secs => $1) WHERE scope = $2 AND key = $3",
+		int(d.Seconds()), scope, key)
+	return err
+}
+
+func (r pgxLockouts) Clear(ctx context.Context, scope, key string) (models.AuthLockout, error) {
+	lockout := models.AuthLockout{Scope: scope, Key: key}
+	err := r.db.QueryRow(ctx, "DELETE FROM auth_lockouts WHERE scope = $1 AND key = $2 RETURNING failed_count, locked_until, last_failed_at",
+		scope, key).Scan(&lockout.FailedCount, &lockout.LockedUntil, &lockout.LastFailedAt)
+	return lockout, notFound(err)
+}
+
+type pgxSessions struct{ db *pgxpool.Pool }
+
+func (r pgxSessions) Create(ctx context.Context, session models.Session, ttl time.Duration) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO sessions (session_id, subject, account_type, role, refresh_token_hash, ip, user_agent, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NOW() + make_interval(secs => $8))`,
+		session.SessionID, session.Subject, session.AccountType, session.Role, session.RefreshTokenHash, session.IP, session.UserAgent,
+		int(ttl.Seconds()))
+	return err
+}
+
+func (r pgxSessions) Rotate(ctx context.Context, sessionID, oldHash, newHash string, client models.ClientInfo) (models.Session, error) {
+	session := models.Session{SessionID: sessionID, ClientInfo: client}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return session, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `SELECT subject, account_type, role, refresh_token_hash FROM sessions
+			WHERE session_id = $1 AND revoked_at IS NULL AND expires_at > NOW() FOR UPDATE`, sessionID).
+		Scan(&session.Subject, &session.AccountType, &session.Role, &session.RefreshTokenHash)
+	if err != nil {
+		return session, notFound(err)
+	}
+
+	// the revocation is committed even though the rotation fails
+	if session.RefreshTokenHash != oldHash {
+		if _, err := tx.Exec(ctx, "UPDATE sessions SET revoked_at = NOW() WHERE session_id = $1", sessionID); err != nil {
+			return session, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return session, err
+		}
+		return session, ErrTokenReused
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE sessions SET refresh_token_hash = $1, last_used_at = NOW(), ip = $2, user_agent = $3
+			WHERE session_id = $4`, newHash, client.IP, client.UserAgent, sessionID)
+	if err != nil {
+		return session, err
+	}
+	session.RefreshTokenHash = newHash
+	return session, tx.Commit(ctx)
+}
+
+func (r pgxSessions) Active(ctx context.Context, sessionID string) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(ctx, "SELECT revoked_at IS NULL AND expires_at > NOW() FROM sessions WHERE session_id = $1", sessionID).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return active, err
+}
+
+func (r pgxSessions) Revoke(ctx context.Context, sessionID string) error {
+	_, err := r.db.Exec(ctx, "UPDATE sessions SET revoked_at = NOW() WHERE session_id = $1 AND revoked_at IS NULL", sessionID)
+	return err
+}
+
+func (r pgxSessions) RevokeAll(ctx context.Context, accountType, subject string) error {
+	_, err := r.db.Exec(ctx, "UPDATE sessions SET revoked_at = NOW() WHERE account_type = $1 AND subject = $2 AND revoked_at IS NULL",
+		accountType, subject)
+	return err
+}
+
+type pgxReports struct{ db *pgxpool.Pool }
+
+func (r pgxReports) Dashboard(ctx context.Context) (models.DashboardSummary, error) {
+	var summary models.DashboardSummary
+	queries := []struct {
+		query string
+		dest  *int
+	}{
+		{"SELECT COUNT(*) FROM users", &summary.PatientsCount},
+		{"SELECT COUNT(*) FROM doctors WHERE status = 'approved'", &summary.DoctorsCount},
+		{"SELECT COUNT(*) FROM appointments", &summary.AppointmentsCount},
+		{"SELECT COUNT(*) FROM orders", &summary.OrdersCount},
+		{"SELECT COUNT(*) FROM doctors WHERE status = 'pending'", &summary.DoctorRequests},
+	}
+	for _, q := range queries {
+		if err := r.db.QueryRow(ctx, q.query).Scan(q.dest); err != nil {
+			return summary, fmt.Errorf("%s: %w", q.query, err)
+		}
+	}
+	return summary, nil
+}
+
+func (r pgxReports) Payments(ctx context.Context, month, year string) (models.AnalyticsResp, error) {
+	analytics := models.AnalyticsResp{Metric: "payments", Month: month, Year: year}
+	query := `SELECT COALESCE(SUM(amount), 0), COUNT(*) FROM payments WHERE EXTRACT(MONTH FROM payment_date) = $1 AND 
+			EXTRACT(YEAR FROM payment_date) = $2 AND status = 'completed'`
+	err := r.db.QueryRow(ctx, query, month, year).Scan(&analytics.Total_amount, &analytics.Payment_count)
+	if err != nil {
+		return analytics, err
+	}
+	if analytics.Payment_count > 0 {
+		analytics.Average_payment = analytics.Total_amount / float64(analytics.Payment_count)
+	}
+	return analytics, nil
+}
+
+type pgxAudit struct{ db *pgxpool.Pool }
+
+var auditLogList = database.ListSpec{
+	Select: `id, COALESCE(actor_admintag, ''), COALESCE(actor_role, ''), action, entity_type, COALESCE(entity_id, ''),
+		before, after, COALESCE(ip, ''), COALESCE(request_id, ''), created_at`,
+	From: "audit_logs",
+	Key:  "id",
+	Sorts: map[string]database.Sort{
+		"created_at": {Expr: "created_at", Type: "timestamp"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]database.Filter{
+		"actor":       {Expr: "actor_admintag", Op: "="},
+		"action":      {Expr: "action", Op: "="},
+		"entity_type": {Expr: "entity_type", Op: "="},
+		"entity_id":   {Expr: "entity_id", Op: "="},
+		"from":        {Expr: "created_at", Kind: database.Time, Op: ">="},
+		"to":          {Expr: "created_at", Kind: database.Time, Op: "<"},
+	},
+}
+
+func (r pgxAudit) List(ctx context.Context, params models.ListParams) (models.Page, error) {
+	return database.List(ctx, r.db, auditLogList, params, func(e *models.AuditLog) []any {
+		return []any{&e.ID, &e.ActorTag, &e.ActorRole, &e.Action, &e.EntityType, &e.EntityID, &e.Before, &e.After, &e.IP,
+			&e.RequestID, &e.CreatedAt}
+	})
+}
+
+func (r pgxAudit) Snapshot(ctx context.Context, table, keyColumn, id string) (map[string]any, error) {
+	var row map[string]any
+	query := fmt.Sprintf("SELECT to_jsonb(t) FROM %s t WHERE %s::text = $1", table, keyColumn)
+	err := r.db.QueryRow(ctx, query, id).Scan(&row)
+	return row, notFound(err)
+}
+
+func (r pgxAudit) Append(ctx context.Context, entry models.AuditLog) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO audit_logs (actor_admintag, actor_role, action, entity_type, entity_id, before, after, ip, request_id)
+			VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''))`,
+		entry.ActorTag, entry.ActorRole, entry.Action, entry.EntityType, entry.EntityID, auditJSON(entry.Before), auditJSON(entry.After),
+		entry.IP, entry.RequestID)
+	return err
+}
+
+func auditJSON(v map[string]any) any {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return string(b)
+}
+
+type pgxReminders struct{ db *pgxpool.Pool }
+
+func (r pgxReminders) Due(ctx context.Context, lower, upper time.Duration) ([]models.DueReminder, error) {
+	rows, err := r.db.Query(ctx, `SELECT a.appointment_id::text, a.patient_tag, TRIM(COALESCE(u.firstname, '') || ' ' || COALESCE(u.lastname, '')),
+				a.doctor_tag, COALESCE(d.fullname, ''), a.scheduled_at
+			FROM appointments a
+			JOIN users u ON a.patient_tag = u.usertag
+			JOIN doctors d ON a.doctor_tag = d.doctortag
+			WHERE a.status = 'confirmed'
+			AND a.scheduled_at > NOW() + make_interval(secs => $1)
+			AND a.scheduled_at <= NOW() + make_interval(secs => $2)
+			AND (SELECT COUNT(*) FROM appointment_reminders r
+				WHERE r.appointment_id = a.appointment_id AND r.offset_minutes = $3 AND r.status = 'sent') < 2`,
+		int(lower.Seconds()), int(upper.Seconds()), int(upper.Minutes()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var due []models.DueReminder
+	for rows.Next() {
+		var d models.DueReminder
+		if err := rows.Scan(&d.AppointmentID, &d.PatientTag, &d.PatientName, &d.DoctorTag, &d.DoctorName, &d.ScheduledAt); err != nil {
+			return due, err
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+func (r pgxReminders) Claim(ctx context.Context, appointmentID, recipient string, offset time.Duration) (bool, error) {
+	tag, err := r.db.Exec(ctx, `INSERT INTO appointment_reminders (appointment_id, recipient, offset_minutes, status)
+			VALUES ($1::int, $2, $3, 'sent')
+			ON CONFLICT (appointment_id, recipient, offset_minutes) DO UPDATE SET status = 'sent', sent_at = NOW()
+			WHERE appointment_reminders.status = 'failed'`, appointmentID, recipient, int(offset.Minutes()))
+	return err == nil && tag.RowsAffected() == 1, err
+}
+
+func (r pgxReminders) Fail(ctx context.Context, appointmentID, recipient string, offset time.Duration) error {
+	_, err := r.db.Exec(ctx, `UPDATE appointment_reminders SET status = 'failed'
+			WHERE appointment_id = $1::int AND recipient = $2 AND offset_minutes = $3`, appointmentID, recipient, int(offset.Minutes()))
+	return err
+}
+
+type pgxAccounts struct{ db *pgxpool.Pool }
+
+// accountTables maps an account type to its table and key column. Staff of
+// every role are in admins.
+var accountTables = map[string][2]string{
+	"patient": {"users", "usertag"},
+	"doctor":  {"doctors", "doctortag"},
+	"admin":   {"admins", "admintag"},
+}
+
+func accountTable(accountType string) (string, string) {
+	t, ok := accountTables[accountType]
+	if !ok {
+		t = accountTables["admin"]
+	}
+	return t[0], t[1]
+}
+
+func (r pgxAccounts) Contact(ctx context.Context, accountType, subject string) (models.Contact, error) {
+	var c models.Contact
+	var query string
+	switch table, key := accountTable(accountType); table {
+	case "users":
+		query = "SELECT COALESCE(email, ''), COALESCE(phone_no, '') FROM users WHERE usertag = $1"
+	case "doctors":
+		query = "SELECT COALESCE(email, ''), COALESCE(phone_number, '') FROM doctors WHERE doctortag = $1"
+	default:
+		query = "SELECT COALESCE(email, ''), '' FROM " + table + " WHERE " + key + " = $1"
+	}
+	if err := r.db.QueryRow(ctx, query, subject).Scan(&c.Email, &c.Phone); err != nil {
+		return c, notFound(err)
+	}
+
+	rows, err := r.db.Query(ctx, "SELECT token FROM push_devices WHERE account_type = $1 AND subject = $2", accountType, subject)
+	if err != nil {
+		return c, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return c, err
+		}
+		c.Devices = append(c.Devices, token)
+	}
+	return c, rows.Err()
+}
+
+func (r pgxAccounts) Timezone(ctx context.Context, accountType, subject string) (string, error) {
+	table, key := accountTable(accountType)
+	var tz string
+	err := r.db.QueryRow(ctx, "SELECT timezone FROM "+table+" WHERE "+key+" = $1", subject).Scan(&tz)
+	return tz, notFound(err)
+}
+
+type pgxNotifications struct{ db *pgxpool.Pool }
+
+func (r pgxNotifications) Preferences(ctx context.Context, accountType, subject, event string) (map[string]bool, error) {
+	prefs := map[string]bool{}
+	rows, err := r.db.Query(ctx, `SELECT channel, enabled FROM notification_preferences
+			WHERE account_type = $1 AND subject = $2 AND event = $3`, accountType, subject, event)
+	if err != nil {
+		return prefs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var channel string
+		var enabled bool
+		if err := rows.Scan(&channel, &enabled); err != nil {
+			return prefs, err
+		}
+		prefs[channel] = enabled
+	}
+	return prefs, rows.Err()
+}
+
+func (r pgxNotifications) SetPreferences(ctx context.Context, accountType, subject string, preferences []models.NotificationPreference) error {
+	return database.WithTx(ctx, r.db, func(tx pgx.Tx) error {
+		for _, p := range preferences {
+			_, err := tx.Exec(ctx, `INSERT INTO notification_preferences (account_type, subject, event, channel, enabled, updated_at)
+					VALUES ($1, $2, $3, $4, $5, NOW())
+					ON CONFLICT (account_type, subject, event, channel) DO UPDATE SET enabled = $5, updated_at = NOW()`,
+				accountType, subject, p.Event, p.Channel, p.Enabled)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r pgxNotifications) RegisterDevice(ctx context.Context, accountType string, d models.PushDevice) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO push_devices (token, account_type, subject, platform) VALUES ($1, $2, $3, NULLIF($4, ''))
+			ON CONFLICT (token) DO UPDATE SET account_type = $2, subject = $3, platform = NULLIF($4, ''), last_seen_at = NOW()`,
+		d.Token, accountType, d.Subject, d.Platform)
+	return err
+}
+
+func (r pgxNotifications) RemoveDevice(ctx context.Context, accountType string, d models.PushDevice) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM push_devices WHERE token = $1 AND account_type = $2 AND subject = $3", d.Token, accountType, d.Subject)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+type pgxSearch struct{ db *pgxpool.Pool }
+
+// searchGroup is one kind of record the admin search covers. Document is
+// the text that is matched and highlighted; it must stay identical to the
+// expression the search indexes in migration 0019 are built on, or they go unused.
+type searchGroup struct {
+	Name     string
+	From     string
+	Key      string
+	Title    string
+	Subtitle string
+	Document string
+}
+
+// searchGroups are listed in the order they appear in the response.
+var searchGroups = []searchGroup{
+	{
+		Name:     "patients",
+		From:     "users",
+		Key:      "usertag",
+		Title:    "COALESCE(firstname, '') || ' ' || COALESCE(lastname, '')",
+		Subtitle: "COALESCE(email, '')",
+		Document: "COALESCE(firstname, '') || ' ' || COALESCE(lastname, '') || ' ' || COALESCE(email, '') || ' ' || COALESCE(phone_no, '')",
+	},
+	{
+		Name:     "doctors",
+		From:     "doctors",
+		Key:      "doctortag",
+		Title:    "COALESCE(fullname, '')",
+		Subtitle: "COALESCE(specialization, '')",
+		Document: "COALESCE(fullname, '') || ' ' || COALESCE(specialization, '') || ' ' || COALESCE(city, '')",
+	},
+	{
+		Name:     "inventory",
+		From:     "inventory",
+		Key:      "product_id",
+		Title:    "COALESCE(name, '')",
+		Subtitle: "COALESCE(milligram, '')",
+		Document: "COALESCE(name, '')",
+	},
+	{
+		Name:     "hospitals",
+		From:     "hospitals",
+		Key:      "hospital_id",
+		Title:    "COALESCE(name, '')",
+		Subtitle: "COALESCE(state, '')",
+		Document: "COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')",
+	},
+	{
+		Name:     "pharmacies",
+		From:     "pharmacies",
+		Key:      "pharmacy_id",
+		Title:    "COALESCE(name, '')",
+		Subtitle: "COALESCE(state, '')",
+		Document: "COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')",
+	},
+	{
+		Name:     "test_centres",
+		From:     "test_centres",
+		Key:      "center_id",
+		Title:    "COALESCE(name, '')",
+		Subtitle: "COALESCE(state, '')",
+		Document: "COALESCE(name, '') || ' ' || COALESCE(state, '') || ' ' || COALESCE(country, '')",
+	},
+}
+
+// query ranks full-text matches above fuzzy ones, so an exact word beats a
+// near-miss, while the trigram match still finds misspelt names and partial
+// emails or phone numbers. Highlights mark the full-text matches only.
+func (g searchGroup) query() string {
+	return fmt.Sprintf(`SELECT (%[2]s)::text, %[3]s, %[4]s,
+			(ts_rank(to_tsvector('simple', %[5]s), q) + similarity(%[5]s, $1))::float8 AS rank,
+			ts_headline('simple', %[5]s, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+		FROM %[1]s, websearch_to_tsquery('simple', $1) q
+		WHERE to_tsvector('simple', %[5]s) @@ q OR %[5]s %% $1 OR %[5]s ILIKE '%%' || $2 || '%%'
+		ORDER BY rank DESC, 1
+		LIMIT $3`, g.From, g.Key, g.Title, g.Subtitle, g.Document)
+}
+
+func searchGroupNames() []string {
+	names := make([]string, 0, len(searchGroups))
+	for _, g := range searchGroups {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+func (r pgxSearch) Groups() []string {
+	return searchGroupNames()
+}
+
+func (r pgxSearch) Find(ctx context.Context, group, term string, limit int) ([]models.SearchHit, error) {
+	hits := []models.SearchHit{}
+	for _, g := range searchGroups {
+		if g.Name != group {
+			continue
+		}
+		like := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+		rows, err := r.db.Query(ctx, g.query(), term, like, limit)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var hit models.SearchHit
+			if err := rows.Scan(&hit.ID, &hit.Title, &hit.Subtitle, &hit.Rank, &hit.Highlight); err != nil {
+				return nil, err
+			}
+			hits = append(hits, hit)
+		}
+		return hits, rows.Err()
+	}
+	return nil, fmt.Errorf("unknown search group %q", group)
+}
@@ -0,0 +1,360 @@
+// Package repository holds the data access behind the servers, one interface
+// per aggregate, with a Postgres implementation for the app and an in-memory
+// one for tests.
+package repository
+
+import (
+	"context"
+	"errors"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/responses"
+	"time"
+)
+
+// ErrNotFound is returned by lookups that match no row. Servers usually
+// replace it with a message naming what was missing; if one does not, it is
+// still answered with a 404.
+var ErrNotFound error = apperrors.NotFound(responses.RECORD_NOT_FOUND)
+
+// ErrTokenReused is returned by Sessions.Rotate for a replayed refresh token.
+var ErrTokenReused = errors.New("refresh token reused")
+
+// Repositories bundles one repository per aggregate.
+type Repositories struct {
+	Appointments  Appointments
+	Calendar      Calendar
+	Reminders     Reminders
+	Doctors       Doctors
+	Patients      Patients
+	Pharmacies    Pharmacies
+	Hospitals     Hospitals
+	Inventory     Inventory
+	Orders        Orders
+	TestCentres   TestCentres
+	Reviews       Reviews
+	Admins        Admins
+	MFA           MFA
+	Lockouts      Lockouts
+	Sessions      Sessions
+	Reports       Reports
+	Audit         AuditLog
+	Accounts      Accounts
+	Notifications Notifications
+	Search        Search
+}
+
+type Appointments interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, id string) (models.AppointmentIDResp, error)
+	Reminders(ctx context.Context, id string) ([]models.AppointmentReminder, error)
+	StatusHistory(ctx context.Context, id string) ([]models.AppointmentStatusChange, error)
+	// ForPatient returns the patient's appointments, latest first, and
+	// ForDoctor the doctor's, earliest first.
+	ForPatient(ctx context.Context, userTag string) ([]models.PatientAppointment, error)
+	ForDoctor(ctx context.Context, doctorTag string) ([]models.DoctorAppointment, error)
+	// ChangeStatus locks the appointment and hands it to check. Unless check
+	// returns an error, the appointment moves to change.ToStatus and change is
+	// added to its status history, in the same transaction. It returns the
+	// status the appointment moved from.
+	ChangeStatus(ctx context.Context, id string, change models.AppointmentStatusChange, check func(current models.Appointment) error) (string, error)
+	// Book hands appointment to reserve and, unless reserve returns an error,
+	// stores it with the length reserve returned and adds bookedBy, with
+	// ToStatus set to the appointment's status, to its status history, all in
+	// one transaction. It returns the new appointment's id.
+	Book(ctx context.Context, appointment models.Appointment, bookedBy models.AppointmentStatusChange, reserve Reserve) (string, error)
+	// Reschedule locks the appointment and hands it to reserve with
+	// Scheduled_at set to at. Unless reserve returns an error, the
+	// appointment moves to at with the length reserve returned; if
+	// change.ToStatus is set and differs from the appointment's status, it
+	// also moves to that status and change is added to its status history.
+	// The reminders already sent for the old time are cleared, so the new
+	// time gets its own. It returns ErrNotFound if there is no such
+	// appointment.
+	Reschedule(ctx context.Context, id string, at time.Time, change models.AppointmentStatusChange, reserve Reserve) error
+}
+
+// Reserve checks that appointment may take up its time in cal and returns
+// how long it will last. Book and Reschedule call it inside their
+// transaction, so what it reads through cal cannot change before the
+// appointment is stored.
+type Reserve func(cal Calendar, appointment models.Appointment) (time.Duration, error)
+
+// Calendar reads what a booking has to fit around. Repositories.Calendar
+// reads outside any transaction; the one handed to a Reserve reads inside
+// the booking's.
+type Calendar interface {
+	// Schedule returns an approved doctor's schedule, or ErrNotFound for any
+	// other doctor. Inside a booking it also locks the doctor until the
+	// booking ends, so bookings for the same doctor are serialised.
+	Schedule(ctx context.Context, doctorTag string) (models.DoctorSchedule, error)
+	// Booked returns the doctor's live appointments that overlap from to to,
+	// other than excludeID.
+	Booked(ctx context.Context, doctorTag string, from, to time.Time, excludeID string) ([]models.Booking, error)
+}
+
+// Reminders are the appointment reminders the scheduler sends, one per
+// recipient and offset.
+type Reminders interface {
+	// Due returns the confirmed appointments starting more than lower and at
+	// most upper from now that are still owed a reminder for upper. A
+	// reminder that failed is still owed.
+	Due(ctx context.Context, lower, upper time.Duration) ([]models.DueReminder, error)
+	// Claim records the reminder as sent before it goes out, reporting false
+	// if it already was, so it is never sent twice. A failed reminder can be
+	// claimed again.
+	Claim(ctx context.Context, appointmentID, recipient string, offset time.Duration) (bool, error)
+	// Fail records that a claimed reminder could not be sent.
+	Fail(ctx context.Context, appointmentID, recipient string, offset time.Duration) error
+}
+
+type Doctors interface {
+	// List returns approved doctors only.
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	// Get returns a doctor of any status with their schedule, or ErrNotFound.
+	Get(ctx context.Context, doctorTag string) (models.Doctor, error)
+	// Delete returns ErrNotFound if there is no such doctor.
+	Delete(ctx context.Context, doctorTag string) error
+	Applications(ctx context.Context, params models.ListParams) (models.Page, error)
+	Application(ctx context.Context, doctorTag string) (models.DoctorApplication, error)
+	// ReviewApplication moves a pending application to status and returns
+	// the applicant's email. resubmitTokenHash, if set, is the hash of the
+	// single-use code that lets the applicant resubmit within resubmitTTL.
+	// ErrNotFound means there was no pending application to review.
+	ReviewApplication(ctx context.Context, doctorTag, status, note, resubmitTokenHash string, resubmitTTL time.Duration) (string, error)
+	// Account and AccountByEmail return the doctor's sign-in details in any
+	// status, or ErrNotFound.
+	Account(ctx context.Context, doctorTag string) (models.DoctorRecord, error)
+	AccountByEmail(ctx context.Context, email string) (models.DoctorRecord, error)
+	// Apply stores a new pending application under doctorTag.
+	Apply(ctx context.Context, doctorTag string, application models.DoctorApplicationReq, passwordHash string) error
+	// Resubmit replaces an application that was sent back for more
+	// information and returns it to the review queue, spending the code
+	// whose hash is tokenHash. It returns ErrNotFound if the doctor's
+	// application is not waiting on them or the code is wrong or expired.
+	Resubmit(ctx context.Context, doctorTag, tokenHash string, application models.DoctorApplicationReq, passwordHash string) error
+	Profile(ctx context.Context, doctorTag string) (models.DoctorProfile, error)
+	// UpdateProfile sets only the fields in patch, or returns ErrNotFound.
+	UpdateProfile(ctx context.Context, patch models.DoctorProfilePatch) error
+	// Schedule returns the schedule of a doctor in any status, or
+	// ErrNotFound. SetSchedule replaces it whole.
+	Schedule(ctx context.Context, doctorTag string) (models.DoctorSchedule, error)
+	SetSchedule(ctx context.Context, schedule models.DoctorSchedule) error
+	// SetOTP stores a new OTP valid for ttl and clears the count of wrong
+	// guesses.
+	SetOTP(ctx context.Context, doctorTag, otp string, ttl time.Duration) error
+	// ClearOTP, CountWrongOTP and ResetOTPAttempts do for the doctor what the
+	// Admins methods of the same names do for an admin.
+	ClearOTP(ctx context.Context, doctorTag string) error
+	CountWrongOTP(ctx context.Context, doctorTag string) (int, error)
+	ResetOTPAttempts(ctx context.Context, doctorTag string) error
+}
+
+type Patients interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, userTag string) (models.PatientIdResp, error)
+	Update(ctx context.Context, patient models.Patient) error
+	// Delete returns ErrNotFound if there is no such patient.
+	Delete(ctx context.Context, userTag string) error
+	// AccountByEmail returns the patient's sign-in details, or ErrNotFound.
+	AccountByEmail(ctx context.Context, email string) (models.PatientRecord, error)
+	// Register stores a new, unverified patient with an OTP valid for ttl.
+	Register(ctx context.Context, profile models.PatientProfile, passwordHash, otp string, ttl time.Duration) error
+	Profile(ctx context.Context, userTag string) (models.PatientProfile, error)
+	// UpdateProfile sets only the fields in patch, or returns ErrNotFound.
+	UpdateProfile(ctx context.Context, patch models.PatientProfilePatch) error
+	// SetOTP stores a new OTP valid for ttl and clears the count of wrong
+	// guesses. VerifyEmail and SetPassword spend it.
+	SetOTP(ctx context.Context, email, otp string, ttl time.Duration) error
+	VerifyEmail(ctx context.Context, email string) error
+	SetPassword(ctx context.Context, email, passwordHash string) error
+	// ClearOTP, CountWrongOTP and ResetOTPAttempts do for the patient with
+	// email what the Admins methods of the same names do for an admin.
+	// Patients sign in by email, so that is what their lockouts are keyed on.
+	ClearOTP(ctx context.Context, email string) error
+	CountWrongOTP(ctx context.Context, email string) (int, error)
+	ResetOTPAttempts(ctx context.Context, email string) error
+}
+
+// Pharmacies, Hospitals, Inventory and TestCentres share one shape. Create
+// stores a new row, whose key the store assigns, and returns it as stored.
+// Patch changes only the fields set in the patch and returns the result.
+// Get, Patch and Delete return ErrNotFound for an unknown id.
+type Pharmacies interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, id string) (models.Pharmacy, error)
+	Create(ctx context.Context, pharmacy models.Pharmacy) (models.Pharmacy, error)
+	Patch(ctx context.Context, id string, patch models.PharmacyPatch) (models.Pharmacy, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Hospitals interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, id string) (models.Hospital, error)
+	Create(ctx context.Context, hospital models.Hospital) (models.Hospital, error)
+	Patch(ctx context.Context, id string, patch models.HospitalPatch) (models.Hospital, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Inventory interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, id string) (models.Inventory, error)
+	Create(ctx context.Context, item models.Inventory) (models.Inventory, error)
+	Patch(ctx context.Context, id string, patch models.InventoryPatch) (models.Inventory, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Orders interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, id string) (models.Orders, error)
+	Update(ctx context.Context, order models.Orders) error
+}
+
+type TestCentres interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, id string) (models.TestCentre, error)
+	Create(ctx context.Context, centre models.TestCentre) (models.TestCentre, error)
+	Patch(ctx context.Context, id string, patch models.TestCentrePatch) (models.TestCentre, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Reviews interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Get(ctx context.Context, id string) (models.Reviews, error)
+	// Delete returns ErrNotFound if there is no such review.
+	Delete(ctx context.Context, id string) error
+}
+
+// Admins are the staff accounts. Get, GetByEmail and GetByInvite return the
+// whole row, secrets included, or ErrNotFound.
+type Admins interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Profile(ctx context.Context, adminTag string) (models.AdminProfile, error)
+	UpdateProfile(ctx context.Context, profile models.AdminProfile) error
+	Get(ctx context.Context, adminTag string) (models.AdminRecord, error)
+	GetByEmail(ctx context.Context, email string) (models.AdminRecord, error)
+	// GetByInvite finds the invited admin whose invite token hashes to
+	// tokenHash.
+	GetByInvite(ctx context.Context, tokenHash string) (models.AdminRecord, error)
+	// Invite adds admin in the "invited" state, with no password yet.
+	Invite(ctx context.Context, admin models.AdminRecord, inviteTTL time.Duration) error
+	// AcceptInvite sets the password of an invited admin and activates them.
+	AcceptInvite(ctx context.Context, adminTag, passwordHash string) error
+	// SetStatus, SetRole and RequirePasswordReset return ErrNotFound if there
+	// is no such admin; SetStatus and RequirePasswordReset also pass over
+	// admins who have not accepted their invite. RequirePasswordReset returns
+	// the admin's email.
+	SetStatus(ctx context.Context, adminTag, status string) error
+	SetRole(ctx context.Context, adminTag, role string) error
+	RequirePasswordReset(ctx context.Context, adminTag string) (string, error)
+	// SetOTP stores a new OTP valid for ttl and clears the count of wrong
+	// guesses. emailed starts the resend cooldown.
+	SetOTP(ctx context.Context, adminTag, otp string, ttl time.Duration, emailed bool) error
+	ClearOTP(ctx context.Context, adminTag string) error
+	// CountWrongOTP adds one wrong guess at the OTP and returns the total.
+	CountWrongOTP(ctx context.Context, adminTag string) (int, error)
+	ResetOTPAttempts(ctx context.Context, adminTag string) error
+	// SetResetToken spends the OTP and stores a password reset token valid for
+	// ttl in its place.
+	SetResetToken(ctx context.Context, adminTag, tokenHash string, ttl time.Duration) error
+	// PasswordHashes returns the admin's current password hash, if they have
+	// one, followed by the newest depth hashes from their history.
+	PasswordHashes(ctx context.Context, adminTag string, depth int) ([]string, error)
+	// ResetPassword sets a new password and spends the reset token, provided
+	// it still hashes to tokenHash; otherwise it returns ErrNotFound. The old
+	// password hash moves into the history, which is trimmed to depth entries.
+	ResetPassword(ctx context.Context, adminTag, tokenHash, passwordHash string, depth int) error
+}
+
+// MFA is the admins' authenticator app enrollment, their recovery codes and
+// the policy on who must use TOTP.
+type MFA interface {
+	SetPendingTOTP(ctx context.Context, adminTag, secret string) error
+	// EnableTOTP activates the pending secret, with counter as the last code
+	// used, and makes TOTP the admin's second factor.
+	EnableTOTP(ctx context.Context, adminTag string, counter int64) error
+	// DisableTOTP drops the secret and the recovery codes and goes back to
+	// email OTPs.
+	DisableTOTP(ctx context.Context, adminTag string) error
+	// UseTOTPCounter records the code for counter as used. It reports false
+	// if that code or a later one already was.
+	UseTOTPCounter(ctx context.Context, adminTag string, counter int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, adminTag string, codeHashes []string) error
+	// UseRecoveryCode spends the unused code hashing to codeHash, reporting
+	// false if there is none.
+	UseRecoveryCode(ctx context.Context, adminTag, codeHash string) (bool, error)
+	RecoveryCodesLeft(ctx context.Context, adminTag string) (int, error)
+	Policy(ctx context.Context) (models.MFAPolicy, error)
+	SetPolicy(ctx context.Context, policy models.MFAPolicy) error
+}
+
+// Lockouts count failed sign-in attempts per scope and key, such as an
+// account or an IP address.
+type Lockouts interface {
+	// List returns keys with a failure in the last day or a lock in force.
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	Locked(ctx context.Context, scope, key string) (bool, error)
+	// RecordFailure counts a failure against key and returns the count.
+	// Failures more than a day apart start the count again.
+	RecordFailure(ctx context.Context, scope, key string) (int, error)
+	Lock(ctx context.Context, scope, key string, d time.Duration) error
+	// Clear forgets key and returns what it held, or ErrNotFound.
+	Clear(ctx context.Context, scope, key string) (models.AuthLockout, error)
+}
+
+// Accounts looks up what every kind of account has, by account type (a
+// role from utils, with every staff role as "admin") and subject.
+type Accounts interface {
+	// Contact returns ErrNotFound for an unknown account.
+	Contact(ctx context.Context, accountType, subject string) (models.Contact, error)
+	Timezone(ctx context.Context, accountType, subject string) (string, error)
+}
+
+// Notifications are the channels each account has chosen per event and the
+// devices it receives push notifications on.
+type Notifications interface {
+	// Preferences returns the channels the account has set for event; the
+	// rest are left to the caller's defaults.
+	Preferences(ctx context.Context, accountType, subject, event string) (map[string]bool, error)
+	SetPreferences(ctx context.Context, accountType, subject string, preferences []models.NotificationPreference) error
+	// RegisterDevice moves a token registered to another account over.
+	RegisterDevice(ctx context.Context, accountType string, device models.PushDevice) error
+	// RemoveDevice returns ErrNotFound unless the account has the token.
+	RemoveDevice(ctx context.Context, accountType string, device models.PushDevice) error
+}
+
+// Search is the admin search across records of every kind.
+type Search interface {
+	// Groups names the kinds of record searched, in the order results are
+	// shown.
+	Groups() []string
+	// Find returns at most limit hits for term in group, best first.
+	Find(ctx context.Context, group, term string, limit int) ([]models.SearchHit, error)
+}
+
+type Sessions interface {
+	// Create stores a new session that expires after ttl.
+	Create(ctx context.Context, session models.Session, ttl time.Duration) error
+	// Rotate swaps the refresh token hash of a live session from oldHash to
+	// newHash and returns the session. A live session whose hash is not
+	// oldHash has had a spent token replayed; it is revoked and
+	// ErrTokenReused returned. Other sessions give ErrNotFound.
+	Rotate(ctx context.Context, sessionID, oldHash, newHash string, client models.ClientInfo) (models.Session, error)
+	Active(ctx context.Context, sessionID string) (bool, error)
+	Revoke(ctx context.Context, sessionID string) error
+	RevokeAll(ctx context.Context, accountType, subject string) error
+}
+
+type Reports interface {
+	Dashboard(ctx context.Context) (models.DashboardSummary, error)
+	// Payments totals the completed payments in a month.
+	Payments(ctx context.Context, month, year string) (models.AnalyticsResp, error)
+}
+
+type AuditLog interface {
+	List(ctx context.Context, params models.ListParams) (models.Page, error)
+	// Snapshot returns the current row of table as a map, or ErrNotFound.
+	// table and keyColumn always come from our own code, never a request.
+	Snapshot(ctx context.Context, table, keyColumn, id string) (map[string]any, error)
+	Append(ctx context.Context, entry models.AuditLog) error
+}
@@ -5,9 +5,20 @@ import (
 )
 
 type Response struct {
-	Success bool   `json:"success"`
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Code    string       `json:"code,omitempty"`
+	Data    any          `json:"data"`
+	Meta    any          `json:"meta,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is one failing field of a request. Code is stable for clients
+// to match on; Message is for people.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
-	Data    any    `json:"data"`
 }
 
 type squadResponse struct {
@@ -16,14 +27,50 @@ type squadResponse struct {
 	Response_description  string `json:"response_description"`
 }
 
+// errorCodes are the stable codes error responses carry, by status, for
+// clients to match on instead of the message.
+var errorCodes = map[int]string{
+	400: "bad_request",
+	401: "unauthorized",
+	403: "forbidden",
+	404: "not_found",
+	405: "method_not_allowed",
+	409: "conflict",
+	413: "payload_too_large",
+	422: "validation_failed",
+	429: "rate_limited",
+	500: "internal",
+	503: "unavailable",
+}
+
+func ErrorCode(statusCode int) string {
+	if code, ok := errorCodes[statusCode]; ok {
+		return code
+	}
+	return "error"
+}
+
 func ErrorResponse(c *fiber.Ctx, message string, statusCode int) error {
 	res := Response{
 		Success: false,
 		Message: message,
+		Code:    ErrorCode(statusCode),
 	}
 	return c.Status(statusCode).JSON(res)
 }
 
+// ValidationErrorResponse answers a request that failed validation with a
+// 422 listing every failing field.
+func ValidationErrorResponse(c *fiber.Ctx, errs []FieldError) error {
+	res := Response{
+		Success: false,
+		Message: VALIDATION_FAILED,
+		Code:    ErrorCode(422),
+		Errors:  errs,
+	}
+	return c.Status(422).JSON(res)
+}
+
 func SuccessResponse(c *fiber.Ctx, message string, data any, statusCode int) error {
 	res := Response{
 		Success: true,
@@ -33,6 +80,18 @@ func SuccessResponse(c *fiber.Ctx, message string, data any, statusCode int) err
 	return c.Status(statusCode).JSON(res)
 }
 
+// PagedResponse is SuccessResponse for list endpoints, with the page's
+// total and next cursor in meta.
+func PagedResponse(c *fiber.Ctx, message string, data any, meta any, statusCode int) error {
+	res := Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	}
+	return c.Status(statusCode).JSON(res)
+}
+
 func SquadResponse(c *fiber.Ctx, reference, description string, statusCode int) error {
 	res := squadResponse{
 		Response_code:         statusCode,
@@ -46,6 +105,7 @@ const (
 	UNAUTHORIZED_ACCESS    = "unauthorized access"
 	BAD_DATA               = "invalid data"
 	INCOMPLETE_DATA        = "incomplete data"
+	VALIDATION_FAILED      = "some fields are missing or invalid"
 	LOGIN_SUCCESSFUL       = "login successful"
 	OTP_SENT               = "otp has been sent to your email"
 	ACCOUNT_NON_EXISTENT   = "admin account does not exist"
@@ -57,4 +117,78 @@ const (
 	DATA_FETCHED           = "data fetched successfully"
 	DATA_UPDATED           = "data updated successfully"
 	DATA_CREATED           = "data created successfully"
+
+	PATIENT_NON_EXISTENT = "patient account does not exist"
+	EMAIL_IN_USE         = "an account with this email already exists"
+	EMAIL_NOT_VERIFIED   = "email has not been verified"
+	EMAIL_VERIFIED       = "email verified successfully"
+	INVALID_OTP          = "invalid OTP"
+	OTP_EXPIRED          = "OTP has expired"
+
+	INVALID_DATETIME      = "invalid datetime format, expected RFC3339"
+	DOCTOR_NOT_FOUND      = "doctor not found"
+	SLOT_UNAVAILABLE      = "doctor is not available at the requested time"
+	SLOT_TAKEN            = "the requested time slot has already been booked"
+	INVALID_SCHEDULE      = "invalid availability schedule, check slot length, buffer, weekdays, dates and HH:MM times"
+	INVALID_TIMEZONE      = "unknown timezone, expected an IANA name such as Africa/Lagos"
+	INVALID_DATE_RANGE    = "invalid date range, expected YYYY-MM-DD dates at most 31 days apart"
+	APPOINTMENT_NOT_FOUND = "appointment not found"
+	SEARCH_TOO_SHORT      = "search term must be at least 2 characters"
+	APPOINTMENT_CLOSED    = "appointment can no longer be changed"
+	APPOINTMENT_BOOKED    = "appointment booked successfully"
+
+	DOCTOR_NON_EXISTENT          = "doctor account does not exist"
+	INVALID_STATUS               = "invalid appointment status"
+	INVALID_STATUS_TRANSITION    = "appointment status cannot change"
+	CANCELLATION_REASON_REQUIRED = "a reason is required to cancel an appointment"
+
+	APPLICATION_SUBMITTED   = "application submitted, you will be notified once it has been reviewed"
+	APPLICATION_NOT_FOUND   = "doctor application not found"
+	APPLICATION_PENDING     = "doctor application is still under review"
+	APPLICATION_CLOSED      = "doctor application can no longer be changed"
+	RESUBMIT_TOKEN_INVALID  = "resubmission code is invalid or has expired"
+	INVALID_REVIEW_DECISION = "invalid review decision"
+	REASON_REQUIRED         = "a reason is required for this decision"
+	DOCTOR_NOT_APPROVED     = "doctor account has not been approved"
+
+	INVITE_SENT             = "invite has been sent"
+	INVITE_INVALID          = "invite is invalid or has expired"
+	INVALID_ROLE            = "invalid role"
+	INVALID_ACCOUNT_STATUS  = "invalid account status"
+	ACCOUNT_SUSPENDED       = "admin account has been suspended"
+	ACCOUNT_NOT_ACTIVATED   = "admin account has not been activated"
+	PASSWORD_RESET_REQUIRED = "a password reset is required before you can log in"
+	CANNOT_MODIFY_SELF      = "you cannot change your own account this way"
+
+	SESSION_INVALID   = "session is invalid or has expired, please log in again"
+	SESSION_REFRESHED = "session refreshed successfully"
+	LOGGED_OUT        = "logged out successfully"
+
+	TOO_MANY_ATTEMPTS     = "too many failed attempts, please try again later"
+	OTP_ATTEMPTS_EXCEEDED = "too many incorrect codes, please request a new OTP"
+	OTP_COOLDOWN          = "please wait before requesting another OTP"
+	LOCKOUT_NOT_FOUND     = "lockout not found"
+	LOCKOUT_CLEARED       = "lockout cleared successfully"
+
+	RESET_TOKEN_INVALID = "password reset token is invalid or has expired"
+	WEAK_PASSWORD       = "password must be at least 10 characters and include upper and lower case letters, a number and a symbol"
+	PASSWORD_REUSED     = "password has been used recently, please choose a different one"
+
+	INVALID_MFA_METHOD     = "invalid second factor method"
+	TOTP_NOT_ENABLED       = "authenticator app has not been set up for this account"
+	TOTP_ALREADY_ENABLED   = "authenticator app is already set up for this account"
+	TOTP_ENROLLMENT_NEEDED = "start authenticator app enrollment first"
+	TOTP_REQUIRED          = "this account must sign in with an authenticator app"
+	TOTP_ENABLED           = "authenticator app enabled, store your recovery codes safely"
+	TOTP_DISABLED          = "authenticator app disabled"
+
+	INVALID_NOTIFICATION_PREFERENCE = "invalid notification event or channel"
+	DEVICE_REGISTERED               = "device registered successfully"
+	DEVICE_NOT_FOUND                = "device not found"
+
+	REQUEST_TIMEOUT = "the request took too long, please try again"
+
+	RECORD_NOT_FOUND = "the requested record does not exist"
+	RECORD_EXISTS    = "a record with these details already exists"
+	RECORD_IN_USE    = "this record is still in use, or refers to one that does not exist"
 )
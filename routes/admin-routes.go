@@ -1,96 +1,98 @@
 package routes
 
 import (
+	"telemed/config"
 	"telemed/controllers"
 	"telemed/middleware"
-	"telemed/responses"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 var adminController controllers.AdminController
 
-const (
-	Admin   = "admin"
-	God_eye = "god_eye"
-)
-
 func AdminRoutes(app *fiber.App) {
 	api := app.Group("/admin")
-	api.Post("/Login", roleMiddleware(Admin, God_eye), adminController.Login)
-	api.Post("/otp", roleMiddleware(Admin, God_eye), adminController.VerifyOTP)
-	api.Post("/forgot-password", roleMiddleware(Admin, God_eye), adminController.ForgotPassword)
-	api.Post("/verify-forgot-password-otp", roleMiddleware(Admin, God_eye), adminController.VerifyPwdOTP)
-	api.Post("/reset-password", roleMiddleware(Admin, God_eye), adminController.ResetPassword)
+	report := middleware.Timeout(config.Timeouts().Report)
+	api.Post("/Login", adminController.Login)
+	api.Post("/otp", adminController.VerifyOTP)
+	api.Post("/forgot-password", adminController.ForgotPassword)
+	api.Post("/verify-forgot-password-otp", adminController.VerifyPwdOTP)
+	api.Post("/reset-password", adminController.ResetPassword)
+	api.Post("/accept-invite", adminController.AcceptInvite)
+	sessionRoutes(api)
 	//dashboards
-	api.Get("/dashboard/summary", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchDashboardSummary)
-	api.Get("/analytics", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchAnalytics)
+	api.Get("/dashboard/summary", report, middleware.JWTProtected(), middleware.Authorize(middleware.ViewDashboard), adminController.FetchDashboardSummary)
+	api.Get("/analytics", report, middleware.JWTProtected(), middleware.Authorize(middleware.ViewDashboard), adminController.FetchAnalytics)
 	//appointments
-	api.Get("/appointments", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchAppointments)
-	api.Post("/appointments/:id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchAppointmentByID)
-	api.Patch("/appointments/:id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdateAppointmentStatus)
-	api.Put("/appointments/:id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdateAppointment)
+	api.Get("/appointments", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAppointments), adminController.FetchAppointments)
+	api.Post("/appointments/:id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAppointments), adminController.FetchAppointmentByID)
+	api.Patch("/appointments/:id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAppointments), adminController.UpdateAppointmentStatus)
+	api.Put("/appointments/:id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAppointments), adminController.UpdateAppointment)
 	//doctors
-	api.Get("/doctors", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchDoctors)
-	api.Get("/doctors/:doctortag", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchDoctorByID)
-	api.Delete("/doctors/:doctortag", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.DeleteDoctor)
+	api.Get("/doctors", middleware.JWTProtected(), middleware.Authorize(middleware.ViewDoctors), adminController.FetchDoctors)
+	api.Get("/doctors/:doctortag", middleware.JWTProtected(), middleware.Authorize(middleware.ViewDoctors), adminController.FetchDoctorByID)
+	api.Delete("/doctors/:doctortag", middleware.JWTProtected(), middleware.Authorize(middleware.DeleteDoctors), adminController.DeleteDoctor)
+	//doctor applications
+	api.Get("/doctor-requests", middleware.JWTProtected(), middleware.Authorize(middleware.ReviewDoctors), adminController.FetchDoctorApplications)
+	api.Get("/doctor-requests/:doctortag", middleware.JWTProtected(), middleware.Authorize(middleware.ReviewDoctors), adminController.FetchDoctorApplicationByID)
+	api.Patch("/doctor-requests/:doctortag", middleware.JWTProtected(), middleware.Authorize(middleware.ReviewDoctors), adminController.ReviewDoctorApplication)
 	//patients
-	api.Get("/patients", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchPatients)
-	api.Get("/patients/:usertag", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchPatientByUsertag)
-	api.Delete("/patients/:usertag", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.DeletePatient)
-	api.Patch("/patients/:usertag", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.EditPatient)
+	api.Get("/patients", middleware.JWTProtected(), middleware.Authorize(middleware.ViewPatients), adminController.FetchPatients)
+	api.Get("/patients/:usertag", middleware.JWTProtected(), middleware.Authorize(middleware.ViewPatients), adminController.FetchPatientByUsertag)
+	api.Delete("/patients/:usertag", middleware.JWTProtected(), middleware.Authorize(middleware.DeletePatients), adminController.DeletePatient)
+	api.Patch("/patients/:usertag", middleware.JWTProtected(), middleware.Authorize(middleware.EditPatients), adminController.EditPatient)
 	//pharmacy
-	api.Get("/pharmacy", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchPharmacy)
-	api.Get("/pharmacy/:pharmacy_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchPharmacyByID)
-	api.Post("/pharmacy", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.CreatePharmacy)
-	api.Delete("/pharmacy/:pharmacy_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.DeletePharmacy)
-	api.Patch("/pharmacy/:pharmacy_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdatePharmacy)
+	api.Get("/pharmacy", middleware.JWTProtected(), middleware.Authorize(middleware.ViewPharmacies), adminController.FetchPharmacy)
+	api.Get("/pharmacy/:pharmacy_id", middleware.JWTProtected(), middleware.Authorize(middleware.ViewPharmacies), adminController.FetchPharmacyByID)
+	api.Post("/pharmacy", middleware.JWTProtected(), middleware.Authorize(middleware.ManagePharmacies), adminController.CreatePharmacy)
+	api.Delete("/pharmacy/:pharmacy_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManagePharmacies), adminController.DeletePharmacy)
+	api.Patch("/pharmacy/:pharmacy_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManagePharmacies), adminController.UpdatePharmacy)
 	//hospitals
-	api.Get("/hospitals", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchHospitals)
-	api.Get("/hospitals/:hospital_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchHospitalByID)
-	api.Post("/hospitals", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.CreateHospital)
-	api.Delete("/hospitals/:hospital_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.DeleteHospital)
-	api.Patch("/hospitals/:hospital_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdateHospital)
+	api.Get("/hospitals", middleware.JWTProtected(), middleware.Authorize(middleware.ManageHospitals), adminController.FetchHospitals)
+	api.Get("/hospitals/:hospital_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageHospitals), adminController.FetchHospitalByID)
+	api.Post("/hospitals", middleware.JWTProtected(), middleware.Authorize(middleware.ManageHospitals), adminController.CreateHospital)
+	api.Delete("/hospitals/:hospital_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageHospitals), adminController.DeleteHospital)
+	api.Patch("/hospitals/:hospital_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageHospitals), adminController.UpdateHospital)
 	//inventory
-	api.Get("/inventory", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchInventory)
-	api.Get("/inventory/:inventory_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchInventoryByID)
-	api.Post("/inventory", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.CreateInventory)
-	api.Delete("/inventory/:inventory_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.DeleteInventory)
-	api.Patch("/inventory/:inventory_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdateInventory)
+	api.Get("/inventory", middleware.JWTProtected(), middleware.Authorize(middleware.ViewInventory), adminController.FetchInventory)
+	api.Get("/inventory/:inventory_id", middleware.JWTProtected(), middleware.Authorize(middleware.ViewInventory), adminController.FetchInventoryByID)
+	api.Post("/inventory", middleware.JWTProtected(), middleware.Authorize(middleware.ManageInventory), adminController.CreateInventory)
+	api.Delete("/inventory/:inventory_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageInventory), adminController.DeleteInventory)
+	api.Patch("/inventory/:inventory_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageInventory), adminController.UpdateInventory)
 	//orders
-	api.Get("/orders", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchOrders)
-	api.Get("/orders/:order_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchOrderByID)
-	api.Put("/orders/:order_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdateOrder)
+	api.Get("/orders", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOrders), adminController.FetchOrders)
+	api.Get("/orders/:order_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOrders), adminController.FetchOrderByID)
+	api.Put("/orders/:order_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOrders), adminController.UpdateOrder)
 	//test center
-	api.Get("/test-centers", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchTestCenters)
-	api.Get("/test-centers/:test_center_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchTestCenterByID)
-	api.Post("/test-centers", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.CreateTestCenter)
-	api.Delete("/test-centers/:test_center_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.DeleteCenter)
-	api.Patch("/test-centers/:test_center_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdateTestCenter)
+	api.Get("/test-centers", middleware.JWTProtected(), middleware.Authorize(middleware.ViewTestCentres), adminController.FetchTestCenters)
+	api.Get("/test-centers/:test_center_id", middleware.JWTProtected(), middleware.Authorize(middleware.ViewTestCentres), adminController.FetchTestCenterByID)
+	api.Post("/test-centers", middleware.JWTProtected(), middleware.Authorize(middleware.ManageTestCentres), adminController.CreateTestCenter)
+	api.Delete("/test-centers/:test_center_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageTestCentres), adminController.DeleteCenter)
+	api.Patch("/test-centers/:test_center_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageTestCentres), adminController.UpdateTestCenter)
 	//reviews
-	api.Get("/reviews", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchReviews)
-	api.Get("/reviews/:review_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchReviewByID)
-	api.Delete("/reviews/:review_id", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.DeleteReview)
+	api.Get("/reviews", middleware.JWTProtected(), middleware.Authorize(middleware.ManageReviews), adminController.FetchReviews)
+	api.Get("/reviews/:review_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageReviews), adminController.FetchReviewByID)
+	api.Delete("/reviews/:review_id", middleware.JWTProtected(), middleware.Authorize(middleware.ManageReviews), adminController.DeleteReview)
 	//admin profile
-	api.Get("/profile", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.FetchAdminProfile)
-	api.Patch("/profile", roleMiddleware(Admin, God_eye), middleware.JWTProtected(), adminController.UpdateAdminProfile)
-}
-
-func roleMiddleware(allowedRoles ...string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		adminRole := c.Get("role")
-		if !contains(allowedRoles, adminRole) {
-			return responses.ErrorResponse(c, "Unauthorized access", fiber.StatusForbidden)
-		}
-		return c.Next()
-	}
-}
+	api.Get("/profile", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOwnStaffProfile), adminController.FetchAdminProfile)
+	api.Patch("/profile", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOwnStaffProfile), adminController.UpdateAdminProfile)
 
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
+	//second factor
+	api.Get("/mfa", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOwnStaffProfile), adminController.FetchMFAStatus)
+	api.Post("/mfa/totp/enroll", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOwnStaffProfile), adminController.EnrollTOTP)
+	api.Post("/mfa/totp/confirm", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOwnStaffProfile), adminController.ConfirmTOTP)
+	api.Post("/mfa/totp/disable", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOwnStaffProfile), adminController.DisableTOTP)
+	api.Post("/mfa/recovery-codes", middleware.JWTProtected(), middleware.Authorize(middleware.ManageOwnStaffProfile), adminController.RegenerateRecoveryCodes)
+	//admin accounts
+	api.Get("/admins", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.FetchAdmins)
+	api.Post("/admins/invite", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.InviteAdmin)
+	api.Patch("/admins/:admintag/status", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.UpdateAdminStatus)
+	api.Patch("/admins/:admintag/role", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.UpdateAdminRole)
+	api.Post("/admins/:admintag/force-password-reset", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.ForceAdminPasswordReset)
+	api.Get("/lockouts", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.FetchLockouts)
+	api.Delete("/lockouts/:scope/:key", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.ResetLockout)
+	api.Get("/mfa-policy", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.FetchMFAPolicy)
+	api.Put("/mfa-policy", middleware.JWTProtected(), middleware.Authorize(middleware.ManageAdmins), adminController.UpdateMFAPolicy)
+	api.Get("/audit-logs", report, middleware.JWTProtected(), middleware.Authorize(middleware.ViewAuditLogs), adminController.FetchAuditLogs)
+	api.Get("/search", report, middleware.JWTProtected(), middleware.Authorize(middleware.Search), adminController.Search)
 }
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"telemed/controllers"
+	"telemed/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var doctorController controllers.DoctorController
+
+func DoctorRoutes(app *fiber.App) {
+	api := app.Group("/doctor")
+	api.Post("/apply", doctorController.Apply)
+	api.Post("/login", doctorController.Login)
+	api.Post("/otp", doctorController.VerifyOTP)
+	sessionRoutes(api)
+	notificationRoutes(api, middleware.DoctorSelfService)
+	//appointments
+	api.Get("/appointments", middleware.JWTProtected(), middleware.Authorize(middleware.DoctorSelfService), doctorController.FetchAppointments)
+	api.Patch("/appointments/:id", middleware.JWTProtected(), middleware.Authorize(middleware.DoctorSelfService), doctorController.UpdateAppointmentStatus)
+	//profile
+	api.Get("/profile", middleware.JWTProtected(), middleware.Authorize(middleware.DoctorSelfService), doctorController.FetchProfile)
+	api.Patch("/profile", middleware.JWTProtected(), middleware.Authorize(middleware.DoctorSelfService), doctorController.UpdateProfile)
+	api.Get("/availability", middleware.JWTProtected(), middleware.Authorize(middleware.DoctorSelfService), doctorController.FetchAvailability)
+	api.Put("/availability", middleware.JWTProtected(), middleware.Authorize(middleware.DoctorSelfService), doctorController.UpdateAvailability)
+}
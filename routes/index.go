@@ -5,5 +5,6 @@ import (
 )
 
 func Routes(app *fiber.App) {
-
+	PatientRoutes(app)
+	DoctorRoutes(app)
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"telemed/controllers"
+	"telemed/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var notificationController controllers.NotificationController
+
+// notificationRoutes mounts channel preferences and push device registration
+// on a route group, for the signed-in user holding permission.
+func notificationRoutes(api fiber.Router, permission middleware.Permission) {
+	api.Get("/notification-preferences", middleware.JWTProtected(), middleware.Authorize(permission), notificationController.FetchPreferences)
+	api.Put("/notification-preferences", middleware.JWTProtected(), middleware.Authorize(permission), notificationController.UpdatePreferences)
+	api.Post("/devices", middleware.JWTProtected(), middleware.Authorize(permission), notificationController.RegisterDevice)
+	api.Delete("/devices/:token", middleware.JWTProtected(), middleware.Authorize(permission), notificationController.RemoveDevice)
+}
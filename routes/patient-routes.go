@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"telemed/controllers"
+	"telemed/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var patientController controllers.PatientController
+
+func PatientRoutes(app *fiber.App) {
+	api := app.Group("/patient")
+	api.Post("/register", patientController.Register)
+	api.Post("/verify-email", patientController.VerifyEmail)
+	api.Post("/resend-otp", patientController.ResendOTP)
+	api.Post("/login", patientController.Login)
+	api.Post("/forgot-password", patientController.ForgotPassword)
+	api.Post("/reset-password", patientController.ResetPassword)
+	sessionRoutes(api)
+	notificationRoutes(api, middleware.PatientSelfService)
+	//profile
+	api.Get("/profile", middleware.JWTProtected(), middleware.Authorize(middleware.PatientSelfService), patientController.FetchProfile)
+	api.Patch("/profile", middleware.JWTProtected(), middleware.Authorize(middleware.PatientSelfService), patientController.UpdateProfile)
+	//appointments
+	api.Get("/doctors/:doctortag/slots", middleware.JWTProtected(), middleware.Authorize(middleware.PatientSelfService), patientController.FetchDoctorSlots)
+	api.Post("/appointments", middleware.JWTProtected(), middleware.Authorize(middleware.PatientSelfService), patientController.BookAppointment)
+	api.Get("/appointments", middleware.JWTProtected(), middleware.Authorize(middleware.PatientSelfService), patientController.FetchAppointments)
+	api.Patch("/appointments/:id/cancel", middleware.JWTProtected(), middleware.Authorize(middleware.PatientSelfService), patientController.CancelAppointment)
+	api.Put("/appointments/:id", middleware.JWTProtected(), middleware.Authorize(middleware.PatientSelfService), patientController.RescheduleAppointment)
+}
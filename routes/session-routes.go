@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"telemed/controllers"
+	"telemed/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var sessionController controllers.SessionController
+
+// sessionRoutes mounts the refresh and logout endpoints on a route group. Any
+// role may manage its own sessions, so only a valid token is required.
+func sessionRoutes(api fiber.Router) {
+	api.Post("/refresh", sessionController.Refresh)
+	api.Post("/logout", middleware.JWTProtected(), sessionController.Logout)
+	api.Post("/logout-all", middleware.JWTProtected(), sessionController.LogoutAll)
+}
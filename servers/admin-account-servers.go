@@ -0,0 +1,171 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/notifications"
+	"telemed/repository"
+	"telemed/responses"
+	"telemed/utils"
+	"time"
+)
+
+// inviteTTL is how long an invite code can be exchanged for a password.
+const inviteTTL = 72 * time.Hour
+
+// InviteAdmin creates an admins row in the "invited" state and emails the
+// invitee a single-use token they exchange for a password via AcceptInvite.
+func (s AdminServer) InviteAdmin(ctx context.Context, actor models.Actor, data models.InviteAdmin) (any, error) {
+	if !utils.IsStaffRole(data.Role) {
+		return nil, apperrors.Invalid(responses.INVALID_ROLE)
+	}
+
+	_, err := s.Repos.Admins.GetByEmail(ctx, data.Email)
+	if err == nil {
+		return nil, apperrors.Conflict(responses.EMAIL_IN_USE)
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		log.Println("Failed to check admin email:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		log.Println("Failed to generate invite token:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	adminTag := utils.GenerateUUID(data.Firstname)
+	invite := models.AdminRecord{
+		AdminAccount:    models.AdminAccount{AdminTag: adminTag, Firstname: data.Firstname, Lastname: data.Lastname, Email: data.Email, Role: data.Role},
+		InviteTokenHash: utils.HashToken(token),
+	}
+	if err := s.Repos.Admins.Invite(ctx, invite, inviteTTL); err != nil {
+		log.Println("Failed to create admin invite:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	body := "You have been invited to the Telemed dashboard as " + data.Role + ".\n\n" +
+		"Use this invite code to set your password: " + token + "\n\nThe code expires in 72 hours."
+	notice := notifications.NoticeData{Subject: "You have been invited to Telemed", Body: body}
+	if err := notifications.Send(data.Email, notifications.EventNotice, notice); err != nil {
+		log.Println("Failed to send invite email:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	s.recordAudit(ctx, actor, "invite", "admin", adminTag, nil, s.snapshot(ctx, "admins", "admintag", adminTag))
+	return map[string]string{"admintag": adminTag, "status": "invited"}, nil
+}
+
+func (s AdminServer) AcceptInvite(ctx context.Context, client models.ClientInfo, data models.AcceptAdminInvite) (any, error) {
+	admin, err := s.Repos.Admins.GetByInvite(ctx, utils.HashToken(data.Token))
+	if err != nil {
+		log.Println("Failed to find admin invite:", err)
+		return nil, apperrors.Unauthorized(responses.INVITE_INVALID)
+	}
+	if admin.InviteExpiry == nil || time.Now().After(*admin.InviteExpiry) {
+		return nil, apperrors.Unauthorized(responses.INVITE_INVALID)
+	}
+
+	if !utils.IsStrongPassword(data.Password) {
+		return nil, apperrors.Invalid(responses.WEAK_PASSWORD)
+	}
+
+	hashedPwd, err := utils.HashPassword(data.Password)
+	if err != nil {
+		log.Println("Failed to hash password:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	before := s.snapshot(ctx, "admins", "admintag", admin.AdminTag)
+	if err := s.Repos.Admins.AcceptInvite(ctx, admin.AdminTag, hashedPwd); err != nil {
+		log.Println("Failed to accept admin invite:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	actor := models.Actor{AdminTag: admin.AdminTag, Role: admin.Role, IP: client.IP}
+	s.recordAudit(ctx, actor, "accept_invite", "admin", admin.AdminTag, before, s.snapshot(ctx, "admins", "admintag", admin.AdminTag))
+	return map[string]string{"admintag": admin.AdminTag, "status": "active"}, nil
+}
+
+func (s AdminServer) GetAdmins(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Admins.List(ctx, params)
+	return page, listError("admins", err)
+}
+
+func (s AdminServer) UpdateAdminStatus(ctx context.Context, actor models.Actor, data models.UpdateAdminAccount) (any, error) {
+	if data.Status != "active" && data.Status != "suspended" {
+		return nil, apperrors.Invalid(responses.INVALID_ACCOUNT_STATUS)
+	}
+	if actor.AdminTag == data.AdminTag {
+		return nil, apperrors.Forbidden(responses.CANNOT_MODIFY_SELF)
+	}
+
+	before := s.snapshot(ctx, "admins", "admintag", data.AdminTag)
+	if err := s.Repos.Admins.SetStatus(ctx, data.AdminTag, data.Status); err != nil {
+		log.Println("Failed to update admin status:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.ACCOUNT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	if data.Status == "suspended" {
+		revokeAllSessions(ctx, s.Repos.Sessions, data.AdminTag, utils.RoleAdmin)
+	}
+
+	s.recordAudit(ctx, actor, "change_status", "admin", data.AdminTag, before, s.snapshot(ctx, "admins", "admintag", data.AdminTag))
+	return map[string]string{"admintag": data.AdminTag, "status": data.Status}, nil
+}
+
+func (s AdminServer) UpdateAdminRole(ctx context.Context, actor models.Actor, data models.UpdateAdminAccount) (any, error) {
+	if !utils.IsStaffRole(data.Role) {
+		return nil, apperrors.Invalid(responses.INVALID_ROLE)
+	}
+	if actor.AdminTag == data.AdminTag {
+		return nil, apperrors.Forbidden(responses.CANNOT_MODIFY_SELF)
+	}
+
+	before := s.snapshot(ctx, "admins", "admintag", data.AdminTag)
+	if err := s.Repos.Admins.SetRole(ctx, data.AdminTag, data.Role); err != nil {
+		log.Println("Failed to update admin role:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.ACCOUNT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	// tokens carry the role, so existing sessions must not outlive the change
+	revokeAllSessions(ctx, s.Repos.Sessions, data.AdminTag, utils.RoleAdmin)
+
+	s.recordAudit(ctx, actor, "change_role", "admin", data.AdminTag, before, s.snapshot(ctx, "admins", "admintag", data.AdminTag))
+	return map[string]string{"admintag": data.AdminTag, "role": data.Role}, nil
+}
+
+// ForceAdminPasswordReset blocks the admin from logging in until they have
+// gone through the forgot-password flow.
+func (s AdminServer) ForceAdminPasswordReset(ctx context.Context, actor models.Actor, data models.UpdateAdminAccount) (any, error) {
+	before := s.snapshot(ctx, "admins", "admintag", data.AdminTag)
+	email, err := s.Repos.Admins.RequirePasswordReset(ctx, data.AdminTag)
+	if err != nil {
+		log.Println("Failed to force admin password reset:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.ACCOUNT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	if email != "" {
+		body := "An administrator has required you to reset your Telemed password. " +
+			"Use the forgot password option on the login page to choose a new one."
+		s.Notify.Notice(email, "Password reset required", body)
+	}
+
+	revokeAllSessions(ctx, s.Repos.Sessions, data.AdminTag, utils.RoleAdmin)
+
+	s.recordAudit(ctx, actor, "force_password_reset", "admin", data.AdminTag, before, s.snapshot(ctx, "admins", "admintag", data.AdminTag))
+	return map[string]string{"admintag": data.AdminTag, "message": "password reset required"}, nil
+}
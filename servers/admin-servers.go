@@ -4,926 +4,921 @@ import (
 	"context"
 	"errors"
 	"log"
+	"telemed/apperrors"
 	"telemed/models"
+	"telemed/notifications"
+	"telemed/repository"
 	"telemed/responses"
 	"telemed/utils"
 	"time"
-
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-type AdminServer struct{}
+// AdminServer serves the admin portal. Repos is where it reads and writes
+// the aggregates it manages; Notify sends the messages its actions trigger.
+type AdminServer struct {
+	Repos  repository.Repositories
+	Notify AdminNotifier
+}
+
+// NewAdminServer returns an AdminServer over repos that sends its messages
+// for real.
+func NewAdminServer(repos repository.Repositories) AdminServer {
+	return AdminServer{Repos: repos, Notify: liveAdminNotifier{repos}}
+}
+
+// AdminNotifier sends the messages admin actions trigger.
+type AdminNotifier interface {
+	Notice(email, subject, body string)
+	OrderShipped(ctx context.Context, orderID string)
+}
+
+type liveAdminNotifier struct {
+	repos repository.Repositories
+}
+
+func (liveAdminNotifier) Notice(email, subject, body string) {
+	if err := notifications.Send(email, notifications.EventNotice, notifications.NoticeData{Subject: subject, Body: body}); err != nil {
+		log.Println("Failed to send notice email:", err)
+	}
+}
 
-var Ctx context.Context
-var Db *pgxpool.Pool
+func (n liveAdminNotifier) OrderShipped(ctx context.Context, orderID string) {
+	notifyOrderShipped(ctx, n.repos, orderID)
+}
+
+func (s AdminServer) Login(ctx context.Context, data models.Adminlogin) (any, error) {
+	ipKey := lockoutKey{lockoutScopeIP, data.IP}
+	if err := checkLockout(ctx, s.Repos.Lockouts, ipKey); err != nil {
+		return nil, err
+	}
 
-func (AdminServer) Login(data models.Adminlogin) (any, error) {
-	var hash string
-	var admin models.AdminLoginResponse
-	err := Db.QueryRow(Ctx, "SELECT password, admintag FROM admins WHERE email = $1", data.Email).Scan(&hash, &admin.Usertag)
+	record, err := s.Repos.Admins.GetByEmail(ctx, data.Email)
 	if err != nil {
 		log.Println(err)
-		return nil, errors.New(responses.ACCOUNT_NON_EXISTENT)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Internal(err)
+		}
+		recordFailure(ctx, s.Repos.Lockouts, ipKey)
+		return nil, apperrors.NotFound(responses.ACCOUNT_NON_EXISTENT)
+	}
+	admin := models.AdminLoginResponse{Usertag: record.AdminTag, MFAMethod: record.MFAMethod}
+	accountKey := lockoutKey{lockoutScopeAdmin, admin.Usertag}
+	if err := checkLockout(ctx, s.Repos.Lockouts, accountKey); err != nil {
+		return nil, err
+	}
+	switch record.Status {
+	case "invited":
+		return nil, apperrors.Forbidden(responses.ACCOUNT_NOT_ACTIVATED)
+	case "suspended":
+		return nil, apperrors.Forbidden(responses.ACCOUNT_SUSPENDED)
 	}
 
-	pwdCheck := utils.VerifyPassword(data.Password, hash)
+	pwdCheck := utils.VerifyPassword(data.Password, record.Password)
 	if !pwdCheck {
 		log.Println("Invalid password for admin login")
-		return nil, errors.New(responses.INVALID_PASSWORD)
+		recordFailure(ctx, s.Repos.Lockouts, accountKey, ipKey)
+		return nil, apperrors.Unauthorized(responses.INVALID_PASSWORD)
+	}
+	if record.PasswordResetRequired {
+		return nil, apperrors.Forbidden(responses.PASSWORD_RESET_REQUIRED)
+	}
+
+	if data.MFAMethod != "" {
+		admin.MFAMethod = data.MFAMethod
+	}
+	switch admin.MFAMethod {
+	case "email":
+	case "totp":
+		if record.TOTPSecret == "" {
+			return nil, apperrors.Conflict(responses.TOTP_NOT_ENABLED)
+		}
+	default:
+		return nil, apperrors.Invalid(responses.INVALID_MFA_METHOD)
+	}
+
+	if admin.MFAMethod == "email" && !otpResendAllowed(record) {
+		return nil, apperrors.RateLimited(responses.OTP_COOLDOWN)
 	}
+	// the OTP doubles as the login challenge VerifyOTP checks for, so it is
+	// stored even when the second factor is the authenticator app
 	otp, err := utils.GenerateOTP()
 	if err != nil {
 		log.Println("Failed to generate OTP:", err)
-		return nil, errors.New("failed to generate OTP")
+		return nil, apperrors.Internal(err)
 	}
-	_, err = Db.Exec(Ctx, "UPDATE admins SET otp = $1, otp_expiry = NOW()+ INTERVAL '5 minutes' WHERE email = $2", otp, data.Email)
-	if err != nil {
+	if err := s.Repos.Admins.SetOTP(ctx, record.AdminTag, otp, 5*time.Minute, admin.MFAMethod == "email"); err != nil {
 		log.Println("failed to save OTP", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
+	}
+	if admin.MFAMethod != "email" {
+		return admin, nil
 	}
 
-	err = utils.SendEmailOTP(data.Email, otp)
+	err = notifications.Send(data.Email, notifications.EventOTP, notifications.OTPData{OTP: otp, ExpiresMinutes: 5})
 	if err != nil {
 		log.Println("Failed to send OTP email:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 	return admin, nil
 }
 
-func (AdminServer) VerifyOTP(data models.OTPVerify) (any, error) {
-	var dbOtp string
-	var otpExpiryTime time.Time
-	err := Db.QueryRow(Ctx, "SELECT otp, otp_expiry, role FROM admins WHERE admintag = $1", data.Usertag).Scan(&dbOtp, &otpExpiryTime)
-	if err != nil {
-		log.Println(err)
-		return nil, errors.New("invalid email or OTP")
+func (s AdminServer) VerifyOTP(ctx context.Context, data models.OTPVerify) (any, error) {
+	accountKey, ipKey := lockoutKey{lockoutScopeAdmin, data.Usertag}, lockoutKey{lockoutScopeIP, data.IP}
+	if err := checkLockout(ctx, s.Repos.Lockouts, accountKey, ipKey); err != nil {
+		return nil, err
 	}
 
-	if data.OTP != dbOtp {
-		log.Println("Invalid OTP for admin login")
-		return nil, errors.New("invalid OTP")
+	record, err := s.Repos.Admins.Get(ctx, data.Usertag)
+	if err != nil || record.OTP == "" || record.OTPExpiry == nil {
+		log.Println("No pending OTP for admin:", err)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Internal(err)
+		}
+		recordFailure(ctx, s.Repos.Lockouts, ipKey)
+		return nil, apperrors.Unauthorized("invalid email or OTP")
 	}
 
-	if time.Now().After(otpExpiryTime) {
+	if time.Now().After(*record.OTPExpiry) {
 		log.Println("OTP has expired")
-		return nil, errors.New("OTP has expired")
+		return nil, apperrors.Unauthorized("OTP has expired")
 	}
-	_, err = Db.Exec(Ctx, `UPDATE admins SET otp = NULL, otp_expiry = NULL WHERE admintag = $1`, data.Usertag)
-	if err != nil {
+
+	var valid bool
+	switch data.Method {
+	case "", "email":
+		if s.totpRequired(ctx, record.Role) {
+			return nil, apperrors.Forbidden(responses.TOTP_REQUIRED)
+		}
+		valid = data.OTP == record.OTP
+	case "totp":
+		valid = s.verifyTOTP(ctx, data.Usertag, data.OTP)
+	case "recovery":
+		valid = s.useRecoveryCode(ctx, data.Usertag, data.OTP)
+	default:
+		return nil, apperrors.Invalid(responses.INVALID_MFA_METHOD)
+	}
+	if !valid {
+		log.Println("Invalid OTP for admin login")
+		recordFailure(ctx, s.Repos.Lockouts, accountKey, ipKey)
+		if recordWrongOTP(ctx, s.Repos.Admins, data.Usertag) {
+			return nil, apperrors.Unauthorized(responses.OTP_ATTEMPTS_EXCEEDED)
+		}
+		return nil, apperrors.Unauthorized("invalid OTP")
+	}
+	if err := s.Repos.Admins.ClearOTP(ctx, data.Usertag); err != nil {
 		log.Println("Failed to clear OTP:", err)
 	}
+	clearFailures(ctx, s.Repos.Lockouts, accountKey)
 
-	token, err := utils.GenerateJWT(data.Usertag)
+	// The account may have been suspended or sent to reset its password
+	// since the OTP was sent.
+	switch record.Status {
+	case "invited":
+		return nil, apperrors.Forbidden(responses.ACCOUNT_NOT_ACTIVATED)
+	case "suspended":
+		return nil, apperrors.Forbidden(responses.ACCOUNT_SUSPENDED)
+	}
+	if record.PasswordResetRequired {
+		return nil, apperrors.Forbidden(responses.PASSWORD_RESET_REQUIRED)
+	}
+	if !utils.IsStaffRole(record.Role) {
+		log.Println("Admin has no valid role assigned:", data.Usertag)
+		return nil, apperrors.Unauthorized(responses.UNAUTHORIZED_ACCESS)
+	}
+
+	tokens, err := issueSession(ctx, s.Repos.Sessions, data.Usertag, record.Role, data.ClientInfo)
 	if err != nil {
-		log.Println("Failed to generate JWT token:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		log.Println("Failed to start session:", err)
+		return nil, apperrors.Internal(err)
 	}
 
 	return map[string]interface{}{
-		"message": "Login successful",
-		"token":   token,
+		"message":       "Login successful",
+		"token":         tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	}, nil
 }
 
-func (AdminServer) ForgotPassword(data models.ForgotPassword) (any, error) {
-	var exists string
-	err := Db.QueryRow(Ctx, "SELECT email FROM admins WHERE email = $1", data.Email).Scan(&exists)
+func (s AdminServer) ForgotPassword(ctx context.Context, data models.ForgotPassword) (any, error) {
+	record, err := s.Repos.Admins.GetByEmail(ctx, data.Email)
 	if err != nil {
 		log.Println(err)
-		return nil, errors.New(responses.ACCOUNT_NON_EXISTENT)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Internal(err)
+		}
+		return nil, apperrors.NotFound(responses.ACCOUNT_NON_EXISTENT)
+	}
+	if !otpResendAllowed(record) {
+		return nil, apperrors.RateLimited(responses.OTP_COOLDOWN)
 	}
 
 	otp, err := utils.GenerateOTP()
 	if err != nil {
 		log.Println("Failed to generate OTP:", err)
-		return nil, errors.New("failed to generate OTP")
+		return nil, apperrors.Internal(err)
 	}
 
-	_, err = Db.Exec(Ctx, "UPDATE admins SET otp = $1, otp_expiry = NOW() + INTERVAL '10 minutes' WHERE email = $2", otp, data.Email)
-	if err != nil {
+	if err := s.Repos.Admins.SetOTP(ctx, record.AdminTag, otp, 10*time.Minute, true); err != nil {
 		log.Println("failed to save OTP", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
-	err = utils.SendEmailOTP(data.Email, otp)
+	err = notifications.Send(data.Email, notifications.EventPasswordReset, notifications.OTPData{OTP: otp, ExpiresMinutes: 10})
 	if err != nil {
 		log.Println("Failed to send OTP email:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
 	return nil, err
 }
 
-func (AdminServer) VerifyPwdOTP(data models.VerifyPwdOTP) (any, error) {
-	var dbOtp string
-	var otpExpiryTime time.Time
+func (s AdminServer) VerifyPwdOTP(ctx context.Context, data models.VerifyPwdOTP) (any, error) {
+	ipKey := lockoutKey{lockoutScopeIP, data.IP}
+	if err := checkLockout(ctx, s.Repos.Lockouts, ipKey); err != nil {
+		return nil, err
+	}
 
-	err := Db.QueryRow(Ctx, "SELECT otp, otp_expiry FROM admins WHERE email = $1", data.Email).
-		Scan(&dbOtp, &otpExpiryTime)
-	if err != nil {
-		log.Println(err)
-		return nil, errors.New("invalid email or OTP")
+	record, err := s.Repos.Admins.GetByEmail(ctx, data.Email)
+	if err != nil || record.OTP == "" || record.OTPExpiry == nil {
+		log.Println("No pending OTP for admin:", err)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Internal(err)
+		}
+		recordFailure(ctx, s.Repos.Lockouts, ipKey)
+		return nil, apperrors.Unauthorized("invalid email or OTP")
+	}
+	accountKey := lockoutKey{lockoutScopeAdmin, record.AdminTag}
+	if err := checkLockout(ctx, s.Repos.Lockouts, accountKey); err != nil {
+		return nil, err
 	}
 
-	if data.OTP != dbOtp {
+	if data.OTP != record.OTP {
 		log.Println("Invalid OTP for admin")
-		return nil, errors.New("invalid OTP")
+		recordFailure(ctx, s.Repos.Lockouts, accountKey, ipKey)
+		if recordWrongOTP(ctx, s.Repos.Admins, record.AdminTag) {
+			return nil, apperrors.Unauthorized(responses.OTP_ATTEMPTS_EXCEEDED)
+		}
+		return nil, apperrors.Unauthorized("invalid OTP")
 	}
 
-	if time.Now().After(otpExpiryTime) {
+	if time.Now().After(*record.OTPExpiry) {
 		log.Println("OTP has expired")
-		return nil, errors.New("OTP has expired")
+		return nil, apperrors.Unauthorized("OTP has expired")
 	}
-	_, err = Db.Exec(Ctx, `UPDATE admins SET otp = NULL, otp_expiry = NULL WHERE email = $1`, data.Email)
+	clearFailures(ctx, s.Repos.Lockouts, accountKey)
+
+	// the reset token is what proves to ResetPassword that this OTP was passed
+	resetToken, err := utils.GenerateToken()
 	if err != nil {
-		log.Println("Failed to clear OTP:", err)
+		log.Println("Failed to generate reset token:", err)
+		return nil, apperrors.Internal(err)
+	}
+	if err := s.Repos.Admins.SetResetToken(ctx, record.AdminTag, utils.HashToken(resetToken), 15*time.Minute); err != nil {
+		log.Println("Failed to save reset token:", err)
+		return nil, apperrors.Internal(err)
 	}
 
 	return map[string]interface{}{
-		"message": "OTP verified successfully",
+		"message":     "OTP verified successfully",
+		"reset_token": resetToken,
 	}, nil
 }
 
-func (AdminServer) ResetPassword(data models.ResetPassword) (any, error) {
-	var exists string
-	err := Db.QueryRow(Ctx, "SELECT email FROM admins WHERE email = $1", data.Email).Scan(&exists)
-	if err != nil {
-		log.Println(err)
-		return nil, errors.New(responses.ACCOUNT_NON_EXISTENT)
+// ResetPassword sets a new password for an admin holding a reset token from
+// VerifyPwdOTP. The token is spent on use and every open session is revoked.
+func (s AdminServer) ResetPassword(ctx context.Context, data models.ResetPassword) (any, error) {
+	if data.NewPassword == "" || data.ResetToken == "" {
+		return nil, apperrors.Invalid(responses.INCOMPLETE_DATA)
 	}
 
-	if data.NewPassword == "" {
-		return nil, errors.New(responses.INCOMPLETE_DATA)
+	tokenHash := utils.HashToken(data.ResetToken)
+	record, err := s.Repos.Admins.GetByEmail(ctx, data.Email)
+	if err != nil || record.ResetTokenHash != tokenHash || record.ResetTokenExpiry == nil || time.Now().After(*record.ResetTokenExpiry) {
+		log.Println("Failed to find password reset token:", err)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Internal(err)
+		}
+		return nil, apperrors.Unauthorized(responses.RESET_TOKEN_INVALID)
+	}
+
+	if !utils.IsStrongPassword(data.NewPassword) {
+		return nil, apperrors.Invalid(responses.WEAK_PASSWORD)
+	}
+	if s.passwordReused(ctx, record.AdminTag, data.NewPassword) {
+		return nil, apperrors.Invalid(responses.PASSWORD_REUSED)
 	}
 
 	hashedPwd, err := utils.HashPassword(data.NewPassword)
 	if err != nil {
 		log.Println("Failed to hash password:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
-	_, err = Db.Exec(Ctx, "UPDATE admins SET password = $1 WHERE email = $2", hashedPwd, data.Email)
-	if err != nil {
+	if err := s.Repos.Admins.ResetPassword(ctx, record.AdminTag, tokenHash, hashedPwd, passwordHistoryDepth); err != nil {
 		log.Println("Failed to reset password:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Unauthorized(responses.RESET_TOKEN_INVALID)
+		}
+		return nil, apperrors.Internal(err)
 	}
 
+	revokeAllSessions(ctx, s.Repos.Sessions, record.AdminTag, utils.RoleAdmin)
+
 	return map[string]interface{}{
 		"message": responses.PASSWORD_RESET_SUCCESS,
 	}, nil
 }
 
-func (AdminServer) GetDashboardSummary() (any, error) {
-	var patientsCount, doctorsCount, appointmentsCount, ordersCount, doctorRequests int
-
-	queries := []struct {
-		query string
-		dest  *int
-	}{
-		{"SELECT COUNT(*) FROM users WHERE role = 'user'", &patientsCount},
-		{"SELECT COUNT(*) FROM users WHERE role = 'doctor'", &doctorsCount},
-		{"SELECT COUNT(*) FROM appointments", &appointmentsCount},
-		{"SELECT COUNT(*) FROM orders", &ordersCount},
-		{"SELECT COUNT(*) FROM users WHERE role = 'doctor' AND status = 'pending'", &doctorRequests},
-	}
-
-	for _, q := range queries {
-		if err := Db.QueryRow(Ctx, q.query).Scan(q.dest); err != nil {
-			log.Printf("Dashboard query failed: %s — %v", q.query, err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
+func (s AdminServer) GetDashboardSummary(ctx context.Context) (any, error) {
+	summary, err := s.Repos.Reports.Dashboard(ctx)
+	if err != nil {
+		log.Println("Dashboard query failed:", err)
+		return nil, apperrors.Internal(err)
 	}
-
-	return map[string]interface{}{
-		"patients_count":     patientsCount,
-		"doctors_count":      doctorsCount,
-		"appointments_count": appointmentsCount,
-		"orders_count":       ordersCount,
-		"doctor_requests":    doctorRequests,
-	}, nil
+	return summary, nil
 }
-func (AdminServer) GetAnalytics(data models.AnalyticsReq) (any, error) {
 
+func (s AdminServer) GetAnalytics(ctx context.Context, data models.AnalyticsReq) (any, error) {
 	if data.Metric != "payments" {
-		return nil, errors.New("unsupported metric: " + data.Metric)
-	} else {
-		res, err := getPaymentAnalytics(data.Month, data.Year)
-		if err != nil {
-			log.Printf("Failed to get analytics for %s: %v", data.Metric, err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		return res, nil
-
+		return nil, apperrors.Invalid("unsupported metric: " + data.Metric)
 	}
-
+	res, err := s.Repos.Reports.Payments(ctx, data.Month, data.Year)
+	if err != nil {
+		log.Printf("Failed to get analytics for %s: %v", data.Metric, err)
+		return nil, apperrors.Internal(err)
+	}
+	return res, nil
 }
 
-func getPaymentAnalytics(month, year string) (any, error) {
-
-	var analytics models.AnalyticsResp
-
-	query := `SELECT COALESCE(SUM(amount), 0), COUNT(*) FROM payments WHERE EXTRACT(MONTH FROM payment_date) = $1 AND 
-			EXTRACT(YEAR FROM payment_date) = $2 AND status = 'completed'`
-
-	err := Db.QueryRow(Ctx, query, month, year).Scan(&analytics.Total_amount, &analytics.Payment_count)
+func (s AdminServer) GetAppointments(ctx context.Context, params models.ListParams, loc *time.Location) (models.Page, error) {
+	page, err := s.Repos.Appointments.List(ctx, params)
 	if err != nil {
-		log.Printf("Payment analytics query failed: %v", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return page, listError("appointments", err)
 	}
-
-	average := 0.0
-	if analytics.Payment_count > 0 {
-		average = analytics.Total_amount / float64(analytics.Payment_count)
+	appointments := page.Items.([]models.Appointment)
+	for i := range appointments {
+		appointments[i].Scheduled_at = appointments[i].Scheduled_at.In(loc)
+		appointments[i].Created_at = appointments[i].Created_at.In(loc)
 	}
-
-	analytics.Metric = "payments"
-	analytics.Month = month
-	analytics.Year = year
-	analytics.Average_payment = average
-
-	return analytics, nil
+	return page, nil
 }
 
-func (AdminServer) GetAppointments() (any, error) {
-	//rememmebr to modify to fetch using filters
-	var appointments []models.Appointment
-
-	rows, err := Db.Query(Ctx, "SELECT appointment_id, patient_tag, doctor_tag, scheduled_at, reason, file_url FROM appointments")
+func (s AdminServer) GetAppointmentByID(ctx context.Context, payload models.AppointmentID, loc *time.Location) (any, error) {
+	data, err := s.Repos.Appointments.Get(ctx, payload.ID)
 	if err != nil {
-		log.Println("Failed to fetch appointments:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var appointment models.Appointment
-		if err := rows.Scan(&appointment.ID, &appointment.UserTag, &appointment.DoctorTag, &appointment.Scheduled_at, &appointment.Reason, &appointment.Fileurl, &appointment.Status, &appointment.Created_at); err != nil {
-			log.Println("Failed to scan appointment:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
+		log.Println("Failed to fetch full appointment details:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.APPOINTMENT_NOT_FOUND)
 		}
-		appointments = append(appointments, appointment)
+		return nil, apperrors.Internal(err)
 	}
 
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over appointments:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+	data.Reminders, err = s.Repos.Appointments.Reminders(ctx, payload.ID)
+	if err != nil {
+		log.Println("Failed to fetch appointment reminders:", err)
+		return nil, apperrors.Internal(err)
 	}
 
-	return appointments, nil
-}
-
-func (AdminServer) GetAppointmentByID(payload models.AppointmentID) (any, error) {
-	var data models.AppointmentIDResp
-
-	query := `
-		SELECT a.patient_tag, a.doctor_tag, a.scheduled_at, a.reason, a.file_url, a.status, a.created_at,
-		       u.firstname, u.lastname, u.phone_no, u.gender, u.date_of_birth,
-		       d.fullname, d.price_per_session
-		FROM appointments a
-		JOIN users u ON a.patient_tag = u.usertag
-		JOIN doctors d ON a.doctor_tag = d.doctortag
-		WHERE a.appointment_id = $1
-	`
-
-	err := Db.QueryRow(Ctx, query, payload.ID).Scan(
-		&data.UserTag,
-		&data.DoctorTag,
-		&data.Scheduled_At,
-		&data.Reason,
-		&data.File_URL,
-		&data.Status,
-		&data.Created_At,
-		&data.First_name,
-		&data.Last_name,
-		&data.Phone_No,
-		&data.Gender,
-		&data.Dob,
-		&data.Doctor_Fullname,
-		&data.Price,
-	)
-
+	data.StatusHistory, err = s.Repos.Appointments.StatusHistory(ctx, payload.ID)
 	if err != nil {
-		log.Println("Failed to fetch full appointment details:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		log.Println("Failed to fetch appointment status history:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	data.Scheduled_At = data.Scheduled_At.In(loc)
+	data.Created_At = data.Created_At.In(loc)
+	for i := range data.Reminders {
+		data.Reminders[i].SentAt = data.Reminders[i].SentAt.In(loc)
+	}
+	for i := range data.StatusHistory {
+		data.StatusHistory[i].CreatedAt = data.StatusHistory[i].CreatedAt.In(loc)
 	}
 
 	return data, nil
 }
 
-func (AdminServer) GetDoctorByID(data models.Doctorreq) (any, error) {
-	var doctor models.Doctor
-
-	query := `
-	SELECT 
-		d.doctortag,
-		d.fullname,
-		d.date_of_birth,
-		d.phone_number,
-		d.gender,
-		d.specialization,
-		d.country,
-		d.city,
-		d.yrs_of_experience,
-		d.price_per_session,
-		d.about,
-		d.availability,
-		d.profile_pic_url,
-		h.name AS hospital_affiliation
-	FROM doctors d
-	LEFT JOIN hospitals h ON d.hospital_id = h.hospital_id
-	WHERE d.fullname = $1
-	`
-
-	err := Db.QueryRow(Ctx, query, data).Scan(
-		&doctor.DoctorTag,
-		&doctor.FullName,
-		&doctor.Dob,
-		&doctor.Phone_no,
-		&doctor.Gender,
-		&doctor.Specialization,
-		&doctor.Country,
-		&doctor.City,
-		&doctor.YearsOfExperience,
-		&doctor.Price,
-		&doctor.About,
-		&doctor.Availability,
-		&doctor.ProfilePicURL,
-		&doctor.HospitalAffiliation, // ← from hospitals.name
-	)
-
-	if err != nil {
-		log.Println("Error fetching doctor by fullname:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("doctor not found")
-		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+func (s AdminServer) GetDoctorByID(ctx context.Context, data models.Doctorreq) (any, error) {
+	doctor, err := s.Repos.Doctors.Get(ctx, data.DoctorTag)
+	if err != nil {
+		log.Println("Failed to fetch doctor:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.DOCTOR_NOT_FOUND)
+		}
+		return nil, apperrors.Internal(err)
 	}
-
 	return doctor, nil
 }
 
-func (AdminServer) UpdateAppointmentStatus(payload models.UpdateAppointmentStatus) (any, error) {
-	switch payload.Status {
-	case "cancel":
-		_, err := Db.Exec(Ctx, "UPDATE appointments SET status = 'cancelled' WHERE appointment_id = $2", payload.Status, payload.Appointment_id)
-		if err != nil {
-			log.Println("Failed to update appointment status:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		return map[string]string{"message": "Appointment cancelled successfully"}, nil
-	case "completed":
-		_, err := Db.Exec(Ctx, "UPDATE appointments SET status = 'completed' WHERE appointment_id = $2", payload.Status, payload.Appointment_id)
-		if err != nil {
-			log.Println("Failed to update appointment status:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		return map[string]string{"message": "Appointment completed successfully"}, nil
-	case "pending":
-		_, err := Db.Exec(Ctx, "UPDATE appointments SET status = 'pending' WHERE appointment_id = $2", payload.Status, payload.Appointment_id)
-		if err != nil {
-			log.Println("Failed to update appointment status:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		return map[string]string{"message": "Appointment status updated to pending"}, nil
-	default:
-		return nil, errors.New("invalid appointment status")
+func (s AdminServer) UpdateAppointmentStatus(ctx context.Context, actor models.Actor, payload models.UpdateAppointmentStatus) (any, error) {
+	before := s.snapshot(ctx, "appointments", "appointment_id", payload.Appointment_id)
+	from, err := changeAppointmentStatus(ctx, s.Repos.Appointments, statusChange{
+		appointmentID: payload.Appointment_id,
+		to:            payload.Status,
+		reason:        payload.Reason,
+		changedByType: "admin",
+		changedBy:     actor.AdminTag,
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	s.recordAudit(ctx, actor, "update_status", "appointment", payload.Appointment_id, before, s.snapshot(ctx, "appointments", "appointment_id", payload.Appointment_id))
+	return map[string]string{"appointment_id": payload.Appointment_id, "from_status": from, "status": payload.Status}, nil
 }
 
-func (a *AdminServer) RescheduleAppointment(data models.RescheduleAppointmentReq) (any, error) {
-	_, err := time.Parse(time.RFC3339, data.NewScheduledAt)
+func (s AdminServer) RescheduleAppointment(ctx context.Context, actor models.Actor, data models.RescheduleAppointmentReq) (any, error) {
+	before := s.snapshot(ctx, "appointments", "appointment_id", data.Appointment_id)
+	scheduledAt, err := time.Parse(time.RFC3339, data.NewScheduledAt)
 	if err != nil {
-		return errors.New("invalid datetime format"), nil
+		return nil, apperrors.Invalid(responses.INVALID_DATETIME)
+	}
+	if !scheduledAt.After(time.Now()) {
+		return nil, apperrors.Invalid("appointment must be scheduled in the future")
 	}
-	query := `UPDATE appointments SET scheduled_at = $1 WHERE appointment_id = $2`
-	_, err = Db.Exec(Ctx, query, data.NewScheduledAt, data.Appointment_id)
+
+	var rejected error
+	err = s.Repos.Appointments.Reschedule(ctx, data.Appointment_id, scheduledAt, models.AppointmentStatusChange{}, func(cal repository.Calendar, a models.Appointment) (time.Duration, error) {
+		if a.Status != "pending" && a.Status != "confirmed" {
+			rejected = apperrors.Conflict(responses.APPOINTMENT_CLOSED)
+			return 0, rejected
+		}
+		slot, err := reserveDoctorSlot(ctx, cal, a.DoctorTag, a.Scheduled_at, a.ID)
+		rejected = err
+		return slot, err
+	})
 	if err != nil {
+		if rejected != nil {
+			return nil, rejected
+		}
 		log.Println("Error updating appointment schedule:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.APPOINTMENT_NOT_FOUND)
+		}
+		return nil, apperrors.Internal(err)
 	}
 
+	s.recordAudit(ctx, actor, "reschedule", "appointment", data.Appointment_id, before, s.snapshot(ctx, "appointments", "appointment_id", data.Appointment_id))
 	return map[string]string{"message": "Appointment rescheduled successfully"}, nil
 }
 
-func (AdminServer) GetDoctors() (any, error) {
-	//rememmebr to modify to fetch using filters
-	var doctors []models.Doctor
-
-	rows, err := Db.Query(Ctx, "SELECT doctortag, fullname, date_of_birth, phone_number, gender, specialization, country, yrs_of_experience, price_per_session FROM doctors")
-	if err != nil {
-		log.Println("Failed to fetch doctors:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var doctor models.Doctor
-		if err := rows.Scan(&doctor.DoctorTag, &doctor.FullName, &doctor.Dob, &doctor.Phone_no, &doctor.Gender, &doctor.Specialization, &doctor.Country, &doctor.YearsOfExperience, &doctor.Price); err != nil {
-			log.Println("Failed to scan doctor:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		doctors = append(doctors, doctor)
-	}
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over doctors:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	return doctors, nil
+func (s AdminServer) GetDoctors(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Doctors.List(ctx, params)
+	return page, listError("doctors", err)
 }
 
-func (AdminServer) DeleteDoctor(data models.Doctorreq) error {
-	_, err := Db.Exec(Ctx, "DELETE FROM doctors WHERE doctortag = $1", data.DoctorTag)
-	if err != nil {
+func (s AdminServer) DeleteDoctor(ctx context.Context, actor models.Actor, data models.Doctorreq) error {
+	before := s.snapshot(ctx, "doctors", "doctortag", data.DoctorTag)
+	if err := s.Repos.Doctors.Delete(ctx, data.DoctorTag); err != nil {
 		log.Println("Failed to delete doctor:", err)
-		return errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound(responses.DOCTOR_NOT_FOUND)
+		}
+		return apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "delete", "doctor", data.DoctorTag, before, nil)
 	return nil
 }
 
-func (AdminServer) GetPatients() (any, error) {
-	var patients []models.Patient
+func (s AdminServer) GetDoctorApplications(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Doctors.Applications(ctx, params)
+	return page, listError("doctor applications", err)
+}
 
-	rows, err := Db.Query(Ctx, "SELECT usertag, firstname, lastname, email, phone_no, gender, date_of_birth FROM users WHERE role = 'user'")
+func (s AdminServer) GetDoctorApplicationByID(ctx context.Context, doctorTag string) (any, error) {
+	application, err := s.Repos.Doctors.Application(ctx, doctorTag)
 	if err != nil {
-		log.Println("Failed to fetch patients:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		log.Println("Failed to fetch doctor application:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.APPLICATION_NOT_FOUND)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	return application, nil
+}
+
+// resubmitTTL is how long an applicant has to resubmit an application that
+// was sent back for more information.
+const resubmitTTL = 7 * 24 * time.Hour
+
+// ReviewDoctorApplication approves, rejects or sends back a pending doctor
+// application and emails the applicant the outcome. Sending it back emails a
+// single-use code that Apply needs to accept the resubmission.
+func (s AdminServer) ReviewDoctorApplication(ctx context.Context, actor models.Actor, data models.ReviewDoctorApplication) (any, error) {
+	before := s.snapshot(ctx, "doctors", "doctortag", data.DoctorTag)
+	var subject, body, resubmitTokenHash string
+	switch data.Status {
+	case "approved":
+		subject = "Your Telemed application has been approved"
+		body = "Congratulations, your application has been approved. You can now log in to the doctor portal."
+	case "rejected":
+		if data.Reason == "" {
+			return nil, apperrors.Invalid(responses.REASON_REQUIRED)
+		}
+		subject = "Your Telemed application has been rejected"
+		body = "Unfortunately your application has been rejected for the following reason:\n\n" + data.Reason
+	case "info_requested":
+		if data.Reason == "" {
+			return nil, apperrors.Invalid(responses.REASON_REQUIRED)
+		}
+		token, err := utils.GenerateToken()
+		if err != nil {
+			log.Println("Failed to generate resubmission code:", err)
+			return nil, apperrors.Internal(err)
+		}
+		resubmitTokenHash = utils.HashToken(token)
+		subject = "More information needed for your Telemed application"
+		body = "We need more information before we can review your application:\n\n" + data.Reason +
+			"\n\nPlease resubmit your application with the same email address and this code: " + token +
+			"\n\nThe code expires in 7 days and can only be used once."
+	default:
+		return nil, apperrors.Invalid(responses.INVALID_REVIEW_DECISION)
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var patient models.Patient
-		if err := rows.Scan(&patient.UserTag, &patient.Firstname, &patient.Lastname, &patient.Email, &patient.Phone_no, &patient.Gender, &patient.Dob); err != nil {
-			log.Println("Failed to scan patient:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
+
+	email, err := s.Repos.Doctors.ReviewApplication(ctx, data.DoctorTag, data.Status, data.Reason, resubmitTokenHash, resubmitTTL)
+	if err != nil {
+		log.Println("Failed to review doctor application:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Conflict(responses.APPLICATION_CLOSED)
 		}
-		patients = append(patients, patient)
+		return nil, apperrors.Internal(err)
 	}
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over patients:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+
+	if email != "" {
+		s.Notify.Notice(email, subject, body)
 	}
-	return patients, nil
+
+	s.recordAudit(ctx, actor, "review_application", "doctor", data.DoctorTag, before, s.snapshot(ctx, "doctors", "doctortag", data.DoctorTag))
+	return map[string]string{"doctortag": data.DoctorTag, "status": data.Status}, nil
 }
 
-func (AdminServer) GetPatientByUsertag(data models.PatientIdReq) (any, error) {
-	var patient models.PatientIdResp
-	var firstname, lastname string
+func (s AdminServer) GetPatients(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Patients.List(ctx, params)
+	return page, listError("patients", err)
+}
 
-	query1 := `select u.usertag, u.firstname, u.lastname, u.phone_no, u.gender, u.date_of_birth , ap.doctor_tag, ap.reason, ap.file_url, ap.status
-				from users AS u
-				inner join appointments AS ap
-				on u.usertag = ap.patient_tag
-				where usertag = $1 `
-	err := Db.QueryRow(Ctx, query1, data.Usertag).Scan(&patient.UserTag, &firstname, &lastname, &patient.Phone_No, &patient.Gender, &patient.Dob, &patient.Attending_Doctor, &patient.Reason, &patient.File_URL, &patient.Status)
+func (s AdminServer) GetPatientByUsertag(ctx context.Context, data models.PatientIdReq) (any, error) {
+	patient, err := s.Repos.Patients.Get(ctx, data.Usertag)
 	if err != nil {
 		log.Println("Failed to fetch patient:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("patient not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("patient not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
-	patient.Name = firstname + " " + lastname
-
 	return patient, nil
-
 }
 
-func (AdminServer) DeletePatient(data models.PatientIdReq) error {
-	_, err := Db.Exec(Ctx, "DELETE FROM users WHERE usertag = $1", data.Usertag)
-	if err != nil {
+func (s AdminServer) DeletePatient(ctx context.Context, actor models.Actor, data models.PatientIdReq) error {
+	before := s.snapshot(ctx, "users", "usertag", data.Usertag)
+	if err := s.Repos.Patients.Delete(ctx, data.Usertag); err != nil {
 		log.Println("Failed to delete patient:", err)
-		return errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("patient not found")
+		}
+		return apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "delete", "patient", data.Usertag, before, nil)
 	return nil
 }
 
-func (AdminServer) EditPatient(data models.Patient) (any, error) {
+func (s AdminServer) EditPatient(ctx context.Context, actor models.Actor, data models.Patient) (any, error) {
 	if data.UserTag == "" || data.Firstname == "" || data.Lastname == "" || data.Phone_no == "" || data.Dob == "" {
-		return nil, errors.New(responses.INCOMPLETE_DATA)
+		return nil, apperrors.Invalid(responses.INCOMPLETE_DATA)
 	}
+	before := s.snapshot(ctx, "users", "usertag", data.UserTag)
 
-	query := `UPDATE users SET firstname = $1, lastname = $2, phone_no = $3, date_of_birth = $4 WHERE usertag = $5`
-	_, err := Db.Exec(Ctx, query, data.Firstname, data.Lastname, data.Phone_no, data.Dob, data.UserTag)
-	if err != nil {
+	if err := s.Repos.Patients.Update(ctx, data); err != nil {
 		log.Println("Failed to update patient:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "update", "patient", data.UserTag, before, s.snapshot(ctx, "users", "usertag", data.UserTag))
 	return map[string]string{"message": "Patient updated successfully"}, nil
 }
 
-func (AdminServer) GetPharmacy() (any, error) {
-	var pharmacies []models.Pharmacy
-
-	rows, err := Db.Query(Ctx, "SELECT pharmacy_id, pharmacy_name, address, country, state, about, picture_url FROM pharmacies")
-	if err != nil {
-		log.Println("Failed to fetch pharmacies:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var pharmacy models.Pharmacy
-		if err := rows.Scan(&pharmacy.PharmacyID, &pharmacy.PharmacyName, &pharmacy.Address, &pharmacy.Country, &pharmacy.State, &pharmacy.About, &pharmacy.Picture_url); err != nil {
-			log.Println("Failed to scan pharmacy:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		pharmacies = append(pharmacies, pharmacy)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over pharmacies:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-
-	return pharmacies, nil
+func (s AdminServer) GetPharmacy(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Pharmacies.List(ctx, params)
+	return page, listError("pharmacies", err)
 }
 
-func (AdminServer) CreatePharmacy(data models.Pharmacy) (any, error) {
-	data.PharmacyID = utils.GenerateUUID(data.PharmacyID)
-	query := `INSERT INTO pharmacies (pharmacy_id, pharmacy_name, address, country, state, about, picture_url) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := Db.Exec(Ctx, query, data.PharmacyID, data.PharmacyName, data.Address, data.Country, data.State, data.About, data.Picture_url)
+func (s AdminServer) CreatePharmacy(ctx context.Context, actor models.Actor, data models.Pharmacy) (any, error) {
+	created, err := s.Repos.Pharmacies.Create(ctx, data)
 	if err != nil {
 		log.Println("Failed to create pharmacy:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
-	return map[string]string{"message": "Pharmacy created successfully"}, nil
+	s.recordAudit(ctx, actor, "create", "pharmacy", created.PharmacyID, nil, s.snapshot(ctx, "pharmacies", "pharmacy_id", created.PharmacyID))
+	return created, nil
 }
 
-func (AdminServer) DeletePharmacy(pharmacyID string) error {
-	_, err := Db.Exec(Ctx, "DELETE FROM pharmacies WHERE pharmacy_id = $1", pharmacyID)
-	if err != nil {
+func (s AdminServer) DeletePharmacy(ctx context.Context, actor models.Actor, pharmacyID string) error {
+	before := s.snapshot(ctx, "pharmacies", "pharmacy_id", pharmacyID)
+	if err := s.Repos.Pharmacies.Delete(ctx, pharmacyID); err != nil {
 		log.Println("Failed to delete pharmacy:", err)
-		return errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("pharmacy not found")
+		}
+		return apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "delete", "pharmacy", pharmacyID, before, nil)
 	return nil
 }
 
-func (AdminServer) GetPharmacyByID(pharmacyID string) (any, error) {
-	var pharmacy models.Pharmacy
-	err := Db.QueryRow(Ctx, "SELECT pharmacy_id, pharmacy_name, address, country, state, about, picture_url FROM pharmacies WHERE pharmacy_id = $1", pharmacyID).
-		Scan(&pharmacy.PharmacyID, &pharmacy.PharmacyName, &pharmacy.Address, &pharmacy.Country, &pharmacy.State, &pharmacy.About, &pharmacy.Picture_url)
+func (s AdminServer) GetPharmacyByID(ctx context.Context, pharmacyID string) (any, error) {
+	pharmacy, err := s.Repos.Pharmacies.Get(ctx, pharmacyID)
 	if err != nil {
 		log.Println("Failed to fetch pharmacy by ID:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("pharmacy not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("pharmacy not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
 	return pharmacy, nil
 }
 
-func (AdminServer) UpdatePharmacy(payload models.Pharmacy) (any, error) {
-	query := `UPDATE pharmacies SET pharmacy_name = $1, address = $2, country = $3, state = $4, about = $5, picture_url = $6 WHERE pharmacy_id = $7`
-	_, err := Db.Exec(Ctx, query, payload.PharmacyName, payload.Address, payload.Country, payload.State, payload.About, payload.Picture_url, payload.PharmacyID)
+func (s AdminServer) UpdatePharmacy(ctx context.Context, actor models.Actor, pharmacyID string, patch models.PharmacyPatch) (any, error) {
+	before := s.snapshot(ctx, "pharmacies", "pharmacy_id", pharmacyID)
+	updated, err := s.Repos.Pharmacies.Patch(ctx, pharmacyID, patch)
 	if err != nil {
 		log.Println("Failed to update pharmacy:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	return map[string]string{"message": "Pharmacy updated successfully"}, nil
-}
-
-func (AdminServer) GetHospitals() (any, error) {
-	var hospitals []models.Hospital
-
-	rows, err := Db.Query(Ctx, "SELECT hospital_id, hospital_name, address, country, state, about, picture_url FROM hospitals")
-	if err != nil {
-		log.Println("Failed to fetch hospitals:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var hospital models.Hospital
-		if err := rows.Scan(&hospital.HospitalID, &hospital.HospitalName, &hospital.Address, &hospital.Country, &hospital.State, &hospital.About, &hospital.Picture_url); err != nil {
-			log.Println("Failed to scan hospital:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("pharmacy not found")
 		}
-		hospitals = append(hospitals, hospital)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over hospitals:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "update", "pharmacy", pharmacyID, before, s.snapshot(ctx, "pharmacies", "pharmacy_id", pharmacyID))
+	return updated, nil
+}
 
-	return hospitals, nil
+func (s AdminServer) GetHospitals(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Hospitals.List(ctx, params)
+	return page, listError("hospitals", err)
 }
 
-func (AdminServer) CreateHospital(data models.Hospital) (any, error) {
-	data.HospitalID = utils.GenerateUUID(data.HospitalName)
-	query := `INSERT INTO hospitals (hospital_id, hospital_name, address, country, state, about, picture_url) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := Db.Exec(Ctx, query, data.HospitalID, data.HospitalName, data.Address, data.Country, data.State, data.About, data.Picture_url)
+func (s AdminServer) CreateHospital(ctx context.Context, actor models.Actor, data models.Hospital) (any, error) {
+	created, err := s.Repos.Hospitals.Create(ctx, data)
 	if err != nil {
 		log.Println("Failed to create hospital:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
-	return map[string]string{"message": "Hospital created successfully"}, nil
+	s.recordAudit(ctx, actor, "create", "hospital", created.HospitalID, nil, s.snapshot(ctx, "hospitals", "hospital_id", created.HospitalID))
+	return created, nil
 }
 
-func (AdminServer) DeleteHospital(hospitalID string) error {
-	_, err := Db.Exec(Ctx, "DELETE FROM hospitals WHERE hospital_id = $1", hospitalID)
-	if err != nil {
+func (s AdminServer) DeleteHospital(ctx context.Context, actor models.Actor, hospitalID string) error {
+	before := s.snapshot(ctx, "hospitals", "hospital_id", hospitalID)
+	if err := s.Repos.Hospitals.Delete(ctx, hospitalID); err != nil {
 		log.Println("Failed to delete hospital:", err)
-		return errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("hospital not found")
+		}
+		return apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "delete", "hospital", hospitalID, before, nil)
 	return nil
 }
 
-func (AdminServer) GetHospitalByID(hospitalID string) (any, error) {
-	var hospital models.Hospital
-	err := Db.QueryRow(Ctx, "SELECT hospital_id, hospital_name, address, country, state, about, picture_url FROM hospitals WHERE hospital_id = $1", hospitalID).
-		Scan(&hospital.HospitalID, &hospital.HospitalName, &hospital.Address, &hospital.Country, &hospital.State, &hospital.About, &hospital.Picture_url)
+func (s AdminServer) GetHospitalByID(ctx context.Context, hospitalID string) (any, error) {
+	hospital, err := s.Repos.Hospitals.Get(ctx, hospitalID)
 	if err != nil {
 		log.Println("Failed to fetch hospital by ID:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("hospital not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("hospital not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
 	return hospital, nil
 }
 
-func (AdminServer) UpdateHospital(payload models.Hospital) (any, error) {
-	query := `UPDATE hospitals SET hospital_name = $1, address = $2, country = $3, state = $4, about = $5, picture_url = $6 WHERE hospital_id = $7`
-	_, err := Db.Exec(Ctx, query, payload.HospitalName, payload.Address, payload.Country, payload.State, payload.About, payload.Picture_url, payload.HospitalID)
+func (s AdminServer) UpdateHospital(ctx context.Context, actor models.Actor, hospitalID string, patch models.HospitalPatch) (any, error) {
+	before := s.snapshot(ctx, "hospitals", "hospital_id", hospitalID)
+	updated, err := s.Repos.Hospitals.Patch(ctx, hospitalID, patch)
 	if err != nil {
 		log.Println("Failed to update hospital:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("hospital not found")
+		}
+		return nil, apperrors.Internal(err)
 	}
-	return map[string]string{"message": "Hospital updated successfully"}, nil
+	s.recordAudit(ctx, actor, "update", "hospital", hospitalID, before, s.snapshot(ctx, "hospitals", "hospital_id", hospitalID))
+	return updated, nil
 }
 
-func (AdminServer) GetInventory() (any, error) {
-	var inventory []models.Inventory
-
-	rows, err := Db.Query(Ctx, "SELECT product_id, name, milligram, price, product_image_url FROM inventory")
-	if err != nil {
-		log.Println("Failed to fetch inventory:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var item models.Inventory
-		if err := rows.Scan(&item.ProductID, &item.ProductName, &item.Milligrams, &item.Price, &item.Product_image_url); err != nil {
-			log.Println("Failed to scan inventory item:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		inventory = append(inventory, item)
-	}
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over inventory:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	return inventory, nil
+func (s AdminServer) GetInventory(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Inventory.List(ctx, params)
+	return page, listError("inventory", err)
 }
 
-func (AdminServer) GetInventoryByID(productID string) (any, error) {
-	var item models.Inventory
-	err := Db.QueryRow(Ctx, "SELECT product_id, name, milligram, price, product_image_url FROM inventory WHERE product_id = $1", productID).
-		Scan(&item.ProductID, &item.ProductName, &item.Milligrams, &item.Price, &item.Product_image_url)
+func (s AdminServer) GetInventoryByID(ctx context.Context, productID string) (any, error) {
+	item, err := s.Repos.Inventory.Get(ctx, productID)
 	if err != nil {
 		log.Println("Failed to fetch inventory item by ID:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("inventory item not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("inventory item not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
 	return item, nil
 }
 
-func (AdminServer) CreateInventory(data models.Inventory) (any, error) {
-	data.ProductID = utils.GenerateUUID(data.ProductName) // Generate a unique ID based on product name
-	query := `INSERT INTO inventory ( product_id, name, milligram, price, product_image_url) VALUES ($1, $2, $3, $4)`
-	_, err := Db.Exec(Ctx, query, data.ProductID, data.ProductName, data.Milligrams, data.Price, data.Product_image_url)
+func (s AdminServer) CreateInventory(ctx context.Context, actor models.Actor, data models.Inventory) (any, error) {
+	created, err := s.Repos.Inventory.Create(ctx, data)
 	if err != nil {
 		log.Println("Failed to create inventory item:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
-	return map[string]string{"message": "Inventory item created successfully"}, nil
+	s.recordAudit(ctx, actor, "create", "inventory", created.ProductID, nil, s.snapshot(ctx, "inventory", "product_id", created.ProductID))
+	return created, nil
 }
 
-func (AdminServer) UpdateInventory(payload models.Inventory) (any, error) {
-	query := `UPDATE inventory SET name = $1, milligram = $2, price = $3, product_image_url = $4 WHERE product_id = $5`
-	_, err := Db.Exec(Ctx, query, payload.ProductName, payload.Milligrams, payload.Price, payload.Product_image_url, payload.ProductID)
+func (s AdminServer) UpdateInventory(ctx context.Context, actor models.Actor, productID string, patch models.InventoryPatch) (any, error) {
+	before := s.snapshot(ctx, "inventory", "product_id", productID)
+	updated, err := s.Repos.Inventory.Patch(ctx, productID, patch)
 	if err != nil {
 		log.Println("Failed to update inventory item:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("inventory item not found")
+		}
+		return nil, apperrors.Internal(err)
 	}
-	return map[string]string{"message": "Inventory item updated successfully"}, nil
+	s.recordAudit(ctx, actor, "update", "inventory", productID, before, s.snapshot(ctx, "inventory", "product_id", productID))
+	return updated, nil
 }
 
-func (AdminServer) DeleteInventory(productID string) error {
-	_, err := Db.Exec(Ctx, "DELETE FROM inventory WHERE product_id = $1", productID)
-	if err != nil {
+func (s AdminServer) DeleteInventory(ctx context.Context, actor models.Actor, productID string) error {
+	before := s.snapshot(ctx, "inventory", "product_id", productID)
+	if err := s.Repos.Inventory.Delete(ctx, productID); err != nil {
 		log.Println("Failed to delete inventory item:", err)
-		return errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("inventory item not found")
+		}
+		return apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "delete", "inventory", productID, before, nil)
 	return nil
 }
 
-func (AdminServer) GetOrders() (any, error) {
-	var orders []models.Orders
-
-	rows, err := Db.Query(Ctx, "SELECT order_id, usertag, item_name, quantity, status FROM orders")
-	if err != nil {
-		log.Println("Failed to fetch orders:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var order models.Orders
-		if err := rows.Scan(&order.OrderID, &order.UserTag, &order.ItemName, &order.Quantity, &order.Status); err != nil {
-			log.Println("Failed to scan order:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		orders = append(orders, order)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over orders:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-
-	return orders, nil
+func (s AdminServer) GetOrders(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Orders.List(ctx, params)
+	return page, listError("orders", err)
 }
 
-func (AdminServer) GetOrderByID(orderID string) (any, error) {
-	var order models.Orders
-	err := Db.QueryRow(Ctx, "SELECT order_id, usertag, item_name, quantity, status FROM orders WHERE order_id = $1", orderID).
-		Scan(&order.OrderID, &order.UserTag, &order.ItemName, &order.Quantity, &order.Status)
+func (s AdminServer) GetOrderByID(ctx context.Context, orderID string) (any, error) {
+	order, err := s.Repos.Orders.Get(ctx, orderID)
 	if err != nil {
 		log.Println("Failed to fetch order by ID:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("order not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("order not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
 	return order, nil
 }
 
-func (AdminServer) UpdateOrder(order models.Orders) (any, error) {
-	query := `UPDATE orders SET usertag = $1, item_name = $2, quantity = $3, status = $4 WHERE order_id = $5`
-	_, err := Db.Exec(Ctx, query, order.UserTag, order.ItemName, order.Quantity, order.Status, order.OrderID)
-	if err != nil {
+func (s AdminServer) UpdateOrder(ctx context.Context, actor models.Actor, order models.Orders) (any, error) {
+	before := s.snapshot(ctx, "orders", "order_id", order.OrderID)
+	if err := s.Repos.Orders.Update(ctx, order); err != nil {
 		log.Println("Failed to update order:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
+	if order.Status == "shipped" && before["status"] != "shipped" {
+		s.Notify.OrderShipped(ctx, order.OrderID)
+	}
+	s.recordAudit(ctx, actor, "update", "order", order.OrderID, before, s.snapshot(ctx, "orders", "order_id", order.OrderID))
 	return map[string]string{"message": "Order updated successfully"}, nil
 }
 
-func (AdminServer) GetTestCenters() (any, error) {
-	var testCenters []models.TestCentre
-
-	rows, err := Db.Query(Ctx, "SELECT center_id, name, address, country, state, daily_capacity,  about, availability, test_types, price_per_test FROM test_centers")
-	if err != nil {
-		log.Println("Failed to fetch test centers:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var center models.TestCentre
-		if err := rows.Scan(&center.CentreID, &center.CentreName, &center.Address, &center.Country, &center.State, &center.DailyCapacity, &center.About, &center.Availability, &center.TestType, &center.Price); err != nil {
-			log.Println("Failed to scan test center:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
-		}
-		testCenters = append(testCenters, center)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over test centers:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-
-	return testCenters, nil
+func (s AdminServer) GetTestCenters(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.TestCentres.List(ctx, params)
+	return page, listError("test centers", err)
 }
 
-func (AdminServer) GetTestCenterByID(centerID string) (any, error) {
-	var center models.TestCentre
-	err := Db.QueryRow(Ctx, "SELECT center_id, name, address, country, state, daily_capacity, about, availability, test_types, price_per_test FROM test_centers WHERE center_id = $1", centerID).
-		Scan(&center.CentreID, &center.CentreName, &center.Address, &center.Country, &center.State, &center.DailyCapacity, &center.About, &center.Availability, &center.TestType, &center.Price)
+func (s AdminServer) GetTestCenterByID(ctx context.Context, centerID string) (any, error) {
+	center, err := s.Repos.TestCentres.Get(ctx, centerID)
 	if err != nil {
 		log.Println("Failed to fetch test center by ID:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("test center not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("test center not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
 	return center, nil
 }
 
-func (AdminServer) CreateTestCenter(data models.TestCentre) (any, error) {
-	data.CentreID = utils.GenerateUUID(data.CentreName) // Generate a unique ID based on center name
-	query := `INSERT INTO test_centers (center_id, name, address, country, state, daily_capacity, about, availability, test_types, price_per_test) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := Db.Exec(Ctx, query, data.CentreID, data.CentreName, data.Address, data.Country, data.State, data.DailyCapacity, data.About, data.Availability, data.TestType, data.Price)
+func (s AdminServer) CreateTestCenter(ctx context.Context, actor models.Actor, data models.TestCentre) (any, error) {
+	timezone, err := normalizeTimezone(data.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	data.Timezone = timezone
+	created, err := s.Repos.TestCentres.Create(ctx, data)
 	if err != nil {
 		log.Println("Failed to create test center:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
-	return map[string]string{"message": "Test center created successfully"}, nil
+	s.recordAudit(ctx, actor, "create", "test_centre", created.CentreID, nil, s.snapshot(ctx, "test_centres", "center_id", created.CentreID))
+	return created, nil
 }
 
-func (AdminServer) DeleteTestCenter(centerID string) error {
-	_, err := Db.Exec(Ctx, "DELETE FROM test_centers WHERE center_id = $1", centerID)
-	if err != nil {
+func (s AdminServer) DeleteTestCenter(ctx context.Context, actor models.Actor, centerID string) error {
+	before := s.snapshot(ctx, "test_centres", "center_id", centerID)
+	if err := s.Repos.TestCentres.Delete(ctx, centerID); err != nil {
 		log.Println("Failed to delete test center:", err)
-		return errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("test center not found")
+		}
+		return apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "delete", "test_centre", centerID, before, nil)
 	return nil
 }
 
-func (AdminServer) UpdateTestCenter(payload models.TestCentre) (any, error) {
-	query := `UPDATE test_centers SET name = $1, address = $2, country = $3, state = $4, daily_capacity = $5, about = $6, availability = $7, test_types = $8, price_per_test = $9 WHERE center_id = $10`
-	_, err := Db.Exec(Ctx, query, payload.CentreName, payload.Address, payload.Country, payload.State, payload.DailyCapacity, payload.About, payload.Availability, payload.TestType, payload.Price, payload.CentreID)
-	if err != nil {
-		log.Println("Failed to update test center:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+func (s AdminServer) UpdateTestCenter(ctx context.Context, actor models.Actor, centerID string, patch models.TestCentrePatch) (any, error) {
+	if patch.Timezone != nil {
+		timezone, err := normalizeTimezone(*patch.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		patch.Timezone = &timezone
 	}
-	return map[string]string{"message": "Test center updated successfully"}, nil
-}
-
-func (AdminServer) GetReviews(payload models.Getreviews) (any, error) {
-	var reviews []models.Reviews
-
-	rows, err := Db.Query(Ctx, "SELECT review_id, usertag, doctor_tag, review, star_rating,  status FROM reviews WHERE status = $1", payload.Status)
+	before := s.snapshot(ctx, "test_centres", "center_id", centerID)
+	updated, err := s.Repos.TestCentres.Patch(ctx, centerID, patch)
 	if err != nil {
-		log.Println("Failed to fetch reviews:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var review models.Reviews
-		if err := rows.Scan(&review.ReviewID, &review.UserTag, &review.DoctorTag, &review.Review, &review.Rating, &review.Status); err != nil {
-			log.Println("Failed to scan review:", err)
-			return nil, errors.New(responses.SOMETHING_WRONG)
+		log.Println("Failed to update test center:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("test center not found")
 		}
-		reviews = append(reviews, review)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Println("Error iterating over reviews:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "update", "test_centre", centerID, before, s.snapshot(ctx, "test_centres", "center_id", centerID))
+	return updated, nil
+}
 
-	return reviews, nil
+func (s AdminServer) GetReviews(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Reviews.List(ctx, params)
+	return page, listError("reviews", err)
 }
 
-func (AdminServer) GetReviewByID(reviewID string) (any, error) {
-	var review models.Reviews
-	err := Db.QueryRow(Ctx, "SELECT review_id, usertag, doctor_tag, review, star_rating, status FROM reviews WHERE review_id = $1", reviewID).
-		Scan(&review.ReviewID, &review.UserTag, &review.DoctorTag, &review.Review, &review.Rating, &review.Status)
+func (s AdminServer) GetReviewByID(ctx context.Context, reviewID string) (any, error) {
+	review, err := s.Repos.Reviews.Get(ctx, reviewID)
 	if err != nil {
 		log.Println("Failed to fetch review by ID:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("review not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("review not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 
 	return review, nil
 }
 
-func (AdminServer) DeleteReview(reviewID string) error {
-	_, err := Db.Exec(Ctx, "DELETE FROM reviews WHERE review_id = $1", reviewID)
-	if err != nil {
+func (s AdminServer) DeleteReview(ctx context.Context, actor models.Actor, reviewID string) error {
+	before := s.snapshot(ctx, "reviews", "review_id", reviewID)
+	if err := s.Repos.Reviews.Delete(ctx, reviewID); err != nil {
 		log.Println("Failed to delete review:", err)
-		return errors.New(responses.SOMETHING_WRONG)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound("review not found")
+		}
+		return apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "delete", "review", reviewID, before, nil)
 	return nil
 }
 
-func (AdminServer) GetAdminProfile(AdminTag string) (any, error) {
-	var admin models.AdminProfile
-
-	err := Db.QueryRow(Ctx, "SELECT email, firstname, lastname, profile_pic_url from admins WHERE admintag = $1", AdminTag).Scan(&admin.Email, &admin.Firstname, &admin.Lastname, &admin.ProfilePicURL)
+func (s AdminServer) GetAdminProfile(ctx context.Context, AdminTag string) (any, error) {
+	admin, err := s.Repos.Admins.Profile(ctx, AdminTag)
 	if err != nil {
 		log.Println("Failed to fetch admin profile:", err)
-		if err.Error() == "no rows in result set" {
-			return nil, errors.New("admin not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound("admin not found")
 		}
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
 	return admin, nil
 }
 
-func (AdminServer) UpdateAdminProfile(data models.AdminProfile) (any, error) {
-
-	query := `UPDATE admins SET firstname = $1, lastname = $2, profile_pic_url = $3 , email = $4 where admintag = $5`
-	_, err := Db.Exec(Ctx, query, data.Firstname, data.Lastname, data.ProfilePicURL, data.Email, data.AdminTag)
+func (s AdminServer) UpdateAdminProfile(ctx context.Context, actor models.Actor, data models.AdminProfile) (any, error) {
+	timezone, err := normalizeTimezone(data.Timezone)
 	if err != nil {
+		return nil, err
+	}
+	data.Timezone = timezone
+	before := s.snapshot(ctx, "admins", "admintag", data.AdminTag)
+
+	if err := s.Repos.Admins.UpdateProfile(ctx, data); err != nil {
 		log.Println("Failed to update admin profile:", err)
-		return nil, errors.New(responses.SOMETHING_WRONG)
+		return nil, apperrors.Internal(err)
 	}
+	s.recordAudit(ctx, actor, "update_profile", "admin", data.AdminTag, before, s.snapshot(ctx, "admins", "admintag", data.AdminTag))
 	return map[string]string{"message": "Admin profile updated successfully"}, nil
 }
 
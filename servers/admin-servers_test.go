@@ -0,0 +1,845 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"telemed/apperrors"
+	"telemed/config"
+	"telemed/database"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+	"telemed/utils"
+	"telemed/validation"
+	"testing"
+	"time"
+)
+
+type notice struct{ email, subject, body string }
+
+// recordingNotifier keeps what the admin server would have sent.
+type recordingNotifier struct {
+	notices []notice
+	shipped []string
+}
+
+func (n *recordingNotifier) Notice(email, subject, body string) {
+	n.notices = append(n.notices, notice{email, subject, body})
+}
+
+func (n *recordingNotifier) OrderShipped(ctx context.Context, orderID string) {
+	n.shipped = append(n.shipped, orderID)
+}
+
+var testActor = models.Actor{AdminTag: "admin-1", Role: utils.RoleAdmin, IP: "127.0.0.1", RequestID: "req-1"}
+
+var scheduledAt = time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+// newTestAdminServer returns an admin server over a seeded in-memory store:
+// patient p-1 with appointment a-1 (pending) with doctor d-1 (approved),
+// a pending application from d-2, order o-1 and admin admin-1.
+func newTestAdminServer() (AdminServer, *repository.Memory, *recordingNotifier) {
+	store := repository.NewMemory()
+	store.AddPatient(models.Patient{UserTag: "p-1", Firstname: "Ada", Lastname: "Obi", Phone_no: "0800", Dob: "1990-01-01"})
+	store.AddDoctor(models.DoctorApplication{DoctorTag: "d-1", FullName: "Dr One", Email: "one@example.com", Status: "approved"})
+	store.AddDoctor(models.DoctorApplication{DoctorTag: "d-2", FullName: "Dr Two", Email: "two@example.com", Status: "pending"})
+	store.AddAppointment(models.Appointment{ID: "a-1", UserTag: "p-1", DoctorTag: "d-1", Scheduled_at: scheduledAt, Status: "pending", Created_at: scheduledAt})
+	store.AddOrder(models.Orders{OrderID: "o-1", UserTag: "p-1", ItemName: "Paracetamol", Quantity: 2, Status: "pending"})
+	store.AddAdmin(models.AdminRecord{
+		AdminAccount: models.AdminAccount{AdminTag: "admin-1", Firstname: "Sam", Lastname: "Lee", Role: "admin", Status: "active"},
+		MFAMethod:    "email",
+		Timezone:     "UTC",
+	})
+
+	notifier := &recordingNotifier{}
+	return AdminServer{Repos: store.Repositories(), Notify: notifier}, store, notifier
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestAdminServerNotFound(t *testing.T) {
+	s, _, _ := newTestAdminServer()
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		get  func() (any, error)
+		want string
+	}{
+		{"appointment", func() (any, error) { return s.GetAppointmentByID(ctx, models.AppointmentID{ID: "missing"}, time.UTC) }, responses.APPOINTMENT_NOT_FOUND},
+		{"doctor application", func() (any, error) { return s.GetDoctorApplicationByID(ctx, "missing") }, responses.APPLICATION_NOT_FOUND},
+		{"patient", func() (any, error) { return s.GetPatientByUsertag(ctx, models.PatientIdReq{Usertag: "missing"}) }, "patient not found"},
+		{"pharmacy", func() (any, error) { return s.GetPharmacyByID(ctx, "missing") }, "pharmacy not found"},
+		{"hospital", func() (any, error) { return s.GetHospitalByID(ctx, "missing") }, "hospital not found"},
+		{"inventory", func() (any, error) { return s.GetInventoryByID(ctx, "missing") }, "inventory item not found"},
+		{"order", func() (any, error) { return s.GetOrderByID(ctx, "missing") }, "order not found"},
+		{"test center", func() (any, error) { return s.GetTestCenterByID(ctx, "missing") }, "test center not found"},
+		{"review", func() (any, error) { return s.GetReviewByID(ctx, "missing") }, "review not found"},
+		{"admin", func() (any, error) { return s.GetAdminProfile(ctx, "missing") }, "admin not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.get()
+			if errorText(err) != tt.want {
+				t.Errorf("got error %q, want %q", errorText(err), tt.want)
+			}
+			if kind := apperrors.KindOf(err); kind != apperrors.KindNotFound {
+				t.Errorf("got kind %v, want KindNotFound", kind)
+			}
+		})
+	}
+}
+
+func TestAdminServerUpdateAppointmentStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []models.UpdateAppointmentStatus
+		wantErr string
+		want    string
+	}{
+		{
+			name:  "pending to confirmed",
+			steps: []models.UpdateAppointmentStatus{{Appointment_id: "a-1", Status: "confirmed"}},
+			want:  "confirmed",
+		},
+		{
+			name: "confirmed to completed",
+			steps: []models.UpdateAppointmentStatus{
+				{Appointment_id: "a-1", Status: "confirmed"},
+				{Appointment_id: "a-1", Status: "completed"},
+			},
+			want: "completed",
+		},
+		{
+			name:    "pending to completed is not allowed",
+			steps:   []models.UpdateAppointmentStatus{{Appointment_id: "a-1", Status: "completed"}},
+			wantErr: responses.INVALID_STATUS_TRANSITION + " from pending to completed",
+			want:    "pending",
+		},
+		{
+			name:    "unknown status",
+			steps:   []models.UpdateAppointmentStatus{{Appointment_id: "a-1", Status: "archived"}},
+			wantErr: responses.INVALID_STATUS,
+			want:    "pending",
+		},
+		{
+			name:    "cancelling needs a reason",
+			steps:   []models.UpdateAppointmentStatus{{Appointment_id: "a-1", Status: "cancelled", Reason: "  "}},
+			wantErr: responses.CANCELLATION_REASON_REQUIRED,
+			want:    "pending",
+		},
+		{
+			name:    "missing appointment",
+			steps:   []models.UpdateAppointmentStatus{{Appointment_id: "missing", Status: "confirmed"}},
+			wantErr: responses.APPOINTMENT_NOT_FOUND,
+			want:    "pending",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, _ := newTestAdminServer()
+			ctx := context.Background()
+			var err error
+			for _, step := range tt.steps {
+				if _, err = s.UpdateAppointmentStatus(ctx, testActor, step); err != nil {
+					break
+				}
+			}
+			if errorText(err) != tt.wantErr {
+				t.Fatalf("got error %q, want %q", errorText(err), tt.wantErr)
+			}
+
+			got, err := s.GetAppointmentByID(ctx, models.AppointmentID{ID: "a-1"}, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			details := got.(models.AppointmentIDResp)
+			if details.Status != tt.want {
+				t.Errorf("status is %q, want %q", details.Status, tt.want)
+			}
+			if tt.wantErr == "" {
+				if len(details.StatusHistory) != len(tt.steps) {
+					t.Fatalf("got %d history entries, want %d", len(details.StatusHistory), len(tt.steps))
+				}
+				last := details.StatusHistory[len(details.StatusHistory)-1]
+				if last.ToStatus != tt.want || last.ChangedByType != "admin" || last.ChangedBy != testActor.AdminTag {
+					t.Errorf("unexpected history entry %+v", last)
+				}
+				if n := len(store.AuditEntries()); n != len(tt.steps) {
+					t.Errorf("got %d audit entries, want %d", n, len(tt.steps))
+				}
+			} else if len(details.StatusHistory) != 0 || len(store.AuditEntries()) != 0 {
+				t.Errorf("a rejected change left history %v and audit %v", details.StatusHistory, store.AuditEntries())
+			}
+		})
+	}
+}
+
+func TestAdminServerRescheduleAppointmentRejected(t *testing.T) {
+	s, store, _ := newTestAdminServer()
+	ctx := context.Background()
+	store.AddAppointment(models.Appointment{ID: "a-2", UserTag: "p-1", DoctorTag: "d-1", Scheduled_at: scheduledAt, Status: "completed", Created_at: scheduledAt})
+	future := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name string
+		req  models.RescheduleAppointmentReq
+		want string
+		kind apperrors.Kind
+	}{
+		{"missing appointment", models.RescheduleAppointmentReq{Appointment_id: "missing", NewScheduledAt: future}, responses.APPOINTMENT_NOT_FOUND, apperrors.KindNotFound},
+		{"closed appointment", models.RescheduleAppointmentReq{Appointment_id: "a-2", NewScheduledAt: future}, responses.APPOINTMENT_CLOSED, apperrors.KindConflict},
+		{"time in the past", models.RescheduleAppointmentReq{Appointment_id: "a-1", NewScheduledAt: "2020-01-01T09:00:00Z"}, "appointment must be scheduled in the future", apperrors.KindValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RescheduleAppointment(ctx, testActor, tt.req)
+			if errorText(err) != tt.want {
+				t.Errorf("got error %q, want %q", errorText(err), tt.want)
+			}
+			if kind := apperrors.KindOf(err); kind != tt.kind {
+				t.Errorf("got kind %v, want %v", kind, tt.kind)
+			}
+		})
+	}
+	if entries := store.AuditEntries(); len(entries) != 0 {
+		t.Errorf("rejected reschedules were audited: %v", entries)
+	}
+}
+
+func TestAdminServerRescheduleAppointment(t *testing.T) {
+	s, store, _ := newTestAdminServer()
+	ctx := context.Background()
+	weekly := []models.WeeklyHours{}
+	for day := 0; day < 7; day++ {
+		weekly = append(weekly, models.WeeklyHours{Weekday: day, Start: "09:00", End: "17:00"})
+	}
+	store.AddSchedule(models.DoctorSchedule{DoctorTag: "d-1", Timezone: "UTC", SlotMinutes: 30, Weekly: weekly})
+	day := time.Now().UTC().AddDate(0, 0, 2)
+	at := time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, time.UTC)
+	store.AddAppointment(models.Appointment{ID: "a-2", UserTag: "p-1", DoctorTag: "d-1", Scheduled_at: at.Add(time.Hour), Status: "confirmed", Created_at: scheduledAt})
+	if _, err := s.Repos.Reminders.Claim(ctx, "a-1", utils.RolePatient, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RescheduleAppointment(ctx, testActor, models.RescheduleAppointmentReq{Appointment_id: "a-1", NewScheduledAt: at.Format(time.RFC3339)}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Repos.Appointments.Get(ctx, "a-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Scheduled_At.Equal(at) || got.Status != "pending" {
+		t.Errorf("appointment is %s at %v, want pending at %v", got.Status, got.Scheduled_At, at)
+	}
+	if reminders, _ := s.Repos.Appointments.Reminders(ctx, "a-1"); len(reminders) != 0 {
+		t.Errorf("reminders for the old time were kept: %v", reminders)
+	}
+	if history, _ := s.Repos.Appointments.StatusHistory(ctx, "a-1"); len(history) != 0 {
+		t.Errorf("an admin reschedule changed the status history: %v", history)
+	}
+	if entries := store.AuditEntries(); len(entries) != 1 || entries[0].Action != "reschedule" {
+		t.Errorf("audit entries are %v, want one reschedule", entries)
+	}
+
+	_, err = s.RescheduleAppointment(ctx, testActor, models.RescheduleAppointmentReq{Appointment_id: "a-1", NewScheduledAt: at.Add(time.Hour).Format(time.RFC3339)})
+	if errorText(err) != responses.SLOT_TAKEN {
+		t.Errorf("moving onto a-2 got error %q, want %q", errorText(err), responses.SLOT_TAKEN)
+	}
+	_, err = s.RescheduleAppointment(ctx, testActor, models.RescheduleAppointmentReq{Appointment_id: "a-1", NewScheduledAt: at.Add(10 * time.Minute).Format(time.RFC3339)})
+	if errorText(err) != responses.SLOT_UNAVAILABLE {
+		t.Errorf("moving off the slot grid got error %q, want %q", errorText(err), responses.SLOT_UNAVAILABLE)
+	}
+}
+
+func TestAdminServerGetAppointmentsInTimezone(t *testing.T) {
+	s, _, _ := newTestAdminServer()
+	ctx := context.Background()
+	lagos, err := time.LoadLocation("Africa/Lagos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := s.GetAppointments(ctx, models.ListParams{}, lagos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appointments := page.Items.([]models.Appointment)
+	if len(appointments) != 1 {
+		t.Fatalf("got %d appointments, want 1", len(appointments))
+	}
+	if got := appointments[0].Scheduled_at; got.Location() != lagos || !got.Equal(scheduledAt) || got.Hour() != 10 {
+		t.Errorf("scheduled_at is %v, want %v in Africa/Lagos", got, scheduledAt)
+	}
+}
+
+func TestAdminServerListLimits(t *testing.T) {
+	s, _, _ := newTestAdminServer()
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr bool
+	}{
+		{"default", 0, false},
+		{"one", 1, false},
+		{"max", database.MaxLimit, false},
+		{"negative", -1, true},
+		{"over max", database.MaxLimit + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetPatients(ctx, models.ListParams{Limit: tt.limit})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && (!errors.Is(err, database.ErrBadList) || apperrors.KindOf(err) != apperrors.KindValidation) {
+				t.Errorf("got %v, want an ErrBadList validation error", err)
+			}
+		})
+	}
+}
+
+func TestAdminServerReviewDoctorApplication(t *testing.T) {
+	tests := []struct {
+		name        string
+		review      models.ReviewDoctorApplication
+		wantErr     string
+		wantStatus  string
+		wantSubject string
+	}{
+		{
+			name:        "approve",
+			review:      models.ReviewDoctorApplication{DoctorTag: "d-2", Status: "approved"},
+			wantStatus:  "approved",
+			wantSubject: "Your Telemed application has been approved",
+		},
+		{
+			name:        "reject",
+			review:      models.ReviewDoctorApplication{DoctorTag: "d-2", Status: "rejected", Reason: "Licence expired"},
+			wantStatus:  "rejected",
+			wantSubject: "Your Telemed application has been rejected",
+		},
+		{
+			name:        "request info",
+			review:      models.ReviewDoctorApplication{DoctorTag: "d-2", Status: "info_requested", Reason: "Upload your licence"},
+			wantStatus:  "info_requested",
+			wantSubject: "More information needed for your Telemed application",
+		},
+		{
+			name:       "reject without a reason",
+			review:     models.ReviewDoctorApplication{DoctorTag: "d-2", Status: "rejected"},
+			wantErr:    responses.REASON_REQUIRED,
+			wantStatus: "pending",
+		},
+		{
+			name:       "unknown decision",
+			review:     models.ReviewDoctorApplication{DoctorTag: "d-2", Status: "maybe"},
+			wantErr:    responses.INVALID_REVIEW_DECISION,
+			wantStatus: "pending",
+		},
+		{
+			name:       "already reviewed",
+			review:     models.ReviewDoctorApplication{DoctorTag: "d-1", Status: "rejected", Reason: "Too late"},
+			wantErr:    responses.APPLICATION_CLOSED,
+			wantStatus: "approved",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, notifier := newTestAdminServer()
+			ctx := context.Background()
+			_, err := s.ReviewDoctorApplication(ctx, testActor, tt.review)
+			if errorText(err) != tt.wantErr {
+				t.Fatalf("got error %q, want %q", errorText(err), tt.wantErr)
+			}
+
+			application, err := s.GetDoctorApplicationByID(ctx, tt.review.DoctorTag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := application.(models.DoctorApplication).Status; got != tt.wantStatus {
+				t.Errorf("status is %q, want %q", got, tt.wantStatus)
+			}
+
+			if tt.wantSubject == "" {
+				if len(notifier.notices) != 0 {
+					t.Errorf("sent %v, want nothing", notifier.notices)
+				}
+				return
+			}
+			if len(notifier.notices) != 1 {
+				t.Fatalf("sent %d notices, want 1", len(notifier.notices))
+			}
+			sent := notifier.notices[0]
+			if sent.email != "two@example.com" || sent.subject != tt.wantSubject || !strings.Contains(sent.body, tt.review.Reason) {
+				t.Errorf("unexpected notice %+v", sent)
+			}
+		})
+	}
+}
+
+func TestAdminServerEditPatient(t *testing.T) {
+	complete := models.Patient{UserTag: "p-1", Firstname: "Ada", Lastname: "Okafor", Phone_no: "0801", Dob: "1990-01-01"}
+	tests := []struct {
+		name     string
+		edit     func(p *models.Patient)
+		wantErr  string
+		wantName string
+	}{
+		{"complete", func(p *models.Patient) {}, "", "Ada Okafor"},
+		{"no usertag", func(p *models.Patient) { p.UserTag = "" }, responses.INCOMPLETE_DATA, "Ada Obi"},
+		{"no lastname", func(p *models.Patient) { p.Lastname = "" }, responses.INCOMPLETE_DATA, "Ada Obi"},
+		{"no phone", func(p *models.Patient) { p.Phone_no = "" }, responses.INCOMPLETE_DATA, "Ada Obi"},
+		{"no date of birth", func(p *models.Patient) { p.Dob = "" }, responses.INCOMPLETE_DATA, "Ada Obi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, _ := newTestAdminServer()
+			ctx := context.Background()
+			patient := complete
+			tt.edit(&patient)
+			_, err := s.EditPatient(ctx, testActor, patient)
+			if errorText(err) != tt.wantErr {
+				t.Fatalf("got error %q, want %q", errorText(err), tt.wantErr)
+			}
+
+			got, err := s.GetPatientByUsertag(ctx, models.PatientIdReq{Usertag: "p-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name := got.(models.PatientIdResp).Name; name != tt.wantName {
+				t.Errorf("name is %q, want %q", name, tt.wantName)
+			}
+			if wantAudit := tt.wantErr == ""; wantAudit != (len(store.AuditEntries()) == 1) {
+				t.Errorf("got audit entries %v", store.AuditEntries())
+			}
+		})
+	}
+}
+
+func TestAdminServerUpdateOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []string
+		wantShipped int
+	}{
+		{"not shipped", []string{"cancelled"}, 0},
+		{"shipped", []string{"shipped"}, 1},
+		{"shipped twice", []string{"shipped", "shipped"}, 1},
+		{"shipped then delivered", []string{"shipped", "delivered"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, notifier := newTestAdminServer()
+			ctx := context.Background()
+			for _, status := range tt.statuses {
+				order := models.Orders{OrderID: "o-1", UserTag: "p-1", ItemName: "Paracetamol", Quantity: 2, Status: status}
+				if errs := validation.Struct(order); errs != nil {
+					t.Fatalf("order with status %q is not valid: %v", status, errs)
+				}
+				if _, err := s.UpdateOrder(ctx, testActor, order); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(notifier.shipped) != tt.wantShipped {
+				t.Errorf("sent %d shipping notices, want %d", len(notifier.shipped), tt.wantShipped)
+			}
+		})
+	}
+}
+
+func TestAdminServerAuditTrail(t *testing.T) {
+	s, store, _ := newTestAdminServer()
+	ctx := context.Background()
+
+	created, err := s.CreateHospital(ctx, testActor, models.Hospital{HospitalName: "General", Address: "1 Main St"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hospital := created.(models.Hospital)
+	if hospital.HospitalID == "" {
+		t.Fatal("created hospital has no id")
+	}
+	address := "2 High St"
+	if _, err := s.UpdateHospital(ctx, testActor, hospital.HospitalID, models.HospitalPatch{Address: &address}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteHospital(ctx, testActor, hospital.HospitalID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetHospitalByID(ctx, hospital.HospitalID); errorText(err) != "hospital not found" {
+		t.Errorf("got error %q after delete, want hospital not found", errorText(err))
+	}
+
+	entries := store.AuditEntries()
+	tests := []struct {
+		action     string
+		wantBefore string
+		wantAfter  string
+	}{
+		{"create", "", "1 Main St"},
+		{"update", "1 Main St", "2 High St"},
+		{"delete", "2 High St", ""},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d audit entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			entry := entries[i]
+			if entry.Action != tt.action || entry.EntityType != "hospital" || entry.EntityID != hospital.HospitalID {
+				t.Fatalf("unexpected entry %+v", entry)
+			}
+			if entry.ActorTag != testActor.AdminTag || entry.IP != testActor.IP || entry.RequestID != testActor.RequestID {
+				t.Errorf("entry does not record the actor: %+v", entry)
+			}
+			if got, _ := entry.Before["address"].(string); tt.wantBefore != "" && got != tt.wantBefore {
+				t.Errorf("before address is %q, want %q", got, tt.wantBefore)
+			}
+			if got, _ := entry.After["address"].(string); got != tt.wantAfter {
+				t.Errorf("after address is %q, want %q", got, tt.wantAfter)
+			}
+			if tt.action == "create" && entry.Before != nil {
+				t.Errorf("create has a before snapshot %v", entry.Before)
+			}
+		})
+	}
+}
+
+func TestAdminServerPatchPharmacy(t *testing.T) {
+	name, empty := "Northside Pharmacy", ""
+	tests := []struct {
+		name    string
+		id      string
+		patch   models.PharmacyPatch
+		wantErr string
+		want    models.Pharmacy
+	}{
+		{
+			name:  "sets only what was sent",
+			patch: models.PharmacyPatch{PharmacyName: &name},
+			want:  models.Pharmacy{PharmacyName: name, Address: "1 Main St", Country: "Nigeria", State: "Lagos", About: "Open late"},
+		},
+		{
+			name:  "can clear an optional field",
+			patch: models.PharmacyPatch{About: &empty},
+			want:  models.Pharmacy{PharmacyName: "Central", Address: "1 Main St", Country: "Nigeria", State: "Lagos"},
+		},
+		{
+			name: "empty patch changes nothing",
+			want: models.Pharmacy{PharmacyName: "Central", Address: "1 Main St", Country: "Nigeria", State: "Lagos", About: "Open late"},
+		},
+		{
+			name:    "unknown pharmacy",
+			id:      "missing",
+			patch:   models.PharmacyPatch{PharmacyName: &name},
+			wantErr: "pharmacy not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newTestAdminServer()
+			ctx := context.Background()
+			created, err := s.CreatePharmacy(ctx, testActor, models.Pharmacy{PharmacyName: "Central", Address: "1 Main St", Country: "Nigeria", State: "Lagos", About: "Open late"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := created.(models.Pharmacy).PharmacyID
+			if tt.id != "" {
+				id = tt.id
+			}
+
+			got, err := s.UpdatePharmacy(ctx, testActor, id, tt.patch)
+			if errorText(err) != tt.wantErr {
+				t.Fatalf("got error %q, want %q", errorText(err), tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			tt.want.PharmacyID = id
+			if got.(models.Pharmacy) != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminServerDeleteMissing(t *testing.T) {
+	s, store, _ := newTestAdminServer()
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		delete func() error
+		want   string
+	}{
+		{"pharmacy", func() error { return s.DeletePharmacy(ctx, testActor, "missing") }, "pharmacy not found"},
+		{"hospital", func() error { return s.DeleteHospital(ctx, testActor, "missing") }, "hospital not found"},
+		{"inventory", func() error { return s.DeleteInventory(ctx, testActor, "missing") }, "inventory item not found"},
+		{"test center", func() error { return s.DeleteTestCenter(ctx, testActor, "missing") }, "test center not found"},
+		{"doctor", func() error { return s.DeleteDoctor(ctx, testActor, models.Doctorreq{DoctorTag: "missing"}) }, responses.DOCTOR_NOT_FOUND},
+		{"patient", func() error { return s.DeletePatient(ctx, testActor, models.PatientIdReq{Usertag: "missing"}) }, "patient not found"},
+		{"review", func() error { return s.DeleteReview(ctx, testActor, "missing") }, "review not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.delete()
+			if errorText(err) != tt.want {
+				t.Errorf("got error %q, want %q", errorText(err), tt.want)
+			}
+			if kind := apperrors.KindOf(err); kind != apperrors.KindNotFound {
+				t.Errorf("got kind %v, want KindNotFound", kind)
+			}
+		})
+	}
+	if entries := store.AuditEntries(); len(entries) != 0 {
+		t.Errorf("failed deletes were audited: %v", entries)
+	}
+}
+
+func TestAdminServerTestCentreTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		wantErr  bool
+	}{
+		{"valid", "Africa/Lagos", false},
+		{"invalid", "Mars/Olympus_Mons", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, _ := newTestAdminServer()
+			ctx := context.Background()
+			_, err := s.CreateTestCenter(ctx, testActor, models.TestCentre{CentreName: "Lab", Timezone: tt.timezone})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			page, err := s.GetTestCenters(ctx, models.ListParams{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			centres := page.Items.([]models.TestCentre)
+			if tt.wantErr {
+				if len(centres) != 0 || len(store.AuditEntries()) != 0 {
+					t.Errorf("an invalid centre was stored: %v", centres)
+				}
+				return
+			}
+			if len(centres) != 1 || centres[0].Timezone != tt.timezone {
+				t.Errorf("got centres %v", centres)
+			}
+		})
+	}
+}
+
+func TestAdminServerUpdateAdminProfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		timezone     string
+		wantErr      bool
+		wantTimezone string
+	}{
+		{"valid timezone", "Europe/London", false, "Europe/London"},
+		{"invalid timezone", "Nowhere/Special", true, "UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newTestAdminServer()
+			ctx := context.Background()
+			_, err := s.UpdateAdminProfile(ctx, testActor, models.AdminProfile{AdminTag: "admin-1", Firstname: "Sam", Lastname: "Lee", Timezone: tt.timezone})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			profile, err := s.GetAdminProfile(ctx, "admin-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := profile.(models.AdminProfile).Timezone; got != tt.wantTimezone {
+				t.Errorf("timezone is %q, want %q", got, tt.wantTimezone)
+			}
+		})
+	}
+}
+
+func TestAdminServerLogin(t *testing.T) {
+	config.JwtSecret = "test-secret"
+	s, store, _ := newTestAdminServer()
+	ctx := context.Background()
+	hash, err := utils.HashPassword("Secret-pass-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.AddAdmin(models.AdminRecord{
+		AdminAccount: models.AdminAccount{AdminTag: "admin-2", Email: "kim@example.com", Role: "admin", Status: "active"},
+		Password:     hash,
+		MFAMethod:    "email",
+		Timezone:     "UTC",
+	})
+	login := models.Adminlogin{Email: "kim@example.com", Password: "Secret-pass-1", ClientInfo: models.ClientInfo{IP: "10.0.0.1"}}
+
+	if _, err := s.Login(ctx, models.Adminlogin{Email: login.Email, Password: "wrong"}); errorText(err) != responses.INVALID_PASSWORD {
+		t.Fatalf("wrong password: got error %q", errorText(err))
+	}
+	if _, err := s.Login(ctx, login); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Login(ctx, login); errorText(err) != responses.OTP_COOLDOWN {
+		t.Errorf("second login: got error %q, want %q", errorText(err), responses.OTP_COOLDOWN)
+	}
+
+	record, err := s.Repos.Admins.Get(ctx, "admin-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.VerifyOTP(ctx, models.OTPVerify{Usertag: "admin-2", OTP: record.OTP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, _ := res.(map[string]interface{})["refresh_token"].(string)
+	if _, err := (SessionServer{Repos: s.Repos}).Refresh(ctx, models.RefreshTokenReq{RefreshToken: refresh}); err != nil {
+		t.Errorf("refreshing the new session: %v", err)
+	}
+	if _, err := s.VerifyOTP(ctx, models.OTPVerify{Usertag: "admin-2", OTP: record.OTP}); apperrors.KindOf(err) != apperrors.KindUnauthorized {
+		t.Errorf("reusing the OTP: got error %v, want unauthorized", err)
+	}
+}
+
+func TestAdminServerVerifyOTPRechecksAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(context.Context, repository.Admins) error
+		wantErr string
+	}{
+		{
+			name: "suspended after the OTP was sent",
+			change: func(ctx context.Context, admins repository.Admins) error {
+				return admins.SetStatus(ctx, "admin-1", "suspended")
+			},
+			wantErr: responses.ACCOUNT_SUSPENDED,
+		},
+		{
+			name: "password reset required after the OTP was sent",
+			change: func(ctx context.Context, admins repository.Admins) error {
+				_, err := admins.RequirePasswordReset(ctx, "admin-1")
+				return err
+			},
+			wantErr: responses.PASSWORD_RESET_REQUIRED,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newTestAdminServer()
+			ctx := context.Background()
+			if err := s.Repos.Admins.SetOTP(ctx, "admin-1", "123456", time.Minute, true); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(ctx, s.Repos.Admins); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.VerifyOTP(ctx, models.OTPVerify{Usertag: "admin-1", OTP: "123456"}); errorText(err) != tt.wantErr {
+				t.Errorf("got error %q, want %q", errorText(err), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminServerWrongOTPBurnsIt(t *testing.T) {
+	s, _, _ := newTestAdminServer()
+	ctx := context.Background()
+	if err := s.Repos.Admins.SetOTP(ctx, "admin-1", "123456", time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	for i := 0; i < maxOTPAttempts; i++ {
+		_, err = s.VerifyOTP(ctx, models.OTPVerify{Usertag: "admin-1", OTP: "000000"})
+	}
+	if errorText(err) != responses.OTP_ATTEMPTS_EXCEEDED {
+		t.Fatalf("got error %q, want %q", errorText(err), responses.OTP_ATTEMPTS_EXCEEDED)
+	}
+	if _, err := s.VerifyOTP(ctx, models.OTPVerify{Usertag: "admin-1", OTP: "123456"}); err == nil {
+		t.Error("the burnt OTP was accepted")
+	}
+}
+
+func TestAdminServerResetPasswordSpendsToken(t *testing.T) {
+	s, store, _ := newTestAdminServer()
+	ctx := context.Background()
+	oldHash, err := utils.HashPassword("Old-password-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.AddAdmin(models.AdminRecord{
+		AdminAccount: models.AdminAccount{AdminTag: "admin-2", Email: "two@example.com", Role: "admin", Status: "active"},
+		Password:     oldHash,
+	})
+	if err := s.Repos.Admins.SetResetToken(ctx, "admin-2", utils.HashToken("reset-token"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	req := models.ResetPassword{Email: "two@example.com", ResetToken: "reset-token", NewPassword: "New-password-1"}
+	if _, err := s.ResetPassword(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	req.NewPassword = "Other-password-2"
+	if _, err := s.ResetPassword(ctx, req); errorText(err) != responses.RESET_TOKEN_INVALID {
+		t.Errorf("got error %q, want %q", errorText(err), responses.RESET_TOKEN_INVALID)
+	}
+
+	hashes, err := s.Repos.Admins.PasswordHashes(ctx, "admin-2", passwordHistoryDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 2 || !utils.VerifyPassword("New-password-1", hashes[0]) || hashes[1] != oldHash {
+		t.Errorf("got %d password hashes, want the new password then the old one", len(hashes))
+	}
+}
+
+func TestAdminServerResetLockoutResetsOTPAttempts(t *testing.T) {
+	s, store, _ := newTestAdminServer()
+	ctx := context.Background()
+	store.AddPatient(models.Patient{UserTag: "p-2", Firstname: "Bo", Lastname: "Ade", Email: "bo@example.com"})
+	tests := []struct {
+		scope    string
+		key      string
+		accounts otpAccounts
+	}{
+		{lockoutScopeAdmin, "admin-1", s.Repos.Admins},
+		{lockoutScopePatient, "bo@example.com", s.Repos.Patients},
+		{lockoutScopeDoctor, "d-1", s.Repos.Doctors},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				if _, err := tt.accounts.CountWrongOTP(ctx, tt.key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := s.Repos.Lockouts.RecordFailure(ctx, tt.scope, tt.key); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.ResetLockout(ctx, testActor, models.LockoutReq{Scope: tt.scope, Key: tt.key}); err != nil {
+				t.Fatal(err)
+			}
+			if attempts, _ := tt.accounts.CountWrongOTP(ctx, tt.key); attempts != 1 {
+				t.Errorf("after the reset the next wrong guess is attempt %d, want 1", attempts)
+			}
+		})
+	}
+}
+
+// brokenLockouts cannot read any lock.
+type brokenLockouts struct{ repository.Lockouts }
+
+func (brokenLockouts) Locked(ctx context.Context, scope, key string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestCheckLockoutFailsClosed(t *testing.T) {
+	err := checkLockout(context.Background(), brokenLockouts{}, lockoutKey{lockoutScopePatient, "bo@example.com"})
+	if kind := apperrors.KindOf(err); kind != apperrors.KindInternal {
+		t.Errorf("got %v (kind %v), want an internal error", err, kind)
+	}
+}
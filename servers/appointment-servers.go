@@ -0,0 +1,90 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+)
+
+// appointmentTransitions lists, for each appointment status, the statuses it
+// may move to next. Statuses without an entry are final.
+var appointmentTransitions = map[string][]string{
+	"pending":   {"confirmed", "cancelled"},
+	"confirmed": {"completed", "cancelled", "no_show"},
+}
+
+func validAppointmentStatus(status string) bool {
+	for _, s := range models.AppointmentStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range appointmentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// statusChange describes a requested move of one appointment to a new status.
+// When ownerColumn is set, only an appointment whose ownerColumn equals
+// changedBy can be changed, so doctors and patients are kept to their own.
+type statusChange struct {
+	appointmentID string
+	to            string
+	reason        string
+	changedByType string
+	changedBy     string
+	ownerColumn   string
+}
+
+// changeAppointmentStatus applies change if appointmentTransitions allows it
+// from the appointment's current status, and records it in the appointment's
+// status history. It returns the status the appointment moved from.
+func changeAppointmentStatus(ctx context.Context, appointments repository.Appointments, change statusChange) (string, error) {
+	if !validAppointmentStatus(change.to) {
+		return "", apperrors.Invalid(responses.INVALID_STATUS)
+	}
+	change.reason = strings.TrimSpace(change.reason)
+	if change.to == "cancelled" && change.reason == "" {
+		return "", apperrors.Invalid(responses.CANCELLATION_REASON_REQUIRED)
+	}
+
+	var rejected error
+	from, err := appointments.ChangeStatus(ctx, change.appointmentID, models.AppointmentStatusChange{
+		ToStatus:      change.to,
+		Reason:        change.reason,
+		ChangedByType: change.changedByType,
+		ChangedBy:     change.changedBy,
+	}, func(current models.Appointment) error {
+		if (change.ownerColumn == "patient_tag" && current.UserTag != change.changedBy) ||
+			(change.ownerColumn == "doctor_tag" && current.DoctorTag != change.changedBy) {
+			rejected = apperrors.NotFound(responses.APPOINTMENT_NOT_FOUND)
+		} else if !canTransition(current.Status, change.to) {
+			rejected = apperrors.Conflict(fmt.Sprintf("%s from %s to %s", responses.INVALID_STATUS_TRANSITION, current.Status, change.to))
+		}
+		return rejected
+	})
+	if err != nil {
+		if rejected != nil {
+			return "", rejected
+		}
+		log.Println("Failed to change appointment status:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", apperrors.NotFound(responses.APPOINTMENT_NOT_FOUND)
+		}
+		return "", apperrors.Internal(err)
+	}
+	return from, nil
+}
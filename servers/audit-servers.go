@@ -0,0 +1,77 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"telemed/models"
+	"telemed/repository"
+)
+
+// auditRedacted columns never make it into an audit snapshot.
+var auditRedacted = []string{"password", "otp", "otp_expiry", "invite_token_hash", "reset_token_hash", "reset_token_expiry",
+	"resubmit_token_hash", "resubmit_token_expiry", "totp_secret", "totp_pending_secret", "totp_last_counter"}
+
+// snapshot returns the current row of table as a map, for the before/after
+// halves of an audit entry. table and keyColumn always come from our own
+// code, never from a request. A missing row or failed lookup yields nil.
+func (s AdminServer) snapshot(ctx context.Context, table, keyColumn, id string) map[string]any {
+	row, err := s.Repos.Audit.Snapshot(ctx, table, keyColumn, id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failed to snapshot %s %s for audit: %v", table, id, err)
+		}
+		return nil
+	}
+	for _, column := range auditRedacted {
+		delete(row, column)
+	}
+	return row
+}
+
+// diffSnapshots trims two snapshots of the same row down to the fields that
+// changed. Creates and deletes, where one side is nil, are kept whole.
+func diffSnapshots(before, after map[string]any) (map[string]any, map[string]any) {
+	if before == nil || after == nil {
+		return before, after
+	}
+	b, a := map[string]any{}, map[string]any{}
+	for k, v := range after {
+		if !reflect.DeepEqual(before[k], v) {
+			b[k] = before[k]
+			a[k] = v
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok {
+			b[k] = v
+		}
+	}
+	return b, a
+}
+
+// recordAudit appends an entry to the audit trail. A failure to record is
+// logged rather than undoing the change itself.
+func (s AdminServer) recordAudit(ctx context.Context, actor models.Actor, action, entityType, entityID string, before, after map[string]any) {
+	before, after = diffSnapshots(before, after)
+	err := s.Repos.Audit.Append(ctx, models.AuditLog{
+		ActorTag:   actor.AdminTag,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+	})
+	if err != nil {
+		log.Printf("Failed to record audit log for %s %s %s: %v", action, entityType, entityID, err)
+	}
+}
+
+func (s AdminServer) GetAuditLogs(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Audit.List(ctx, params)
+	return page, listError("audit logs", err)
+}
@@ -0,0 +1,246 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+	"time"
+)
+
+const (
+	dateLayout       = "2006-01-02"
+	clockLayout      = "15:04"
+	maxSlotRangeDays = 31
+)
+
+// window is a span of wall-clock time within a day, as offsets from midnight.
+type window struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// schedule is a validated models.DoctorSchedule that slots can be generated
+// from.
+type schedule struct {
+	loc       *time.Location
+	slot      time.Duration
+	buffer    time.Duration
+	weekly    map[time.Weekday][]window
+	overrides map[string][]window
+	blackouts map[string]bool
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func newWindow(start, end string) (window, error) {
+	var w window
+	var err error
+	if w.start, err = parseClock(start); err != nil {
+		return w, err
+	}
+	if w.end, err = parseClock(end); err != nil {
+		return w, err
+	}
+	if w.end <= w.start {
+		return w, errors.New("window ends before it starts")
+	}
+	return w, nil
+}
+
+func compileSchedule(s models.DoctorSchedule) (schedule, error) {
+	compiled := schedule{
+		slot:      time.Duration(s.SlotMinutes) * time.Minute,
+		buffer:    time.Duration(s.BufferMinutes) * time.Minute,
+		weekly:    map[time.Weekday][]window{},
+		overrides: map[string][]window{},
+		blackouts: map[string]bool{},
+	}
+	if s.Timezone == "" {
+		return compiled, apperrors.Invalid(responses.INVALID_TIMEZONE)
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return compiled, apperrors.Invalid(responses.INVALID_TIMEZONE)
+	}
+	compiled.loc = loc
+
+	if s.SlotMinutes < 5 || s.SlotMinutes > 480 || s.BufferMinutes < 0 || s.BufferMinutes > 240 {
+		return compiled, apperrors.Invalid(responses.INVALID_SCHEDULE)
+	}
+	for _, h := range s.Weekly {
+		w, err := newWindow(h.Start, h.End)
+		if err != nil || h.Weekday < 0 || h.Weekday > 6 {
+			return compiled, apperrors.Invalid(responses.INVALID_SCHEDULE)
+		}
+		compiled.weekly[time.Weekday(h.Weekday)] = append(compiled.weekly[time.Weekday(h.Weekday)], w)
+	}
+	for _, o := range s.Overrides {
+		w, err := newWindow(o.Start, o.End)
+		if err != nil {
+			return compiled, apperrors.Invalid(responses.INVALID_SCHEDULE)
+		}
+		if _, err := time.Parse(dateLayout, o.Date); err != nil {
+			return compiled, apperrors.Invalid(responses.INVALID_SCHEDULE)
+		}
+		compiled.overrides[o.Date] = append(compiled.overrides[o.Date], w)
+	}
+	for _, b := range s.Blackouts {
+		if _, err := time.Parse(dateLayout, b.Date); err != nil {
+			return compiled, apperrors.Invalid(responses.INVALID_SCHEDULE)
+		}
+		compiled.blackouts[b.Date] = true
+	}
+	return compiled, nil
+}
+
+// slots returns the start of every slot on the calendar dates from to to,
+// inclusive, in order. Slots are laid end to end from the start of each
+// window with the buffer between them, and must finish within the window.
+func (s schedule) slots(from, to time.Time) []time.Time {
+	seen := map[time.Time]bool{}
+	var starts []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		if s.blackouts[date] {
+			continue
+		}
+		windows, ok := s.overrides[date]
+		if !ok {
+			windows = s.weekly[day.Weekday()]
+		}
+		for _, w := range windows {
+			for offset := w.start; offset+s.slot <= w.end; offset += s.slot + s.buffer {
+				start := time.Date(day.Year(), day.Month(), day.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, s.loc)
+				if !seen[start] {
+					seen[start] = true
+					starts = append(starts, start)
+				}
+			}
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	return starts
+}
+
+// overlapsBooking reports whether a slot starting at start overlaps any of
+// booked.
+func overlapsBooking(start time.Time, slot time.Duration, booked []models.Booking) bool {
+	for _, b := range booked {
+		if b.Start.Before(start.Add(slot)) && b.Start.Add(b.Length).After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSlotRange reads the from and to dates of a free-slot query. from
+// defaults to today in loc and to to a week after from.
+func parseSlotRange(data models.SlotQuery, loc *time.Location) (time.Time, time.Time, error) {
+	invalid := apperrors.Invalid(responses.INVALID_DATE_RANGE)
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if data.From != "" {
+		var err error
+		if from, err = time.Parse(dateLayout, data.From); err != nil {
+			return from, from, invalid
+		}
+	}
+	to := from.AddDate(0, 0, 6)
+	if data.To != "" {
+		var err error
+		if to, err = time.Parse(dateLayout, data.To); err != nil {
+			return from, to, invalid
+		}
+	}
+	if to.Before(from) || to.Sub(from) > maxSlotRangeDays*24*time.Hour {
+		return from, to, invalid
+	}
+	return from, to, nil
+}
+
+// GetDoctorSlots lists the bookable slots an approved doctor has left over a
+// range of dates in their timezone. The slots themselves are rendered in loc.
+func (s PatientServer) GetDoctorSlots(ctx context.Context, data models.SlotQuery, loc *time.Location) (any, error) {
+	stored, err := s.Repos.Calendar.Schedule(ctx, data.DoctorTag)
+	if err != nil {
+		log.Println("Failed to load doctor schedule:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.DOCTOR_NOT_FOUND)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	sched, err := compileSchedule(stored)
+	if err != nil {
+		log.Println("Stored doctor schedule is invalid:", err)
+		return nil, apperrors.Internal(err)
+	}
+	from, to, err := parseSlotRange(data, sched.loc)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := sched.slots(from, to)
+	free := []models.Slot{}
+	if len(starts) > 0 {
+		booked, err := s.Repos.Calendar.Booked(ctx, data.DoctorTag, starts[0], starts[len(starts)-1].Add(sched.slot), "")
+		if err != nil {
+			log.Println("Failed to fetch booked appointments:", err)
+			return nil, apperrors.Internal(err)
+		}
+		now := time.Now()
+		for _, start := range starts {
+			if !start.After(now) || overlapsBooking(start, sched.slot, booked) {
+				continue
+			}
+			free = append(free, models.Slot{Start: start.In(loc), End: start.Add(sched.slot).In(loc)})
+		}
+	}
+
+	return models.FreeSlots{
+		DoctorTag:   data.DoctorTag,
+		Timezone:    stored.Timezone,
+		SlotMinutes: stored.SlotMinutes,
+		Slots:       free,
+	}, nil
+}
+
+func (s DoctorServer) GetAvailability(ctx context.Context, doctorTag string) (any, error) {
+	stored, err := s.Repos.Doctors.Schedule(ctx, doctorTag)
+	if err != nil {
+		log.Println("Failed to load doctor schedule:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.DOCTOR_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	return stored, nil
+}
+
+// UpdateAvailability replaces the doctor's whole schedule. Appointments that
+// are already booked are kept even if they no longer fall on a slot.
+func (s DoctorServer) UpdateAvailability(ctx context.Context, data models.DoctorSchedule) (any, error) {
+	if _, err := compileSchedule(data); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repos.Doctors.SetSchedule(ctx, data); err != nil {
+		log.Println("Failed to save doctor schedule:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.DOCTOR_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	return s.GetAvailability(ctx, data.DoctorTag)
+}
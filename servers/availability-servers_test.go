@@ -0,0 +1,145 @@
+package servers
+
+import (
+	"reflect"
+	"telemed/models"
+	"telemed/responses"
+	"testing"
+	"time"
+)
+
+func TestScheduleSlots(t *testing.T) {
+	monday := func(start, end string) []models.WeeklyHours {
+		return []models.WeeklyHours{{Weekday: 1, Start: start, End: end}}
+	}
+	tests := []struct {
+		name     string
+		schedule models.DoctorSchedule
+		from, to string
+		want     []string
+	}{
+		{
+			name:     "weekly hours",
+			schedule: models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 30, Weekly: monday("09:00", "11:00")},
+			from:     "2024-06-03", to: "2024-06-04",
+			want: []string{"2024-06-03T08:00:00Z", "2024-06-03T08:30:00Z", "2024-06-03T09:00:00Z", "2024-06-03T09:30:00Z"},
+		},
+		{
+			name: "override replaces the weekly hours",
+			schedule: models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 30, Weekly: monday("09:00", "11:00"),
+				Overrides: []models.DateOverride{{Date: "2024-06-03", Start: "14:00", End: "15:00"}}},
+			from: "2024-06-03", to: "2024-06-03",
+			want: []string{"2024-06-03T13:00:00Z", "2024-06-03T13:30:00Z"},
+		},
+		{
+			name: "override on a day with no weekly hours",
+			schedule: models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 60, Weekly: monday("09:00", "10:00"),
+				Overrides: []models.DateOverride{{Date: "2024-06-08", Start: "10:00", End: "11:00"}}},
+			from: "2024-06-03", to: "2024-06-09",
+			want: []string{"2024-06-03T08:00:00Z", "2024-06-08T09:00:00Z"},
+		},
+		{
+			name: "blackout beats weekly hours and overrides",
+			schedule: models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 60, Weekly: monday("09:00", "10:00"),
+				Overrides: []models.DateOverride{{Date: "2024-06-03", Start: "14:00", End: "15:00"}},
+				Blackouts: []models.Blackout{{Date: "2024-06-03", Reason: "Conference"}}},
+			from: "2024-06-03", to: "2024-06-10",
+			want: []string{"2024-06-10T08:00:00Z"},
+		},
+		{
+			name:     "buffer between slots",
+			schedule: models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 30, BufferMinutes: 15, Weekly: monday("09:00", "11:00")},
+			from:     "2024-06-03", to: "2024-06-03",
+			want: []string{"2024-06-03T08:00:00Z", "2024-06-03T08:45:00Z", "2024-06-03T09:30:00Z"},
+		},
+		{
+			name:     "slot that would overrun the window is dropped",
+			schedule: models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 30, Weekly: monday("09:00", "10:10")},
+			from:     "2024-06-03", to: "2024-06-03",
+			want: []string{"2024-06-03T08:00:00Z", "2024-06-03T08:30:00Z"},
+		},
+		{
+			name: "overlapping windows give each start once",
+			schedule: models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 30,
+				Weekly: append(monday("09:00", "10:00"), monday("09:30", "10:30")...)},
+			from: "2024-06-03", to: "2024-06-03",
+			want: []string{"2024-06-03T08:00:00Z", "2024-06-03T08:30:00Z", "2024-06-03T09:00:00Z"},
+		},
+		{
+			name:     "morning east of UTC falls on the previous UTC day",
+			schedule: models.DoctorSchedule{Timezone: "Pacific/Auckland", SlotMinutes: 60, Weekly: monday("08:00", "09:00")},
+			from:     "2024-06-03", to: "2024-06-03",
+			want: []string{"2024-06-02T20:00:00Z"},
+		},
+		{
+			name:     "last slot must end by midnight",
+			schedule: models.DoctorSchedule{Timezone: "America/Los_Angeles", SlotMinutes: 30, Weekly: monday("23:00", "23:59")},
+			from:     "2024-06-03", to: "2024-06-03",
+			want: []string{"2024-06-04T06:00:00Z"},
+		},
+		{
+			name: "clocks going forward skip the missing hour",
+			schedule: models.DoctorSchedule{Timezone: "America/New_York", SlotMinutes: 60,
+				Weekly: []models.WeeklyHours{{Weekday: 0, Start: "01:00", End: "04:00"}}},
+			from: "2024-03-10", to: "2024-03-10",
+			want: []string{"2024-03-10T06:00:00Z", "2024-03-10T07:00:00Z"},
+		},
+		{
+			name: "clocks going back keep wall-clock starts",
+			schedule: models.DoctorSchedule{Timezone: "America/New_York", SlotMinutes: 60,
+				Weekly: []models.WeeklyHours{{Weekday: 0, Start: "00:00", End: "03:00"}}},
+			from: "2024-11-03", to: "2024-11-03",
+			want: []string{"2024-11-03T04:00:00Z", "2024-11-03T05:00:00Z", "2024-11-03T07:00:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := compileSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("compileSchedule: %v", err)
+			}
+			from, to, err := parseSlotRange(models.SlotQuery{From: tt.from, To: tt.to}, sched.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, start := range sched.slots(from, to) {
+				got = append(got, start.UTC().Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got slots %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileScheduleRejects(t *testing.T) {
+	valid := models.DoctorSchedule{Timezone: "Africa/Lagos", SlotMinutes: 30, Weekly: []models.WeeklyHours{{Weekday: 1, Start: "09:00", End: "17:00"}}}
+	tests := []struct {
+		name    string
+		change  func(*models.DoctorSchedule)
+		wantErr string
+	}{
+		{"no timezone", func(s *models.DoctorSchedule) { s.Timezone = "" }, responses.INVALID_TIMEZONE},
+		{"unknown timezone", func(s *models.DoctorSchedule) { s.Timezone = "Mars/Olympus" }, responses.INVALID_TIMEZONE},
+		{"slot too short", func(s *models.DoctorSchedule) { s.SlotMinutes = 4 }, responses.INVALID_SCHEDULE},
+		{"buffer too long", func(s *models.DoctorSchedule) { s.BufferMinutes = 241 }, responses.INVALID_SCHEDULE},
+		{"weekday out of range", func(s *models.DoctorSchedule) { s.Weekly[0].Weekday = 7 }, responses.INVALID_SCHEDULE},
+		{"window across midnight", func(s *models.DoctorSchedule) { s.Weekly[0].Start, s.Weekly[0].End = "22:00", "02:00" }, responses.INVALID_SCHEDULE},
+		{"empty window", func(s *models.DoctorSchedule) { s.Weekly[0].End = s.Weekly[0].Start }, responses.INVALID_SCHEDULE},
+		{"bad override date", func(s *models.DoctorSchedule) {
+			s.Overrides = []models.DateOverride{{Date: "2024-02-30", Start: "09:00", End: "10:00"}}
+		}, responses.INVALID_SCHEDULE},
+		{"bad blackout date", func(s *models.DoctorSchedule) { s.Blackouts = []models.Blackout{{Date: "03/06/2024"}} }, responses.INVALID_SCHEDULE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			s.Weekly = append([]models.WeeklyHours(nil), valid.Weekly...)
+			tt.change(&s)
+			if _, err := compileSchedule(s); errorText(err) != tt.wantErr {
+				t.Errorf("got error %q, want %q", errorText(err), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/notifications"
+	"telemed/repository"
+	"telemed/responses"
+	"telemed/utils"
+	"time"
+)
+
+// DoctorServer serves the doctor portal. Repos is where it changes the
+// appointments and sessions it shares with the other portals.
+type DoctorServer struct {
+	Repos repository.Repositories
+}
+
+// doctorOTPTTL is how long a doctor's login code stays valid.
+const doctorOTPTTL = 5 * time.Minute
+
+func (s DoctorServer) Login(ctx context.Context, data models.DoctorLogin) (any, error) {
+	account, err := s.Repos.Doctors.AccountByEmail(ctx, data.Email)
+	if err != nil {
+		log.Println("Failed to fetch doctor for login:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.DOCTOR_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	if !utils.VerifyPassword(data.Password, account.Password) {
+		log.Println("Invalid password for doctor login")
+		return nil, apperrors.Unauthorized(responses.INVALID_PASSWORD)
+	}
+	if account.Status != "approved" {
+		return nil, apperrors.Forbidden(responses.DOCTOR_NOT_APPROVED)
+	}
+	otp, err := utils.GenerateOTP()
+	if err != nil {
+		log.Println("Failed to generate OTP:", err)
+		return nil, apperrors.Internal(err)
+	}
+	if err := s.Repos.Doctors.SetOTP(ctx, account.DoctorTag, otp, doctorOTPTTL); err != nil {
+		log.Println("failed to save OTP", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	err = notifyUser(ctx, s.Repos, utils.RoleDoctor, account.DoctorTag, notifications.EventOTP,
+		notifications.OTPData{OTP: otp, ExpiresMinutes: int(doctorOTPTTL.Minutes())})
+	if err != nil {
+		log.Println("Failed to send OTP:", err)
+		return nil, apperrors.Internal(err)
+	}
+	return models.DoctorLoginResponse{DoctorTag: account.DoctorTag}, nil
+}
+
+func (s DoctorServer) VerifyOTP(ctx context.Context, data models.DoctorOTPVerify) (any, error) {
+	accountKey, ipKey := lockoutKey{lockoutScopeDoctor, data.DoctorTag}, lockoutKey{lockoutScopeIP, data.IP}
+	if err := checkLockout(ctx, s.Repos.Lockouts, accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
+	account, err := s.Repos.Doctors.Account(ctx, data.DoctorTag)
+	if err != nil || account.OTP == "" {
+		log.Println("No pending OTP for doctor:", err)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.Internal(err)
+		}
+		recordFailure(ctx, s.Repos.Lockouts, ipKey)
+		return nil, apperrors.Unauthorized("invalid doctortag or OTP")
+	}
+
+	if data.OTP != account.OTP {
+		log.Println("Invalid OTP for doctor login")
+		recordFailure(ctx, s.Repos.Lockouts, accountKey, ipKey)
+		if recordWrongOTP(ctx, s.Repos.Doctors, data.DoctorTag) {
+			return nil, apperrors.Unauthorized(responses.OTP_ATTEMPTS_EXCEEDED)
+		}
+		return nil, apperrors.Unauthorized(responses.INVALID_OTP)
+	}
+
+	if account.OTPExpiry == nil || time.Now().After(*account.OTPExpiry) {
+		log.Println("OTP has expired")
+		return nil, apperrors.Unauthorized(responses.OTP_EXPIRED)
+	}
+	if err := s.Repos.Doctors.ClearOTP(ctx, data.DoctorTag); err != nil {
+		log.Println("Failed to clear OTP:", err)
+	}
+	clearFailures(ctx, s.Repos.Lockouts, accountKey)
+
+	// The application may have been reviewed again since the OTP was sent.
+	if account.Status != "approved" {
+		return nil, apperrors.Forbidden(responses.DOCTOR_NOT_APPROVED)
+	}
+
+	tokens, err := issueSession(ctx, s.Repos.Sessions, data.DoctorTag, utils.RoleDoctor, data.ClientInfo)
+	if err != nil {
+		log.Println("Failed to start session:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return map[string]interface{}{
+		"message":       "Login successful",
+		"token":         tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	}, nil
+}
+
+// Apply records a doctor's onboarding application. An applicant whose
+// application was sent back for more information re-applies with the same
+// email and the code they were emailed, which updates the existing row and
+// returns it to the review queue.
+func (s DoctorServer) Apply(ctx context.Context, data models.DoctorApplicationReq) (any, error) {
+	existing, err := s.Repos.Doctors.AccountByEmail(ctx, data.Email)
+	if err == nil {
+		switch existing.Status {
+		case "info_requested":
+			if data.ResubmitToken == "" {
+				return nil, apperrors.Unauthorized(responses.RESUBMIT_TOKEN_INVALID)
+			}
+		case "pending":
+			return nil, apperrors.Forbidden(responses.APPLICATION_PENDING)
+		default:
+			return nil, apperrors.Conflict(responses.EMAIL_IN_USE)
+		}
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		log.Println("Failed to check existing doctor application:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	hashedPwd, err := utils.HashPassword(data.Password)
+	if err != nil {
+		log.Println("Failed to hash password:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	if data.Documents == nil {
+		data.Documents = []string{}
+	}
+
+	doctorTag := existing.DoctorTag
+	if doctorTag == "" {
+		doctorTag = utils.GenerateUUID(data.FullName)
+		err = s.Repos.Doctors.Apply(ctx, doctorTag, data, hashedPwd)
+	} else {
+		err = s.Repos.Doctors.Resubmit(ctx, doctorTag, utils.HashToken(data.ResubmitToken), data, hashedPwd)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, apperrors.Unauthorized(responses.RESUBMIT_TOKEN_INVALID)
+	}
+	if err != nil {
+		log.Println("Failed to save doctor application:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return map[string]string{"doctortag": doctorTag, "status": "pending"}, nil
+}
+
+func (s DoctorServer) GetAppointments(ctx context.Context, doctorTag string, loc *time.Location) (any, error) {
+	appointments, err := s.Repos.Appointments.ForDoctor(ctx, doctorTag)
+	if err != nil {
+		log.Println("Failed to fetch doctor appointments:", err)
+		return nil, apperrors.Internal(err)
+	}
+	for i := range appointments {
+		appointments[i].Scheduled_at = appointments[i].Scheduled_at.In(loc)
+		appointments[i].Created_at = appointments[i].Created_at.In(loc)
+	}
+	return appointments, nil
+}
+
+func (s DoctorServer) UpdateAppointmentStatus(ctx context.Context, data models.DoctorAppointmentStatus) (any, error) {
+	_, err := changeAppointmentStatus(ctx, s.Repos.Appointments, statusChange{
+		appointmentID: data.Appointment_id,
+		to:            data.Status,
+		reason:        data.Reason,
+		changedByType: utils.RoleDoctor,
+		changedBy:     data.DoctorTag,
+		ownerColumn:   "doctor_tag",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data.Status == "confirmed" {
+		notifyAppointmentConfirmed(ctx, s.Repos, data.Appointment_id)
+	}
+
+	return map[string]string{"appointment_id": data.Appointment_id, "status": data.Status}, nil
+}
+
+func (s DoctorServer) GetProfile(ctx context.Context, doctorTag string) (any, error) {
+	doctor, err := s.Repos.Doctors.Profile(ctx, doctorTag)
+	if err != nil {
+		log.Println("Failed to fetch doctor profile:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.DOCTOR_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	return doctor, nil
+}
+
+func (s DoctorServer) UpdateProfile(ctx context.Context, data models.DoctorProfilePatch) (any, error) {
+	if err := s.Repos.Doctors.UpdateProfile(ctx, data); err != nil {
+		log.Println("Failed to update doctor profile:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.DOCTOR_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	return map[string]string{"message": "Profile updated successfully"}, nil
+}
@@ -0,0 +1,97 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+	"testing"
+)
+
+func TestDoctorServerResubmitNeedsEmailedCode(t *testing.T) {
+	admin, _, notifier := newTestAdminServer()
+	s := DoctorServer{Repos: admin.Repos}
+	ctx := context.Background()
+
+	_, err := admin.ReviewDoctorApplication(ctx, testActor, models.ReviewDoctorApplication{DoctorTag: "d-2", Status: "info_requested", Reason: "Upload your licence"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.notices) != 1 {
+		t.Fatalf("sent %d notices, want 1", len(notifier.notices))
+	}
+	_, after, found := strings.Cut(notifier.notices[0].body, "this code: ")
+	code, _, _ := strings.Cut(after, "\n")
+	if !found || code == "" {
+		t.Fatalf("notice has no resubmission code: %q", notifier.notices[0].body)
+	}
+
+	application := models.DoctorApplicationReq{FullName: "Mallory", Email: "two@example.com", Password: "Secret123!", LicenseNumber: "L-1"}
+	for _, token := range []string{"", "wrong"} {
+		application.ResubmitToken = token
+		if _, err := s.Apply(ctx, application); errorText(err) != responses.RESUBMIT_TOKEN_INVALID {
+			t.Errorf("Apply with code %q got error %q, want %q", token, errorText(err), responses.RESUBMIT_TOKEN_INVALID)
+		}
+	}
+	if got, _ := s.Repos.Doctors.Application(ctx, "d-2"); got.Status != "info_requested" || got.FullName != "Dr Two" {
+		t.Fatalf("application was changed without the code: %+v", got)
+	}
+
+	application.FullName, application.ResubmitToken = "Dr Two", code
+	if _, err := s.Apply(ctx, application); err != nil {
+		t.Fatalf("Apply with the emailed code: %v", err)
+	}
+	if got, _ := s.Repos.Doctors.Application(ctx, "d-2"); got.Status != "pending" || got.LicenseNumber != "L-1" {
+		t.Errorf("resubmitted application is %+v, want it pending with the new licence", got)
+	}
+}
+
+type brokenDoctors struct{ repository.Doctors }
+
+func (brokenDoctors) AccountByEmail(ctx context.Context, email string) (models.DoctorRecord, error) {
+	return models.DoctorRecord{}, errors.New("connection refused")
+}
+
+func TestDoctorServerLoginLookupErrors(t *testing.T) {
+	s := DoctorServer{Repos: repository.NewMemory().Repositories()}
+	ctx := context.Background()
+	login := models.DoctorLogin{Email: "bo@example.com", Password: "Secret123!"}
+	if _, err := s.Login(ctx, login); errorText(err) != responses.DOCTOR_NON_EXISTENT {
+		t.Errorf("unknown email got error %q, want %q", errorText(err), responses.DOCTOR_NON_EXISTENT)
+	}
+	s.Repos.Doctors = brokenDoctors{s.Repos.Doctors}
+	if _, err := s.Login(ctx, login); apperrors.KindOf(err) != apperrors.KindInternal {
+		t.Errorf("failed lookup got %v, want an internal error", err)
+	}
+}
+
+func TestDoctorServerUpdateProfileKeepsUnsentFields(t *testing.T) {
+	store := repository.NewMemory()
+	store.AddDoctor(models.DoctorApplication{DoctorTag: "d-1", FullName: "Dr One", Email: "one@example.com", Specialization: "Cardiology",
+		City: "Abuja", YearsOfExperience: 12, Status: "approved"})
+	s := DoctorServer{Repos: store.Repositories()}
+	ctx := context.Background()
+	price, about := 15000.0, "Heart health"
+	if _, err := s.UpdateProfile(ctx, models.DoctorProfilePatch{DoctorTag: "d-1", Price: &price}); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	if _, err := s.UpdateProfile(ctx, models.DoctorProfilePatch{DoctorTag: "d-1", About: &about}); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	got, err := s.Repos.Doctors.Profile(ctx, "d-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.DoctorProfile{DoctorTag: "d-1", FullName: "Dr One", Email: "one@example.com", Specialization: "Cardiology",
+		City: "Abuja", YearsOfExperience: 12, Price: price, About: about}
+	if got != want {
+		t.Errorf("profile is %+v, want %+v", got, want)
+	}
+
+	if _, err := s.UpdateProfile(ctx, models.DoctorProfilePatch{DoctorTag: "missing", About: &about}); errorText(err) != responses.DOCTOR_NON_EXISTENT {
+		t.Errorf("unknown doctor got error %q, want %q", errorText(err), responses.DOCTOR_NON_EXISTENT)
+	}
+}
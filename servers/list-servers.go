@@ -0,0 +1,21 @@
+package servers
+
+import (
+	"errors"
+	"log"
+	"telemed/apperrors"
+	"telemed/database"
+)
+
+// listError passes bad list parameters back to the client as they are;
+// anything else is logged as a failure to fetch what.
+func listError(what string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, database.ErrBadList) {
+		return &apperrors.Error{Kind: apperrors.KindValidation, Message: err.Error(), Err: err}
+	}
+	log.Printf("Failed to fetch %s: %v", what, err)
+	return apperrors.Internal(err)
+}
@@ -0,0 +1,179 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+	"time"
+)
+
+const (
+	lockoutScopeAdmin   = "admin"
+	lockoutScopePatient = "patient"
+	lockoutScopeDoctor  = "doctor"
+	lockoutScopeIP      = "ip"
+
+	// maxOTPAttempts wrong guesses burn the OTP, forcing a new one to be sent.
+	maxOTPAttempts = 5
+	// otpResendCooldown is the minimum gap between two OTP emails to an account.
+	otpResendCooldown = time.Minute
+
+	baseLockout = time.Minute
+	maxLockout  = time.Hour
+)
+
+// lockoutThresholds is how many failures a key may rack up before it is
+// locked. IPs get more headroom since many users can share one address.
+var lockoutThresholds = map[string]int{
+	lockoutScopeAdmin:   5,
+	lockoutScopePatient: 5,
+	lockoutScopeDoctor:  5,
+	lockoutScopeIP:      20,
+}
+
+type lockoutKey struct {
+	scope string
+	key   string
+}
+
+// lockoutDuration doubles the lockout for every failure past the threshold,
+// capped at maxLockout.
+func lockoutDuration(scope string, failures int) time.Duration {
+	over := failures - lockoutThresholds[scope]
+	if over < 0 {
+		return 0
+	}
+	if over > 10 || baseLockout<<over > maxLockout {
+		return maxLockout
+	}
+	return baseLockout << over
+}
+
+// checkLockout fails with TOO_MANY_ATTEMPTS while any of keys is locked. If
+// a lock cannot be read it fails too, rather than let guesses through
+// unlimited.
+func checkLockout(ctx context.Context, lockouts repository.Lockouts, keys ...lockoutKey) error {
+	for _, k := range keys {
+		if k.key == "" {
+			continue
+		}
+		locked, err := lockouts.Locked(ctx, k.scope, k.key)
+		if err != nil {
+			log.Println("Failed to check lockout:", err)
+			return apperrors.Internal(err)
+		}
+		if locked {
+			return apperrors.RateLimited(responses.TOO_MANY_ATTEMPTS)
+		}
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt against each key and locks any key
+// that has gone past its threshold. Failures older than a day are forgotten.
+func recordFailure(ctx context.Context, lockouts repository.Lockouts, keys ...lockoutKey) {
+	for _, k := range keys {
+		if k.key == "" {
+			continue
+		}
+		failures, err := lockouts.RecordFailure(ctx, k.scope, k.key)
+		if err != nil {
+			log.Println("Failed to record failed attempt:", err)
+			continue
+		}
+		if d := lockoutDuration(k.scope, failures); d > 0 {
+			log.Printf("Locking %s %s for %s after %d failed attempts", k.scope, k.key, d, failures)
+			if err := lockouts.Lock(ctx, k.scope, k.key, d); err != nil {
+				log.Println("Failed to lock:", err)
+			}
+		}
+	}
+}
+
+// clearFailures resets the counter for key after a successful attempt.
+func clearFailures(ctx context.Context, lockouts repository.Lockouts, k lockoutKey) {
+	if _, err := lockouts.Clear(ctx, k.scope, k.key); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Println("Failed to clear failed attempts:", err)
+	}
+}
+
+// otpResendAllowed reports whether the cooldown since the last OTP email to
+// admin has passed.
+func otpResendAllowed(admin models.AdminRecord) bool {
+	return admin.OTPSentAt == nil || time.Since(*admin.OTPSentAt) >= otpResendCooldown
+}
+
+// otpAccounts is what the Admins, Patients and Doctors repositories share for
+// counting wrong OTP guesses, each keyed the way its lockouts are.
+type otpAccounts interface {
+	ClearOTP(ctx context.Context, key string) error
+	CountWrongOTP(ctx context.Context, key string) (int, error)
+	ResetOTPAttempts(ctx context.Context, key string) error
+}
+
+// recordWrongOTP counts a wrong OTP guess for the account and burns the OTP
+// once maxOTPAttempts is reached. It reports whether the OTP has been burnt.
+func recordWrongOTP(ctx context.Context, accounts otpAccounts, key string) bool {
+	attempts, err := accounts.CountWrongOTP(ctx, key)
+	if err != nil {
+		log.Println("Failed to count OTP attempt:", err)
+		return false
+	}
+	if attempts < maxOTPAttempts {
+		return false
+	}
+	if err := accounts.ClearOTP(ctx, key); err != nil {
+		log.Println("Failed to invalidate OTP:", err)
+	}
+	return true
+}
+
+func (s AdminServer) GetLockouts(ctx context.Context, params models.ListParams) (models.Page, error) {
+	page, err := s.Repos.Lockouts.List(ctx, params)
+	return page, listError("lockouts", err)
+}
+
+// ResetLockout forgets every failed attempt against a key. Resetting an
+// account also lets it guess at its current OTP again.
+func (s AdminServer) ResetLockout(ctx context.Context, actor models.Actor, data models.LockoutReq) (any, error) {
+	if _, ok := lockoutThresholds[data.Scope]; !ok {
+		return nil, apperrors.Invalid(responses.BAD_DATA)
+	}
+
+	before, err := s.Repos.Lockouts.Clear(ctx, data.Scope, data.Key)
+	if err != nil {
+		log.Println("Failed to reset lockout:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.LOCKOUT_NOT_FOUND)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	if accounts := s.otpAccounts(data.Scope); accounts != nil {
+		if err := accounts.ResetOTPAttempts(ctx, data.Key); err != nil {
+			log.Println("Failed to reset OTP attempts:", err)
+		}
+	}
+
+	s.recordAudit(ctx, actor, "reset_lockout", "lockout", data.Scope+":"+data.Key,
+		map[string]any{"failed_count": before.FailedCount, "locked_until": before.LockedUntil}, nil)
+	return map[string]string{"scope": data.Scope, "key": data.Key}, nil
+}
+
+// otpAccounts returns the accounts a lockout scope is keyed on, or nil for
+// the ip scope.
+func (s AdminServer) otpAccounts(scope string) otpAccounts {
+	switch scope {
+	case lockoutScopeAdmin:
+		return s.Repos.Admins
+	case lockoutScopePatient:
+		return s.Repos.Patients
+	case lockoutScopeDoctor:
+		return s.Repos.Doctors
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+	"telemed/utils"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+// totpRequired reports whether accounts with role must use TOTP as their
+// second factor under the current security settings.
+func (s AdminServer) totpRequired(ctx context.Context, role string) bool {
+	if role != utils.RoleGodEye {
+		return false
+	}
+	policy, err := s.Repos.MFA.Policy(ctx)
+	if err != nil {
+		log.Println("Failed to read security settings:", err)
+	}
+	return policy.RequireTOTPForGodEye
+}
+
+// verifyTOTP checks code against the admin's enrolled secret. Each code is
+// only accepted once, even within its validity window.
+func (s AdminServer) verifyTOTP(ctx context.Context, adminTag, code string) bool {
+	admin, err := s.Repos.Admins.Get(ctx, adminTag)
+	if err != nil || admin.TOTPSecret == "" {
+		return false
+	}
+	counter, ok := utils.ValidateTOTP(admin.TOTPSecret, code, time.Now())
+	if !ok {
+		return false
+	}
+	fresh, err := s.Repos.MFA.UseTOTPCounter(ctx, adminTag, counter)
+	if err != nil {
+		log.Println("Failed to record TOTP use:", err)
+		return false
+	}
+	return fresh
+}
+
+// useRecoveryCode spends one of the admin's unused recovery codes.
+func (s AdminServer) useRecoveryCode(ctx context.Context, adminTag, code string) bool {
+	code = strings.ToLower(strings.TrimSpace(code))
+	used, err := s.Repos.MFA.UseRecoveryCode(ctx, adminTag, utils.HashToken(code))
+	if err != nil {
+		log.Println("Failed to use recovery code:", err)
+		return false
+	}
+	return used
+}
+
+// issueRecoveryCodes replaces the admin's recovery codes with a fresh set and
+// returns them. Only their hashes are kept.
+func (s AdminServer) issueRecoveryCodes(ctx context.Context, adminTag string) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+	return codes, s.Repos.MFA.ReplaceRecoveryCodes(ctx, adminTag, hashes)
+}
+
+func (s AdminServer) GetMFAStatus(ctx context.Context, adminTag string) (any, error) {
+	admin, err := s.Repos.Admins.Get(ctx, adminTag)
+	if err != nil {
+		log.Println("Failed to fetch MFA status:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.ACCOUNT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	status := models.MFAStatus{Method: admin.MFAMethod, TOTPEnabled: admin.TOTPSecret != ""}
+	status.RecoveryCodesRemaining, err = s.Repos.MFA.RecoveryCodesLeft(ctx, adminTag)
+	if err != nil {
+		log.Println("Failed to count recovery codes:", err)
+		return nil, apperrors.Internal(err)
+	}
+	status.TOTPRequired = s.totpRequired(ctx, admin.Role)
+	return status, nil
+}
+
+// EnrollTOTP starts authenticator app enrollment. The secret only becomes
+// active once ConfirmTOTP has seen a valid code generated from it.
+func (s AdminServer) EnrollTOTP(ctx context.Context, adminTag string) (any, error) {
+	admin, err := s.Repos.Admins.Get(ctx, adminTag)
+	if err != nil {
+		log.Println("Failed to fetch admin for TOTP enrollment:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.ACCOUNT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	if admin.TOTPSecret != "" {
+		return nil, apperrors.Conflict(responses.TOTP_ALREADY_ENABLED)
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		log.Println("Failed to generate TOTP secret:", err)
+		return nil, apperrors.Internal(err)
+	}
+	if err := s.Repos.MFA.SetPendingTOTP(ctx, adminTag, secret); err != nil {
+		log.Println("Failed to save TOTP secret:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(admin.Email, secret),
+	}, nil
+}
+
+func (s AdminServer) ConfirmTOTP(ctx context.Context, actor models.Actor, data models.TOTPCode) (any, error) {
+	admin, err := s.Repos.Admins.Get(ctx, data.AdminTag)
+	if err != nil || admin.TOTPPendingSecret == "" {
+		log.Println("Failed to find pending TOTP secret:", err)
+		return nil, apperrors.Conflict(responses.TOTP_ENROLLMENT_NEEDED)
+	}
+	counter, ok := utils.ValidateTOTP(admin.TOTPPendingSecret, data.Code, time.Now())
+	if !ok {
+		return nil, apperrors.Unauthorized(responses.INVALID_OTP)
+	}
+
+	before := s.snapshot(ctx, "admins", "admintag", data.AdminTag)
+	if err := s.Repos.MFA.EnableTOTP(ctx, data.AdminTag, counter); err != nil {
+		log.Println("Failed to enable TOTP:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	codes, err := s.issueRecoveryCodes(ctx, data.AdminTag)
+	if err != nil {
+		log.Println("Failed to issue recovery codes:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	s.recordAudit(ctx, actor, "enable_totp", "admin", data.AdminTag, before, s.snapshot(ctx, "admins", "admintag", data.AdminTag))
+	return models.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s AdminServer) DisableTOTP(ctx context.Context, actor models.Actor, data models.TOTPCode) (any, error) {
+	if s.totpRequired(ctx, actor.Role) {
+		return nil, apperrors.Forbidden(responses.TOTP_REQUIRED)
+	}
+	if !s.verifyTOTP(ctx, data.AdminTag, data.Code) {
+		return nil, apperrors.Unauthorized(responses.INVALID_OTP)
+	}
+
+	before := s.snapshot(ctx, "admins", "admintag", data.AdminTag)
+	if err := s.Repos.MFA.DisableTOTP(ctx, data.AdminTag); err != nil {
+		log.Println("Failed to disable TOTP:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	s.recordAudit(ctx, actor, "disable_totp", "admin", data.AdminTag, before, s.snapshot(ctx, "admins", "admintag", data.AdminTag))
+	return map[string]string{"method": "email"}, nil
+}
+
+func (s AdminServer) RegenerateRecoveryCodes(ctx context.Context, actor models.Actor, data models.TOTPCode) (any, error) {
+	if !s.verifyTOTP(ctx, data.AdminTag, data.Code) {
+		return nil, apperrors.Unauthorized(responses.INVALID_OTP)
+	}
+	codes, err := s.issueRecoveryCodes(ctx, data.AdminTag)
+	if err != nil {
+		log.Println("Failed to issue recovery codes:", err)
+		return nil, apperrors.Internal(err)
+	}
+	s.recordAudit(ctx, actor, "regenerate_recovery_codes", "admin", data.AdminTag, nil, nil)
+	return models.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s AdminServer) GetMFAPolicy(ctx context.Context) (any, error) {
+	policy, err := s.Repos.MFA.Policy(ctx)
+	if err != nil {
+		log.Println("Failed to fetch MFA policy:", err)
+		return nil, apperrors.Internal(err)
+	}
+	return policy, nil
+}
+
+// UpdateMFAPolicy turns TOTP enforcement for god_eye accounts on or off. The
+// god_eye turning it on must have TOTP set up, so they are not locked out by
+// their own change.
+func (s AdminServer) UpdateMFAPolicy(ctx context.Context, actor models.Actor, data models.MFAPolicy) (any, error) {
+	if data.RequireTOTPForGodEye {
+		admin, err := s.Repos.Admins.Get(ctx, actor.AdminTag)
+		if err != nil {
+			log.Println("Failed to check TOTP enrollment:", err)
+			return nil, apperrors.Internal(err)
+		}
+		if admin.TOTPSecret == "" {
+			return nil, apperrors.Conflict(responses.TOTP_NOT_ENABLED)
+		}
+	}
+
+	before := s.snapshot(ctx, "security_settings", "id", "1")
+	if err := s.Repos.MFA.SetPolicy(ctx, data); err != nil {
+		log.Println("Failed to update MFA policy:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	s.recordAudit(ctx, actor, "update_mfa_policy", "security_settings", "1", before, s.snapshot(ctx, "security_settings", "id", "1"))
+	return data, nil
+}
@@ -0,0 +1,200 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/notifications"
+	"telemed/repository"
+	"telemed/responses"
+	"telemed/utils"
+)
+
+// NotificationServer lets users choose how they are notified. Repos is where
+// their preferences and push devices are kept.
+type NotificationServer struct {
+	Repos repository.Repositories
+}
+
+// defaultChannelEnabled applies wherever a user has not set a preference:
+// everything goes by email until they opt in to SMS or push.
+func defaultChannelEnabled(channel notifications.Channel) bool {
+	return channel == notifications.ChannelEmail
+}
+
+// channelPreferences returns whether each channel is enabled for event,
+// falling back to defaultChannelEnabled where the user has no row.
+func channelPreferences(ctx context.Context, prefsRepo repository.Notifications, account, subject string, event notifications.Event) map[notifications.Channel]bool {
+	prefs := map[notifications.Channel]bool{}
+	for _, channel := range notifications.Channels {
+		prefs[channel] = defaultChannelEnabled(channel)
+	}
+
+	stored, err := prefsRepo.Preferences(ctx, account, subject, string(event))
+	if err != nil {
+		log.Println("Failed to fetch notification preferences:", err)
+		return prefs
+	}
+	for channel, enabled := range stored {
+		prefs[notifications.Channel(channel)] = enabled
+	}
+	return prefs
+}
+
+// notifyUser queues event for subject on every channel they have enabled for
+// it and can be reached on. If that leaves nothing, it falls back to email so
+// codes and updates are never silently dropped. An error means nothing could
+// be queued.
+func notifyUser(ctx context.Context, repos repository.Repositories, account, subject string, event notifications.Event, data any) error {
+	c, err := repos.Accounts.Contact(ctx, accountType(account), subject)
+	if err != nil {
+		log.Printf("Failed to look up contact details for %s %s: %v", account, subject, err)
+		return err
+	}
+	prefs := channelPreferences(ctx, repos.Notifications, accountType(account), subject, event)
+
+	sent := 0
+	if prefs[notifications.ChannelSMS] && c.Phone != "" {
+		if err := notifications.SendVia(notifications.ChannelSMS, c.Phone, event, data); err != nil {
+			log.Println("Failed to queue SMS:", err)
+		} else {
+			sent++
+		}
+	}
+	if prefs[notifications.ChannelPush] {
+		for _, token := range c.Devices {
+			if err := notifications.SendVia(notifications.ChannelPush, token, event, data); err != nil {
+				log.Println("Failed to queue push notification:", err)
+			} else {
+				sent++
+			}
+		}
+	}
+	if (prefs[notifications.ChannelEmail] || sent == 0) && c.Email != "" {
+		if err := notifications.Send(c.Email, event, data); err != nil {
+			log.Println("Failed to queue email:", err)
+		} else {
+			sent++
+		}
+	}
+
+	if sent == 0 {
+		return errors.New("no channel available to notify " + subject)
+	}
+	return nil
+}
+
+// notifyAppointmentConfirmed tells the patient once their appointment has
+// been confirmed. Failures are logged; the confirmation itself stands.
+func notifyAppointmentConfirmed(ctx context.Context, repos repository.Repositories, appointmentID string) {
+	a, err := repos.Appointments.Get(ctx, appointmentID)
+	if err != nil {
+		log.Println("Failed to fetch appointment for confirmation notice:", err)
+		return
+	}
+	data := notifications.AppointmentData{
+		PatientName: strings.TrimSpace(a.First_name + " " + a.Last_name),
+		DoctorName:  a.Doctor_Fullname,
+		ScheduledAt: a.Scheduled_At.In(accountLocation(ctx, repos.Accounts, utils.RolePatient, a.UserTag)).Format("Mon 2 Jan 2006, 15:04 MST"),
+	}
+	if err := notifyUser(ctx, repos, utils.RolePatient, a.UserTag, notifications.EventAppointmentConfirmed, data); err != nil {
+		log.Println("Failed to send appointment confirmation:", err)
+	}
+}
+
+// notifyOrderShipped tells the customer once their order has shipped.
+func notifyOrderShipped(ctx context.Context, repos repository.Repositories, orderID string) {
+	order, err := repos.Orders.Get(ctx, orderID)
+	if err != nil {
+		log.Println("Failed to fetch order for shipping notice:", err)
+		return
+	}
+	customer, err := repos.Patients.Profile(ctx, order.UserTag)
+	if err != nil {
+		log.Println("Failed to fetch customer for shipping notice:", err)
+		return
+	}
+	data := notifications.OrderData{
+		CustomerName: customer.Firstname,
+		OrderID:      order.OrderID,
+		ItemName:     order.ItemName,
+		Quantity:     order.Quantity,
+	}
+	if err := notifyUser(ctx, repos, utils.RolePatient, order.UserTag, notifications.EventOrderShipped, data); err != nil {
+		log.Println("Failed to send order shipped notice:", err)
+	}
+}
+
+func (s NotificationServer) GetPreferences(ctx context.Context, data models.SessionReq) (any, error) {
+	account := accountType(data.Role)
+	preferences := []models.NotificationPreference{}
+	for _, event := range notifications.Events {
+		prefs := channelPreferences(ctx, s.Repos.Notifications, account, data.Subject, event)
+		for _, channel := range notifications.Channels {
+			preferences = append(preferences, models.NotificationPreference{
+				Event:   string(event),
+				Channel: string(channel),
+				Enabled: prefs[channel],
+			})
+		}
+	}
+	return preferences, nil
+}
+
+func (s NotificationServer) UpdatePreferences(ctx context.Context, data models.NotificationPreferencesReq) (any, error) {
+	for _, p := range data.Preferences {
+		if !validEvent(p.Event) || !validChannel(p.Channel) {
+			return nil, apperrors.Invalid(responses.INVALID_NOTIFICATION_PREFERENCE)
+		}
+	}
+
+	if err := s.Repos.Notifications.SetPreferences(ctx, accountType(data.Role), data.Subject, data.Preferences); err != nil {
+		log.Println("Failed to save notification preferences:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return s.GetPreferences(ctx, models.SessionReq{Subject: data.Subject, Role: data.Role})
+}
+
+// RegisterDevice records a push token for the signed-in user. A token that
+// was registered to someone else, e.g. on a shared device, moves over.
+func (s NotificationServer) RegisterDevice(ctx context.Context, data models.PushDevice) (any, error) {
+	if err := s.Repos.Notifications.RegisterDevice(ctx, accountType(data.Role), data); err != nil {
+		log.Println("Failed to register push device:", err)
+		return nil, apperrors.Internal(err)
+	}
+	return map[string]string{"token": data.Token}, nil
+}
+
+func (s NotificationServer) RemoveDevice(ctx context.Context, data models.PushDevice) (any, error) {
+	err := s.Repos.Notifications.RemoveDevice(ctx, accountType(data.Role), data)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, apperrors.NotFound(responses.DEVICE_NOT_FOUND)
+	}
+	if err != nil {
+		log.Println("Failed to remove push device:", err)
+		return nil, apperrors.Internal(err)
+	}
+	return map[string]string{"token": data.Token}, nil
+}
+
+func validEvent(event string) bool {
+	for _, e := range notifications.Events {
+		if string(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+func validChannel(channel string) bool {
+	for _, c := range notifications.Channels {
+		if string(c) == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package servers
+
+import (
+	"context"
+	"log"
+	"telemed/utils"
+)
+
+// passwordHistoryDepth is how many previous passwords an admin may not reuse.
+const passwordHistoryDepth = 5
+
+// passwordReused reports whether password matches the admin's current
+// password or any of the last passwordHistoryDepth ones.
+func (s AdminServer) passwordReused(ctx context.Context, adminTag, password string) bool {
+	hashes, err := s.Repos.Admins.PasswordHashes(ctx, adminTag, passwordHistoryDepth)
+	if err != nil {
+		log.Println("Failed to fetch password history:", err)
+		return false
+	}
+	for _, hash := range hashes {
+		if utils.VerifyPassword(password, hash) {
+			return true
+		}
+	}
+	return false
+}
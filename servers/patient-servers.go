@@ -0,0 +1,394 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/notifications"
+	"telemed/repository"
+	"telemed/responses"
+	"telemed/utils"
+	"time"
+)
+
+// PatientServer serves the patient app. Repos is where it changes the
+// appointments and sessions it shares with the other portals.
+type PatientServer struct {
+	Repos repository.Repositories
+}
+
+// patientOTPTTL is how long a verification or reset code sent to a patient
+// stays valid.
+const patientOTPTTL = 10 * time.Minute
+
+func (s PatientServer) Register(ctx context.Context, data models.PatientRegister) (any, error) {
+	_, err := s.Repos.Patients.AccountByEmail(ctx, data.Email)
+	if err == nil {
+		return nil, apperrors.Conflict(responses.EMAIL_IN_USE)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		log.Println("Failed to check existing patient email:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	hashedPwd, err := utils.HashPassword(data.Password)
+	if err != nil {
+		log.Println("Failed to hash password:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	otp, err := utils.GenerateOTP()
+	if err != nil {
+		log.Println("Failed to generate OTP:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	usertag := utils.GenerateUUID(data.Firstname)
+	err = s.Repos.Patients.Register(ctx, models.PatientProfile{
+		UserTag:   usertag,
+		Firstname: data.Firstname,
+		Lastname:  data.Lastname,
+		Email:     data.Email,
+		Phone_no:  data.Phone_no,
+		Gender:    data.Gender,
+		Dob:       data.Dob,
+	}, hashedPwd, otp, patientOTPTTL)
+	if err != nil {
+		log.Println("Failed to create patient:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	err = notifications.Send(data.Email, notifications.EventOTP, notifications.OTPData{OTP: otp, ExpiresMinutes: int(patientOTPTTL.Minutes())})
+	if err != nil {
+		log.Println("Failed to send OTP email:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return models.PatientRegisterResponse{Usertag: usertag, Email: data.Email}, nil
+}
+
+func (s PatientServer) VerifyEmail(ctx context.Context, data models.PatientVerifyEmail) (any, error) {
+	if err := s.checkOTP(ctx, data.Email, data.OTP, data.IP); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repos.Patients.VerifyEmail(ctx, data.Email); err != nil {
+		log.Println("Failed to verify patient email:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return map[string]string{"message": responses.EMAIL_VERIFIED}, nil
+}
+
+func (s PatientServer) ResendOTP(ctx context.Context, data models.ForgotPassword) (any, error) {
+	return nil, s.sendOTP(ctx, data.Email, notifications.EventOTP)
+}
+
+func (s PatientServer) Login(ctx context.Context, data models.PatientLogin) (any, error) {
+	account, err := s.Repos.Patients.AccountByEmail(ctx, data.Email)
+	if err != nil {
+		log.Println("Failed to fetch patient for login:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.PATIENT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	if !utils.VerifyPassword(data.Password, account.Password) {
+		log.Println("Invalid password for patient login")
+		return nil, apperrors.Unauthorized(responses.INVALID_PASSWORD)
+	}
+
+	if !account.EmailVerified {
+		return nil, apperrors.Forbidden(responses.EMAIL_NOT_VERIFIED)
+	}
+
+	patient := models.PatientLoginResponse{Usertag: account.UserTag}
+	patient.SessionTokens, err = issueSession(ctx, s.Repos.Sessions, patient.Usertag, utils.RolePatient, data.ClientInfo)
+	if err != nil {
+		log.Println("Failed to start session:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return patient, nil
+}
+
+func (s PatientServer) ForgotPassword(ctx context.Context, data models.ForgotPassword) (any, error) {
+	return nil, s.sendOTP(ctx, data.Email, notifications.EventPasswordReset)
+}
+
+func (s PatientServer) ResetPassword(ctx context.Context, data models.PatientResetPassword) (any, error) {
+	if err := s.checkOTP(ctx, data.Email, data.OTP, data.IP); err != nil {
+		return nil, err
+	}
+
+	hashedPwd, err := utils.HashPassword(data.NewPassword)
+	if err != nil {
+		log.Println("Failed to hash password:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	if err := s.Repos.Patients.SetPassword(ctx, data.Email, hashedPwd); err != nil {
+		log.Println("Failed to reset patient password:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return map[string]string{"message": responses.PASSWORD_RESET_SUCCESS}, nil
+}
+
+func (s PatientServer) GetProfile(ctx context.Context, usertag string) (any, error) {
+	patient, err := s.Repos.Patients.Profile(ctx, usertag)
+	if err != nil {
+		log.Println("Failed to fetch patient profile:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.PATIENT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	return patient, nil
+}
+
+func (s PatientServer) UpdateProfile(ctx context.Context, data models.PatientProfilePatch) (any, error) {
+	if data.Timezone != nil {
+		timezone, err := normalizeTimezone(*data.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		data.Timezone = &timezone
+	}
+	if err := s.Repos.Patients.UpdateProfile(ctx, data); err != nil {
+		log.Println("Failed to update patient profile:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.PATIENT_NON_EXISTENT)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	return map[string]string{"message": "Profile updated successfully"}, nil
+}
+
+func (s PatientServer) BookAppointment(ctx context.Context, data models.BookAppointmentReq) (any, error) {
+	scheduledAt, err := time.Parse(time.RFC3339, data.ScheduledAt)
+	if err != nil {
+		return nil, apperrors.Invalid(responses.INVALID_DATETIME)
+	}
+	if !scheduledAt.After(time.Now()) {
+		return nil, apperrors.Invalid("appointment must be scheduled in the future")
+	}
+
+	var rejected error
+	appointmentID, err := s.Repos.Appointments.Book(ctx, models.Appointment{
+		UserTag:      data.UserTag,
+		DoctorTag:    data.DoctorTag,
+		Scheduled_at: scheduledAt,
+		Reason:       data.Reason,
+		Fileurl:      data.Fileurl,
+		Status:       "pending",
+	}, models.AppointmentStatusChange{ChangedByType: utils.RolePatient, ChangedBy: data.UserTag}, func(cal repository.Calendar, a models.Appointment) (time.Duration, error) {
+		slot, err := reserveDoctorSlot(ctx, cal, a.DoctorTag, a.Scheduled_at, "")
+		rejected = err
+		return slot, err
+	})
+	if err != nil {
+		if rejected != nil {
+			return nil, rejected
+		}
+		log.Println("Failed to book appointment:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return map[string]string{"appointment_id": appointmentID, "status": "pending"}, nil
+}
+
+func (s PatientServer) GetAppointments(ctx context.Context, usertag string, loc *time.Location) (any, error) {
+	appointments, err := s.Repos.Appointments.ForPatient(ctx, usertag)
+	if err != nil {
+		log.Println("Failed to fetch patient appointments:", err)
+		return nil, apperrors.Internal(err)
+	}
+	for i := range appointments {
+		appointments[i].Scheduled_at = appointments[i].Scheduled_at.In(loc)
+		appointments[i].Created_at = appointments[i].Created_at.In(loc)
+	}
+	return appointments, nil
+}
+
+func (s PatientServer) CancelAppointment(ctx context.Context, data models.PatientAppointmentReq) (any, error) {
+	_, err := changeAppointmentStatus(ctx, s.Repos.Appointments, statusChange{
+		appointmentID: data.Appointment_id,
+		to:            "cancelled",
+		reason:        data.Reason,
+		changedByType: utils.RolePatient,
+		changedBy:     data.UserTag,
+		ownerColumn:   "patient_tag",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"message": "Appointment cancelled successfully"}, nil
+}
+
+func (s PatientServer) RescheduleAppointment(ctx context.Context, data models.PatientAppointmentReq) (any, error) {
+	scheduledAt, err := time.Parse(time.RFC3339, data.NewScheduledAt)
+	if err != nil {
+		return nil, apperrors.Invalid(responses.INVALID_DATETIME)
+	}
+	if !scheduledAt.After(time.Now()) {
+		return nil, apperrors.Invalid("appointment must be scheduled in the future")
+	}
+
+	// A new time has to be confirmed by the doctor again. This is the one
+	// move back to pending that appointmentTransitions does not cover.
+	var rejected error
+	err = s.Repos.Appointments.Reschedule(ctx, data.Appointment_id, scheduledAt, models.AppointmentStatusChange{
+		ToStatus:      "pending",
+		Reason:        "rescheduled",
+		ChangedByType: utils.RolePatient,
+		ChangedBy:     data.UserTag,
+	}, func(cal repository.Calendar, a models.Appointment) (time.Duration, error) {
+		if a.UserTag != data.UserTag {
+			rejected = apperrors.NotFound(responses.APPOINTMENT_NOT_FOUND)
+			return 0, rejected
+		}
+		if a.Status != "pending" && a.Status != "confirmed" {
+			rejected = apperrors.Conflict(responses.APPOINTMENT_CLOSED)
+			return 0, rejected
+		}
+		slot, err := reserveDoctorSlot(ctx, cal, a.DoctorTag, a.Scheduled_at, a.ID)
+		rejected = err
+		return slot, err
+	})
+	if err != nil {
+		if rejected != nil {
+			return nil, rejected
+		}
+		log.Println("Error updating appointment schedule:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperrors.NotFound(responses.APPOINTMENT_NOT_FOUND)
+		}
+		return nil, apperrors.Internal(err)
+	}
+
+	return map[string]string{"message": "Appointment rescheduled successfully"}, nil
+}
+
+// reserveDoctorSlot checks, through the calendar of the booking it is
+// called from, that at is one of the slots in the doctor's schedule and does
+// not overlap a live appointment other than excludeID. It returns the slot
+// length, which the appointment keeps even if the doctor's schedule changes
+// later.
+func reserveDoctorSlot(ctx context.Context, cal repository.Calendar, doctorTag string, at time.Time, excludeID string) (time.Duration, error) {
+	stored, err := cal.Schedule(ctx, doctorTag)
+	if err != nil {
+		log.Println("Failed to load doctor schedule for booking:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, apperrors.NotFound(responses.DOCTOR_NOT_FOUND)
+		}
+		return 0, apperrors.Internal(err)
+	}
+	sched, err := compileSchedule(stored)
+	if err != nil {
+		log.Println("Stored doctor schedule is invalid:", err)
+		return 0, apperrors.Internal(err)
+	}
+
+	local := at.In(sched.loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	if !slotStartsAt(sched.slots(day, day), at) {
+		return 0, apperrors.Conflict(responses.SLOT_UNAVAILABLE)
+	}
+
+	booked, err := cal.Booked(ctx, doctorTag, at, at.Add(sched.slot), excludeID)
+	if err != nil {
+		log.Println("Failed to check doctor slot:", err)
+		return 0, apperrors.Internal(err)
+	}
+	if overlapsBooking(at, sched.slot, booked) {
+		return 0, apperrors.Conflict(responses.SLOT_TAKEN)
+	}
+	return sched.slot, nil
+}
+
+func slotStartsAt(starts []time.Time, at time.Time) bool {
+	for _, start := range starts {
+		if start.Equal(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendOTP issues a fresh OTP to a registered patient, used for both email
+// verification resends and password resets. Verification codes always go to
+// the email being verified; reset codes follow the patient's channel
+// preferences.
+func (s PatientServer) sendOTP(ctx context.Context, email string, event notifications.Event) error {
+	account, err := s.Repos.Patients.AccountByEmail(ctx, email)
+	if err != nil {
+		log.Println("Failed to fetch patient for OTP:", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperrors.NotFound(responses.PATIENT_NON_EXISTENT)
+		}
+		return apperrors.Internal(err)
+	}
+
+	otp, err := utils.GenerateOTP()
+	if err != nil {
+		log.Println("Failed to generate OTP:", err)
+		return apperrors.Internal(err)
+	}
+
+	if err := s.Repos.Patients.SetOTP(ctx, email, otp, patientOTPTTL); err != nil {
+		log.Println("failed to save OTP", err)
+		return apperrors.Internal(err)
+	}
+
+	data := notifications.OTPData{OTP: otp, ExpiresMinutes: int(patientOTPTTL.Minutes())}
+	if event == notifications.EventPasswordReset {
+		err = notifyUser(ctx, s.Repos, utils.RolePatient, account.UserTag, event, data)
+	} else {
+		err = notifications.Send(email, event, data)
+	}
+	if err != nil {
+		log.Println("Failed to send OTP:", err)
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+// checkOTP checks otp against the one last sent to email. Wrong guesses
+// count towards lockouts of the account and ip, as they do for admins, and
+// burn the OTP after maxOTPAttempts.
+func (s PatientServer) checkOTP(ctx context.Context, email, otp, ip string) error {
+	accountKey, ipKey := lockoutKey{lockoutScopePatient, email}, lockoutKey{lockoutScopeIP, ip}
+	if err := checkLockout(ctx, s.Repos.Lockouts, accountKey, ipKey); err != nil {
+		return err
+	}
+
+	account, err := s.Repos.Patients.AccountByEmail(ctx, email)
+	if err != nil || account.OTP == "" {
+		log.Println("No pending OTP for patient:", err)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return apperrors.Internal(err)
+		}
+		recordFailure(ctx, s.Repos.Lockouts, ipKey)
+		return apperrors.Unauthorized("invalid email or OTP")
+	}
+
+	if otp != account.OTP {
+		log.Println("Invalid OTP for patient")
+		recordFailure(ctx, s.Repos.Lockouts, accountKey, ipKey)
+		if recordWrongOTP(ctx, s.Repos.Patients, email) {
+			return apperrors.Unauthorized(responses.OTP_ATTEMPTS_EXCEEDED)
+		}
+		return apperrors.Unauthorized(responses.INVALID_OTP)
+	}
+
+	if account.OTPExpiry == nil || time.Now().After(*account.OTPExpiry) {
+		log.Println("OTP has expired")
+		return apperrors.Unauthorized(responses.OTP_EXPIRED)
+	}
+	clearFailures(ctx, s.Repos.Lockouts, accountKey)
+	return nil
+}
@@ -0,0 +1,118 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+	"testing"
+)
+
+func newTestPatientServer() (PatientServer, *repository.Memory) {
+	store := repository.NewMemory()
+	return PatientServer{Repos: store.Repositories()}, store
+}
+
+func TestPatientServerRegisterVerifyLogin(t *testing.T) {
+	s, _ := newTestPatientServer()
+	ctx := context.Background()
+	register := models.PatientRegister{Firstname: "Ada", Lastname: "Obi", Email: "ada@example.com", Phone_no: "+2348000000000", Password: "Secret123!"}
+	if _, err := s.Register(ctx, register); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := s.Register(ctx, register); apperrors.KindOf(err) != apperrors.KindConflict {
+		t.Fatalf("second Register err = %v, want a conflict", err)
+	}
+
+	login := models.PatientLogin{Email: register.Email, Password: register.Password}
+	if _, err := s.Login(ctx, login); errorText(err) != responses.EMAIL_NOT_VERIFIED {
+		t.Fatalf("Login before verifying err = %v, want %q", err, responses.EMAIL_NOT_VERIFIED)
+	}
+
+	account, err := s.Repos.Patients.AccountByEmail(ctx, register.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyEmail(ctx, models.PatientVerifyEmail{Email: register.Email, OTP: account.OTP}); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+
+	res, err := s.Login(ctx, login)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if got := res.(models.PatientLoginResponse).Usertag; got != account.UserTag {
+		t.Errorf("Login usertag = %q, want %q", got, account.UserTag)
+	}
+	if _, err := s.Login(ctx, models.PatientLogin{Email: register.Email, Password: "wrong"}); errorText(err) != responses.INVALID_PASSWORD {
+		t.Errorf("Login with a wrong password err = %v, want %q", err, responses.INVALID_PASSWORD)
+	}
+}
+
+type brokenPatients struct{ repository.Patients }
+
+func (brokenPatients) AccountByEmail(ctx context.Context, email string) (models.PatientRecord, error) {
+	return models.PatientRecord{}, errors.New("connection refused")
+}
+
+func TestPatientServerLookupErrors(t *testing.T) {
+	s, _ := newTestPatientServer()
+	broken := s
+	broken.Repos.Patients = brokenPatients{s.Repos.Patients}
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		call func(PatientServer) error
+	}{
+		{"login", func(s PatientServer) error {
+			_, err := s.Login(ctx, models.PatientLogin{Email: "bo@example.com", Password: "Secret123!"})
+			return err
+		}},
+		{"resend OTP", func(s PatientServer) error {
+			_, err := s.ResendOTP(ctx, models.ForgotPassword{Email: "bo@example.com"})
+			return err
+		}},
+		{"forgot password", func(s PatientServer) error {
+			_, err := s.ForgotPassword(ctx, models.ForgotPassword{Email: "bo@example.com"})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(s); errorText(err) != responses.PATIENT_NON_EXISTENT {
+				t.Errorf("unknown email got error %q, want %q", errorText(err), responses.PATIENT_NON_EXISTENT)
+			}
+			if err := tt.call(broken); apperrors.KindOf(err) != apperrors.KindInternal {
+				t.Errorf("failed lookup got %v, want an internal error", err)
+			}
+		})
+	}
+}
+
+func TestPatientServerUpdateProfileKeepsUnsentFields(t *testing.T) {
+	s, store := newTestPatientServer()
+	store.AddPatient(models.Patient{UserTag: "p-1", Firstname: "Ada", Lastname: "Obi", Phone_no: "+2348000000000", Dob: "1990-01-01"})
+	ctx := context.Background()
+	lagos, address, state := "Africa/Lagos", "1 Marina", "Lagos"
+	if _, err := s.UpdateProfile(ctx, models.PatientProfilePatch{UserTag: "p-1", Timezone: &lagos, DeliveryAddress: &address}); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	if _, err := s.UpdateProfile(ctx, models.PatientProfilePatch{UserTag: "p-1", State: &state}); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	got, err := s.Repos.Patients.Profile(ctx, "p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.PatientProfile{UserTag: "p-1", Firstname: "Ada", Lastname: "Obi", Phone_no: "+2348000000000", Dob: "1990-01-01",
+		State: state, DeliveryAddress: address, Timezone: lagos}
+	if got != want {
+		t.Errorf("profile is %+v, want %+v", got, want)
+	}
+
+	if _, err := s.UpdateProfile(ctx, models.PatientProfilePatch{UserTag: "missing", State: &state}); errorText(err) != responses.PATIENT_NON_EXISTENT {
+		t.Errorf("unknown patient got error %q, want %q", errorText(err), responses.PATIENT_NON_EXISTENT)
+	}
+}
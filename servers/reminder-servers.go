@@ -0,0 +1,103 @@
+package servers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"telemed/config"
+	"telemed/models"
+	"telemed/notifications"
+	"telemed/repository"
+	"telemed/utils"
+	"time"
+)
+
+// StartReminderScheduler checks repos for due appointment reminders every
+// cfg.Interval in the background, until ctx is cancelled. Each check gets
+// at most one interval to finish.
+func StartReminderScheduler(ctx context.Context, repos repository.Repositories, cfg config.ReminderConfig) {
+	if len(cfg.Offsets) == 0 {
+		log.Println("No reminder offsets configured, appointment reminders are off")
+		return
+	}
+	offsets := append([]time.Duration(nil), cfg.Offsets...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			runCtx, cancel := context.WithTimeout(ctx, cfg.Interval)
+			sendDueReminders(runCtx, repos, offsets)
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// sendDueReminders sends every reminder that has come due. offsets must be
+// sorted ascending. Each offset owns the band between it and the next smaller
+// one, so an appointment booked at short notice only gets the nearest
+// reminder rather than all of them at once.
+func sendDueReminders(ctx context.Context, repos repository.Repositories, offsets []time.Duration) {
+	var lower time.Duration
+	for _, offset := range offsets {
+		due, err := repos.Reminders.Due(ctx, lower, offset)
+		if err != nil {
+			log.Println("Failed to fetch due reminders:", err)
+		}
+		for _, r := range due {
+			sendReminder(ctx, repos, r, offset, utils.RolePatient, r.PatientTag, r.PatientName, r.DoctorName)
+			sendReminder(ctx, repos, r, offset, utils.RoleDoctor, r.DoctorTag, r.DoctorName, r.PatientName)
+		}
+		lower = offset
+	}
+}
+
+// sendReminder claims the reminder before sending it, so a restart or a
+// second server instance never sends it twice. One that fails is tried
+// again on the next check, for as long as it is still due.
+func sendReminder(ctx context.Context, repos repository.Repositories, r models.DueReminder, offset time.Duration, recipient, subject, name, withName string) {
+	claimed, err := repos.Reminders.Claim(ctx, r.AppointmentID, recipient, offset)
+	if err != nil {
+		log.Println("Failed to record reminder:", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	data := notifications.ReminderData{
+		RecipientName: name,
+		WithName:      withName,
+		ScheduledAt:   r.ScheduledAt.In(accountLocation(ctx, repos.Accounts, recipient, subject)).Format("Mon 2 Jan 2006, 15:04 MST"),
+		StartsIn:      humanizeDuration(time.Until(r.ScheduledAt)),
+	}
+	if err := notifyUser(ctx, repos, recipient, subject, notifications.EventAppointmentReminder, data); err != nil {
+		log.Printf("Failed to send %s reminder for appointment %s: %v", recipient, r.AppointmentID, err)
+		if err := repos.Reminders.Fail(ctx, r.AppointmentID, recipient, offset); err != nil {
+			log.Println("Failed to record reminder failure:", err)
+		}
+	}
+}
+
+// humanizeDuration rounds d to whole hours, or minutes under an hour.
+func humanizeDuration(d time.Duration) string {
+	if d >= time.Hour {
+		hours := int(d.Round(time.Hour).Hours())
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes <= 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
@@ -0,0 +1,46 @@
+package servers
+
+import (
+	"context"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/utils"
+	"testing"
+	"time"
+)
+
+func TestSendDueRemindersRetriesFailed(t *testing.T) {
+	store := repository.NewMemory()
+	store.AddPatient(models.Patient{UserTag: "p-1", Firstname: "Ada", Lastname: "Obi", Email: "ada@example.com"})
+	store.AddDoctor(models.DoctorApplication{DoctorTag: "d-1", FullName: "Dr One", Email: "one@example.com", Status: "approved"})
+	store.AddAppointment(models.Appointment{ID: "a-1", UserTag: "p-1", DoctorTag: "d-1", Scheduled_at: time.Now().Add(30 * time.Minute), Status: "confirmed"})
+	repos := store.Repositories()
+	ctx := context.Background()
+
+	for _, recipient := range []string{utils.RolePatient, utils.RoleDoctor} {
+		if _, err := repos.Reminders.Claim(ctx, "a-1", recipient, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repos.Reminders.Fail(ctx, "a-1", utils.RolePatient, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	sendDueReminders(ctx, repos, []time.Duration{time.Hour})
+
+	reminders, err := repos.Appointments.Reminders(ctx, "a-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reminders) != 2 {
+		t.Fatalf("got reminders %v, want one per recipient", reminders)
+	}
+	for _, r := range reminders {
+		if r.Status != "sent" {
+			t.Errorf("%s reminder is %s, want sent", r.Recipient, r.Status)
+		}
+	}
+	if due, _ := repos.Reminders.Due(ctx, 0, time.Hour); len(due) != 0 {
+		t.Errorf("appointment is still due a reminder once both were sent: %v", due)
+	}
+}
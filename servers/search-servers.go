@@ -0,0 +1,50 @@
+package servers
+
+import (
+	"context"
+	"log"
+	"strings"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/responses"
+)
+
+const (
+	minSearchLength    = 2
+	defaultSearchLimit = 5
+	maxSearchLimit     = 25
+)
+
+// Search looks term up in every group named in groups, returning at most
+// limit hits per group, best first. Groups come back in a fixed order and
+// are present even when empty.
+func (s AdminServer) Search(ctx context.Context, term string, limit int, groups []string) (models.SearchResults, error) {
+	term = strings.TrimSpace(term)
+	results := models.SearchResults{Query: term, Groups: []models.SearchGroup{}}
+	if len([]rune(term)) < minSearchLength {
+		return results, apperrors.Invalid(responses.SEARCH_TOO_SHORT)
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		return results, apperrors.Invalid(responses.BAD_DATA)
+	}
+	allowed := map[string]bool{}
+	for _, name := range groups {
+		allowed[name] = true
+	}
+
+	for _, name := range s.Repos.Search.Groups() {
+		if !allowed[name] {
+			continue
+		}
+		hits, err := s.Repos.Search.Find(ctx, name, term, limit)
+		if err != nil {
+			log.Printf("Failed to search %s: %v", name, err)
+			return results, apperrors.Internal(err)
+		}
+		results.Groups = append(results.Groups, models.SearchGroup{Type: name, Hits: hits})
+	}
+	return results, nil
+}
@@ -0,0 +1,142 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"telemed/apperrors"
+	"telemed/models"
+	"telemed/repository"
+	"telemed/responses"
+	"telemed/utils"
+	"time"
+)
+
+// SessionServer refreshes and ends the sessions the login flows start.
+type SessionServer struct {
+	Repos repository.Repositories
+}
+
+// accountType maps a role onto the table its subject lives in, so that tags
+// from different tables never revoke each other's sessions.
+func accountType(role string) string {
+	if utils.IsStaffRole(role) {
+		return "admin"
+	}
+	return role
+}
+
+// AccountLocation returns the timezone saved on the caller's account, or UTC
+// if it cannot be read.
+func (s SessionServer) AccountLocation(ctx context.Context, role, subject string) *time.Location {
+	return accountLocation(ctx, s.Repos.Accounts, role, subject)
+}
+
+// issueSession starts a new session for subject and returns an access token
+// bound to it plus a refresh token of the form "<session id>.<secret>".
+func issueSession(ctx context.Context, sessions repository.Sessions, subject, role string, client models.ClientInfo) (models.SessionTokens, error) {
+	var tokens models.SessionTokens
+
+	sessionID, err := utils.GenerateToken()
+	if err != nil {
+		return tokens, err
+	}
+	secret, err := utils.GenerateToken()
+	if err != nil {
+		return tokens, err
+	}
+
+	err = sessions.Create(ctx, models.Session{
+		SessionID:        sessionID,
+		Subject:          subject,
+		AccountType:      accountType(role),
+		Role:             role,
+		RefreshTokenHash: utils.HashToken(secret),
+		ClientInfo:       client,
+	}, utils.RefreshTokenTTL)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.Token, err = utils.GenerateJWT(subject, role, sessionID)
+	if err != nil {
+		return tokens, err
+	}
+	tokens.RefreshToken = sessionID + "." + secret
+	tokens.ExpiresIn = int(utils.AccessTokenTTL.Seconds())
+	return tokens, nil
+}
+
+// Refresh rotates a refresh token. Presenting a refresh token that has
+// already been rotated means it has leaked, so the whole session is revoked.
+func (s SessionServer) Refresh(ctx context.Context, data models.RefreshTokenReq) (any, error) {
+	sessionID, secret, ok := strings.Cut(data.RefreshToken, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return nil, apperrors.Unauthorized(responses.SESSION_INVALID)
+	}
+
+	newSecret, err := utils.GenerateToken()
+	if err != nil {
+		log.Println("Failed to generate refresh token:", err)
+		return nil, apperrors.Internal(err)
+	}
+	session, err := s.Repos.Sessions.Rotate(ctx, sessionID, utils.HashToken(secret), utils.HashToken(newSecret), data.ClientInfo)
+	switch {
+	case errors.Is(err, repository.ErrTokenReused):
+		log.Println("Refresh token reuse detected, revoking session", sessionID)
+		return nil, apperrors.Unauthorized(responses.SESSION_INVALID)
+	case errors.Is(err, repository.ErrNotFound):
+		return nil, apperrors.Unauthorized(responses.SESSION_INVALID)
+	case err != nil:
+		log.Println("Failed to rotate refresh token:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	token, err := utils.GenerateJWT(session.Subject, session.Role, sessionID)
+	if err != nil {
+		log.Println("Failed to generate JWT token:", err)
+		return nil, apperrors.Internal(err)
+	}
+
+	return models.SessionTokens{
+		Token:        token,
+		RefreshToken: sessionID + "." + newSecret,
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s SessionServer) Logout(ctx context.Context, data models.SessionReq) (any, error) {
+	if err := s.Repos.Sessions.Revoke(ctx, data.SessionID); err != nil {
+		log.Println("Failed to revoke session:", err)
+		return nil, apperrors.Internal(err)
+	}
+	return map[string]string{"message": "Logged out successfully"}, nil
+}
+
+func (s SessionServer) LogoutAll(ctx context.Context, data models.SessionReq) (any, error) {
+	if err := revokeAllSessions(ctx, s.Repos.Sessions, data.Subject, data.Role); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return map[string]string{"message": "Logged out of all devices"}, nil
+}
+
+func revokeAllSessions(ctx context.Context, sessions repository.Sessions, subject, role string) error {
+	err := sessions.RevokeAll(ctx, accountType(role), subject)
+	if err != nil {
+		log.Println("Failed to revoke sessions:", err)
+	}
+	return err
+}
+
+// Active reports whether the session an access token was issued for is
+// still live. main hands it to middleware.JWTProtected, which asks on every
+// request.
+func (s SessionServer) Active(ctx context.Context, sessionID string) bool {
+	active, err := s.Repos.Sessions.Active(ctx, sessionID)
+	if err != nil {
+		log.Println("Failed to check session:", err)
+		return false
+	}
+	return active
+}
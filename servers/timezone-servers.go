@@ -0,0 +1,38 @@
+package servers
+
+import (
+	"context"
+	"log"
+	"telemed/apperrors"
+	"telemed/repository"
+	"telemed/responses"
+	"time"
+)
+
+// normalizeTimezone checks that tz is an IANA zone name such as
+// Africa/Lagos, defaulting to UTC when it is empty.
+func normalizeTimezone(tz string) (string, error) {
+	if tz == "" {
+		return "UTC", nil
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return "", apperrors.Invalid(responses.INVALID_TIMEZONE)
+	}
+	return tz, nil
+}
+
+// accountLocation returns the timezone saved on the account behind subject,
+// falling back to UTC if it cannot be read.
+func accountLocation(ctx context.Context, accounts repository.Accounts, role, subject string) *time.Location {
+	tz, err := accounts.Timezone(ctx, accountType(role), subject)
+	if err != nil {
+		log.Println("Failed to fetch account timezone:", err)
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		log.Println("Account has an unknown timezone:", err)
+		return time.UTC
+	}
+	return loc
+}
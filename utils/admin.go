@@ -2,9 +2,9 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"fmt"
-	"net/smtp"
 	"telemed/config"
 	"time"
 
@@ -36,28 +36,14 @@ func GenerateOTP() (string, error) {
 	return string(otp), nil
 }
 
-func SendEmailOTP(Email, otp string) error {
-	// Gmail SMTP server configuration.
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "587"
-	senderEmail := config.AppEmail
-	senderPassword := config.AppPassword
-
-	auth := smtp.PlainAuth("", senderEmail, senderPassword, smtpHost)
-
-	subject := "Your OTP Code"
-	body := fmt.Sprintf("Your OTP code is: %s  and it will expire in 10 mins", otp)
-	message := []byte("Subject: " + subject + "\r\n" +
-		"To: " + Email + "\r\n" +
-		"From: " + senderEmail + "\r\n" +
-		"\r\n" +
-		body + "\r\n")
-
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, senderEmail, []string{Email}, message)
-	return err
-}
+// Access tokens are short lived; clients keep a session going by trading the
+// refresh token issued alongside them.
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
 
-func GenerateJWT(usertag string) (string, error) {
+func GenerateJWT(usertag, role, sessionID string) (string, error) {
 	secret := config.JwtSecret
 	if secret == "" {
 		return "", errors.New("no secret key found")
@@ -65,7 +51,9 @@ func GenerateJWT(usertag string) (string, error) {
 
 	claims := jwt.MapClaims{
 		"usertag": usertag,
-		"exp":     time.Now().Add(1 * time.Hour).Unix(),
+		"role":    role,
+		"sid":     sessionID,
+		"exp":     time.Now().Add(AccessTokenTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -79,3 +67,18 @@ func HashPassword(password string) (string, error) {
 	}
 	return string(hashedPassword), nil
 }
+
+// GenerateToken returns a random 32-byte hex token for single-use links such
+// as admin invites. Only HashToken's output should ever be stored.
+func GenerateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "unicode"
+
+const MinPasswordLength = 10
+
+// IsStrongPassword enforces the password policy: at least MinPasswordLength
+// characters with an upper case letter, a lower case letter, a digit and a
+// symbol.
+func IsStrongPassword(password string) bool {
+	if len([]rune(password)) < MinPasswordLength {
+		return false
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	return upper && lower && digit && symbol
+}
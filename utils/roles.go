@@ -0,0 +1,29 @@
+package utils
+
+// Roles carried in the "role" claim of every JWT we issue. Patients and
+// doctors get their role from the table they log in against; staff roles are
+// stored in admins.role.
+const (
+	RoleAdmin      = "admin"
+	RoleGodEye     = "god_eye"
+	RoleDoctor     = "doctor"
+	RolePatient    = "patient"
+	RolePharmacist = "pharmacist"
+	RoleLabStaff   = "lab_staff"
+)
+
+// StaffRoles are the roles an admins row may hold.
+var StaffRoles = []string{RoleAdmin, RoleGodEye, RolePharmacist, RoleLabStaff}
+
+func IsStaffRole(role string) bool {
+	for _, r := range StaffRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func IsValidRole(role string) bool {
+	return IsStaffRole(role) || role == RoleDoctor || role == RolePatient
+}
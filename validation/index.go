@@ -0,0 +1,214 @@
+// Package validation checks request payloads against the rules in their
+// validate struct tags, e.g.
+//
+//	Email string `json:"email" validate:"required,email"`
+//
+// Rules are comma separated, and every rule except required passes on an
+// empty value, so optional fields are only checked when they are sent. A nil
+// pointer, as in a PATCH body, skips the field altogether; a non-nil one is
+// checked as its value, so on a pointer required means "not cleared".
+//
+//	required      not empty, zero or blank           code "required"
+//	email         a bare address, no display name    code "invalid_email"
+//	e164          a phone number like +2348012345678 code "invalid_phone"
+//	oneof=a b c   one of the listed values           code "not_allowed"
+//	min=n, max=n  a number in range                  code "out_of_range"
+//	              or a string or list length         code "too_short", "too_long"
+//	date          a YYYY-MM-DD date                  code "invalid_date"
+//	datetime      an RFC 3339 timestamp              code "invalid_datetime"
+//	timezone      an IANA name such as Africa/Lagos  code "invalid_timezone"
+//
+// Nested structs and lists of structs are checked too. Errors name fields by
+// their JSON path, e.g. "preferences[1].channel", and each field reports only
+// its first failing rule.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"telemed/responses"
+	"time"
+	"unicode/utf8"
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// Struct checks v, a struct or a pointer to one, and returns every failing
+// field in declaration order, or nil if there are none. It panics on a rule
+// it does not know, since that is a programming error.
+func Struct(v any) []responses.FieldError {
+	var errs []responses.FieldError
+	checkStruct(reflect.Indirect(reflect.ValueOf(v)), "", &errs)
+	return errs
+}
+
+// Var checks a single value that is not part of a payload struct, such as a
+// field only one endpoint requires, reporting failures under field.
+func Var(field string, value any, rules string) []responses.FieldError {
+	var errs []responses.FieldError
+	checkValue(reflect.ValueOf(value), field, rules, &errs)
+	return errs
+}
+
+func checkStruct(v reflect.Value, prefix string, errs *[]responses.FieldError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous {
+			if value := reflect.Indirect(v.Field(i)); value.Kind() == reflect.Struct {
+				checkStruct(value, prefix, errs)
+			}
+			continue
+		}
+		checkValue(v.Field(i), prefix+jsonName(field), field.Tag.Get("validate"), errs)
+	}
+}
+
+func checkValue(v reflect.Value, name, rules string, errs *[]responses.FieldError) {
+	if !v.IsValid() {
+		return
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if rules != "" {
+		for _, rule := range strings.Split(rules, ",") {
+			rule, arg, _ := strings.Cut(rule, "=")
+			if code, message := check(v, rule, arg); code != "" {
+				*errs = append(*errs, responses.FieldError{Field: name, Code: code, Message: message})
+				return
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		checkStruct(v, name+".", errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if elem := reflect.Indirect(v.Index(i)); elem.Kind() == reflect.Struct {
+				checkStruct(elem, fmt.Sprintf("%s[%d].", name, i), errs)
+			}
+		}
+	}
+}
+
+// check applies one rule to v and returns the error code and message if it
+// fails, or empty strings if it passes.
+func check(v reflect.Value, rule, arg string) (string, string) {
+	if rule == "required" {
+		if isEmpty(v) {
+			return "required", "is required"
+		}
+		return "", ""
+	}
+	if isEmpty(v) {
+		return "", ""
+	}
+
+	switch rule {
+	case "email":
+		if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+			return "invalid_email", "must be a valid email address"
+		}
+	case "e164":
+		if !e164Pattern.MatchString(v.String()) {
+			return "invalid_phone", "must be a phone number in international format, e.g. +2348012345678"
+		}
+	case "oneof":
+		allowed := strings.Fields(arg)
+		value := fmt.Sprint(v.Interface())
+		for _, a := range allowed {
+			if a == value {
+				return "", ""
+			}
+		}
+		return "not_allowed", "must be one of: " + strings.Join(allowed, ", ")
+	case "min", "max":
+		return checkBound(v, rule, arg)
+	case "date":
+		if _, err := time.Parse("2006-01-02", v.String()); err != nil {
+			return "invalid_date", "must be a date in YYYY-MM-DD format"
+		}
+	case "datetime":
+		if _, err := time.Parse(time.RFC3339, v.String()); err != nil {
+			return "invalid_datetime", "must be a datetime in RFC 3339 format, e.g. 2024-05-01T09:30:00Z"
+		}
+	case "timezone":
+		if _, err := time.LoadLocation(v.String()); err != nil {
+			return "invalid_timezone", "must be an IANA timezone such as Africa/Lagos"
+		}
+	default:
+		panic(fmt.Sprintf("validation: unknown rule %q", rule))
+	}
+	return "", ""
+}
+
+// checkBound applies min or max to a number's value or to the length of a
+// string or list.
+func checkBound(v reflect.Value, rule, arg string) (string, string) {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid %s bound %q", rule, arg))
+	}
+
+	var n float64
+	var unit string
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = float64(v.Len()), " items"
+	default:
+		panic(fmt.Sprintf("validation: %s does not apply to %s", rule, v.Kind()))
+	}
+
+	switch {
+	case rule == "min" && n < bound && unit == "":
+		return "out_of_range", "must be at least " + arg
+	case rule == "max" && n > bound && unit == "":
+		return "out_of_range", "must be at most " + arg
+	case rule == "min" && n < bound:
+		return "too_short", "must have at least " + arg + unit
+	case rule == "max" && n > bound:
+		return "too_long", "must have at most " + arg + unit
+	}
+	return "", ""
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// jsonName is the name a field has in request bodies. Fields the body cannot
+// set, like route parameters, fall back to their lower cased Go name.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
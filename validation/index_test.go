@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"reflect"
+	"telemed/models"
+	"telemed/responses"
+	"testing"
+)
+
+func codes(errs []responses.FieldError) map[string]string {
+	got := map[string]string{}
+	for _, e := range errs {
+		got[e.Field] = e.Code
+	}
+	return got
+}
+
+func TestStructListsEveryFailingField(t *testing.T) {
+	errs := Struct(models.PatientRegister{
+		Firstname: "Ada",
+		Email:     "ada@",
+		Phone_no:  "08012345678",
+		Dob:       "01/02/1990",
+	})
+	want := map[string]string{
+		"lastname": "required",
+		"email":    "invalid_email",
+		"phone_no": "invalid_phone",
+		"dob":      "invalid_date",
+		"password": "required",
+	}
+	if got := codes(errs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestStructPassesValidPayload(t *testing.T) {
+	errs := Struct(&models.PatientRegister{
+		Firstname: "Ada",
+		Lastname:  "Obi",
+		Email:     "ada@example.com",
+		Phone_no:  "+2348012345678",
+		Dob:       "1990-02-01",
+		Password:  "Secret-pass-1",
+	})
+	if errs != nil {
+		t.Errorf("got %v, want no errors", errs)
+	}
+}
+
+func TestStructPatch(t *testing.T) {
+	empty, price := "", -2.5
+	if errs := Struct(models.InventoryPatch{}); errs != nil {
+		t.Errorf("empty patch: got %v, want no errors", errs)
+	}
+	errs := Struct(models.InventoryPatch{ProductName: &empty, Price: &price})
+	want := map[string]string{"product_name": "required", "price": "out_of_range"}
+	if got := codes(errs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestStructRulesAndNesting(t *testing.T) {
+	if got := codes(Struct(models.Reviews{Rating: 6})); got["rating"] != "out_of_range" {
+		t.Errorf("rating 6: got %v", got)
+	}
+	if got := codes(Struct(models.Orders{OrderID: "1", Status: "lost"})); got["status"] != "not_allowed" {
+		t.Errorf("order status: got %v", got)
+	}
+
+	errs := Struct(models.NotificationPreferencesReq{Preferences: []models.NotificationPreference{
+		{Event: "order_shipped", Channel: "sms"},
+		{Event: "order_shipped"},
+	}})
+	want := []responses.FieldError{{Field: "preferences[1].channel", Code: "required", Message: "is required"}}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("got %v, want %v", errs, want)
+	}
+}
+
+func TestVar(t *testing.T) {
+	if got := codes(Var("status", "", "required")); got["status"] != "required" {
+		t.Errorf("got %v", got)
+	}
+	if errs := Var("timezone", "Africa/Lagos", "timezone"); errs != nil {
+		t.Errorf("got %v, want no errors", errs)
+	}
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unknown rule")
+		}
+	}()
+	Var("name", "x", "shiny")
+}